@@ -0,0 +1,161 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata/types"
+)
+
+// AppConfigClient is the part of the AWS AppConfig Data API used by the AppConfig source. It is
+// satisfied by `*appconfigdata.Client`.
+type AppConfigClient interface {
+	StartConfigurationSession(
+		ctx context.Context,
+		params *appconfigdata.StartConfigurationSessionInput,
+		optFns ...func(*appconfigdata.Options),
+	) (*appconfigdata.StartConfigurationSessionOutput, error)
+	GetLatestConfiguration(
+		ctx context.Context,
+		params *appconfigdata.GetLatestConfigurationInput,
+		optFns ...func(*appconfigdata.Options),
+	) (*appconfigdata.GetLatestConfigurationOutput, error)
+}
+
+// AppConfigConfig configures the AppConfig source.
+type AppConfigConfig struct {
+	// Application, Environment and Profile identify the configuration by name or ID.
+	Application string
+	Environment string
+	Profile     string
+	// PollInterval is the minimum time between polls requested from AppConfig. AppConfig
+	// requires at least 15 seconds and defaults to 60 seconds.
+	PollInterval time.Duration
+	// Client is used to call AppConfig. If nil, a client is created from the default AWS
+	// configuration.
+	Client AppConfigClient
+}
+
+// AppConfig returns a source that reads a configuration profile deployed with AWS AppConfig,
+// such as a feature flag profile. JSON and YAML configurations are flattened like config files,
+// so the feature flag `new-checkout` with an `enabled` attribute is resolved as
+// `NEW_CHECKOUT_ENABLED`. Configurations of any other content type are read as dotenv files.
+//
+// A configuration session is started and the configuration fetched the first time a key is looked
+// up. Later lookups poll for a new deployment once the poll interval returned by AppConfig has
+// passed, as AppConfig recommends, and keep the current configuration until then. The session is
+// restarted if it has expired.
+func AppConfig(cfg AppConfigConfig) Source {
+	var (
+		mu       sync.Mutex
+		client   = cfg.Client
+		token    string
+		nextPoll time.Time
+		values   map[string]string
+	)
+	name := fmt.Sprintf("appconfig:%s/%s/%s", cfg.Application, cfg.Environment, cfg.Profile)
+
+	startSession := func(ctx context.Context) error {
+		input := &appconfigdata.StartConfigurationSessionInput{
+			ApplicationIdentifier:          aws.String(cfg.Application),
+			EnvironmentIdentifier:          aws.String(cfg.Environment),
+			ConfigurationProfileIdentifier: aws.String(cfg.Profile),
+		}
+		if cfg.PollInterval > 0 {
+			input.RequiredMinimumPollIntervalInSeconds = aws.Int32(int32(cfg.PollInterval / time.Second))
+		}
+		out, err := client.StartConfigurationSession(ctx, input)
+		if err != nil {
+			return err
+		}
+		token = aws.ToString(out.InitialConfigurationToken)
+		return nil
+	}
+
+	poll := func(ctx context.Context) error {
+		out, err := client.GetLatestConfiguration(ctx, &appconfigdata.GetLatestConfigurationInput{
+			ConfigurationToken: aws.String(token),
+		})
+		if err != nil {
+			return err
+		}
+		token = aws.ToString(out.NextPollConfigurationToken)
+		nextPoll = time.Now().Add(time.Duration(out.NextPollIntervalInSeconds) * time.Second)
+		// an empty configuration means it has not changed since the last poll
+		if len(out.Configuration) == 0 && values != nil {
+			return nil
+		}
+
+		format := "dotenv"
+		mediaType, _, _ := mime.ParseMediaType(aws.ToString(out.ContentType))
+		switch mediaType {
+		case "application/json":
+			format = "json"
+		case "application/x-yaml", "application/yaml", "text/yaml":
+			format = "yaml"
+		}
+		polledValues, err := decodeConfig(format, out.Configuration, defaultKeySeparator)
+		if err != nil {
+			return fmt.Errorf("error reading configuration: %w", err)
+		}
+		values = polledValues
+		return nil
+	}
+
+	load := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if values != nil && time.Now().Before(nextPoll) {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		if client == nil {
+			awsCfg, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("error loading AWS config: %w", err)
+			}
+			client = appconfigdata.NewFromConfig(awsCfg)
+		}
+		if token == "" {
+			if err := startSession(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := poll(ctx)
+		var badRequest *types.BadRequestException
+		if errors.As(err, &badRequest) {
+			// the session token expires after 24 hours without polling
+			if err := startSession(ctx); err != nil {
+				return err
+			}
+			err = poll(ctx)
+		}
+		return err
+	}
+
+	return &funcSource{
+		name:     name,
+		prefetch: load,
+		lookup: func(ctx context.Context, key string) (string, bool, error) {
+			if err := load(ctx); err != nil {
+				return "", false, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := values[key]
+			return value, ok, nil
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package envstruct_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata/types"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// appConfigDeployment is a configuration returned by fakeAppConfig.
+type appConfigDeployment struct {
+	contentType string
+	content     string
+}
+
+// fakeAppConfig returns the deployments queued with deploy, and an empty configuration when
+// nothing new was deployed. Tokens of sessions that were expired with expire are rejected.
+type fakeAppConfig struct {
+	mu           sync.Mutex
+	sessions     []*appconfigdata.StartConfigurationSessionInput
+	polls        int
+	pollInterval int32
+	pending      []appConfigDeployment
+	expired      map[string]bool
+	lastToken    string
+}
+
+func (c *fakeAppConfig) StartConfigurationSession(_ context.Context, in *appconfigdata.StartConfigurationSessionInput, _ ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions = append(c.sessions, in)
+	c.lastToken = "session-" + strconv.Itoa(len(c.sessions))
+	return &appconfigdata.StartConfigurationSessionOutput{InitialConfigurationToken: aws.String(c.lastToken)}, nil
+}
+
+func (c *fakeAppConfig) GetLatestConfiguration(_ context.Context, in *appconfigdata.GetLatestConfigurationInput, _ ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token := aws.ToString(in.ConfigurationToken)
+	if token != c.lastToken || c.expired[token] {
+		return nil, &types.BadRequestException{Message: aws.String("token expired")}
+	}
+	c.polls++
+	c.lastToken = token + "-" + strconv.Itoa(c.polls)
+	out := &appconfigdata.GetLatestConfigurationOutput{
+		NextPollConfigurationToken: aws.String(c.lastToken),
+		NextPollIntervalInSeconds:  c.pollInterval,
+	}
+	if len(c.pending) > 0 {
+		out.ContentType = aws.String(c.pending[0].contentType)
+		out.Configuration = []byte(c.pending[0].content)
+		c.pending = c.pending[1:]
+	}
+	return out, nil
+}
+
+func (c *fakeAppConfig) deploy(contentType, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, appConfigDeployment{contentType, content})
+}
+
+// expire expires the token of the current session.
+func (c *fakeAppConfig) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired == nil {
+		c.expired = make(map[string]bool)
+	}
+	c.expired[c.lastToken] = true
+}
+
+func (c *fakeAppConfig) counts() (sessions, polls int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sessions), c.polls
+}
+
+type featureConfig struct {
+	Enabled bool   `env:"NEW_CHECKOUT_ENABLED"`
+	Level   string `env:"LOG_LEVEL"`
+}
+
+func TestAppConfig(t *testing.T) {
+	client := &fakeAppConfig{}
+	client.deploy("application/json", `{"new-checkout": {"enabled": true}, "log_level": "info"}`)
+	source := envstruct.AppConfig(envstruct.AppConfigConfig{
+		Application:  "shop",
+		Environment:  "prod",
+		Profile:      "flags",
+		PollInterval: 30 * time.Second,
+		Client:       client,
+	})
+	parse := func(want featureConfig) {
+		t.Helper()
+		var cfg featureConfig
+		if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+			t.Fatal(err)
+		}
+		if cfg != want {
+			t.Errorf("got %+v, want %+v", cfg, want)
+		}
+	}
+
+	parse(featureConfig{Enabled: true, Level: "info"})
+	if in := client.sessions[0]; aws.ToString(in.ApplicationIdentifier) != "shop" ||
+		aws.ToString(in.EnvironmentIdentifier) != "prod" ||
+		aws.ToString(in.ConfigurationProfileIdentifier) != "flags" ||
+		aws.ToInt32(in.RequiredMinimumPollIntervalInSeconds) != 30 {
+		t.Errorf("got session input %+v", in)
+	}
+
+	// an empty configuration keeps the current one
+	parse(featureConfig{Enabled: true, Level: "info"})
+
+	client.deploy("application/x-yaml; charset=utf-8", "new-checkout:\n  enabled: false\nlog_level: debug\n")
+	parse(featureConfig{Level: "debug"})
+
+	client.deploy("text/plain", "NEW_CHECKOUT_ENABLED=true\n")
+	parse(featureConfig{Enabled: true})
+
+	if sessions, _ := client.counts(); sessions != 1 {
+		t.Errorf("got %d sessions, want 1", sessions)
+	}
+}
+
+func TestAppConfigPollInterval(t *testing.T) {
+	client := &fakeAppConfig{pollInterval: 60}
+	client.deploy("application/json", `{"log_level": "info"}`)
+	source := envstruct.AppConfig(envstruct.AppConfigConfig{Application: "shop", Environment: "prod", Profile: "flags", Client: client})
+	var cfg featureConfig
+	for range 3 {
+		if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, polls := client.counts(); polls != 1 {
+		t.Errorf("got %d polls, want 1 before the poll interval has passed", polls)
+	}
+}
+
+func TestAppConfigSessionExpired(t *testing.T) {
+	client := &fakeAppConfig{}
+	client.deploy("application/json", `{"log_level": "info"}`)
+	source := envstruct.AppConfig(envstruct.AppConfigConfig{Application: "shop", Environment: "prod", Profile: "flags", Client: client})
+	var cfg featureConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+
+	client.expire()
+	client.deploy("application/json", `{"log_level": "debug"}`)
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" {
+		t.Errorf("got level %q, want the configuration of the new session", cfg.Level)
+	}
+	if sessions, _ := client.counts(); sessions != 2 {
+		t.Errorf("got %d sessions, want the session to be restarted", sessions)
+	}
+}
@@ -0,0 +1,151 @@
+package envstruct
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a single call to Parse, including the parses done when a config is
+// reloaded, for compliance requirements around configuration changes.
+type AuditRecord struct {
+	// Time is when the parse finished.
+	Time time.Time `json:"time"`
+	// User and Host identify the process that parsed the config.
+	User string `json:"user,omitempty"`
+	Host string `json:"host,omitempty"`
+	PID  int    `json:"pid"`
+	// Changes are the fields whose value changed, in field order.
+	Changes []AuditChange `json:"changes"`
+	// Sources maps the dotted path of every field that was set to its source, as in
+	// `Result.Sources`.
+	Sources map[string]string `json:"sources"`
+	// Error is the error of a failed parse.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditChange is a field whose value was changed by a parse. Values of secret fields are
+// redacted, so only the fact that a secret changed is recorded.
+type AuditChange struct {
+	Field  string `json:"field"`
+	Key    string `json:"key"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+	Source string `json:"source,omitempty"`
+}
+
+// AuditSink receives an AuditRecord for every parse. Audit is called synchronously before Parse
+// returns, so slow sinks should buffer records.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(record AuditRecord)
+
+func (fn AuditSinkFunc) Audit(record AuditRecord) {
+	fn(record)
+}
+
+// WithAudit makes Parse send an AuditRecord to `sink` after every parse, whether it succeeds or
+// not.
+func WithAudit(sink AuditSink) Option {
+	return func(o *options) {
+		o.audit = sink
+	}
+}
+
+// JSONAuditSink returns an AuditSink that writes every record to `w` as a line of JSON. Write
+// errors are ignored.
+func JSONAuditSink(w io.Writer) AuditSink {
+	var mu sync.Mutex
+	return AuditSinkFunc(func(record AuditRecord) {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(append(data, '\n'))
+	})
+}
+
+// SlogAuditSink returns an AuditSink that logs every record to `logger` at the info level, or
+// the error level for failed parses.
+func SlogAuditSink(logger *slog.Logger) AuditSink {
+	return AuditSinkFunc(func(record AuditRecord) {
+		level := slog.LevelInfo
+		if record.Error != "" {
+			level = slog.LevelError
+		}
+		changes := make([]any, len(record.Changes))
+		for i, change := range record.Changes {
+			changes[i] = slog.Group(change.Key,
+				slog.String("field", change.Field),
+				slog.String("old", change.Old),
+				slog.String("new", change.New),
+				slog.String("source", change.Source),
+			)
+		}
+		attrs := []any{
+			slog.String("user", record.User),
+			slog.String("host", record.Host),
+			slog.Int("pid", record.PID),
+			slog.Group("changes", changes...),
+		}
+		if record.Error != "" {
+			attrs = append(attrs, slog.String("error", record.Error))
+		}
+		logger.Log(context.Background(), level, "config loaded", attrs...)
+	})
+}
+
+// auditIdentity is the user and host recorded in audit records, looked up once.
+var auditIdentity = sync.OnceValues(func() (string, string) {
+	var username string
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+	host, _ := os.Hostname()
+	return username, host
+})
+
+// auditSnapshot returns a copy of the struct `val`.
+func auditSnapshot(val reflect.Value) reflect.Value {
+	snapshot := reflect.New(val.Type()).Elem()
+	snapshot.Set(val)
+	return snapshot
+}
+
+// audit sends the record of a parse of `val` to the audit sink. `before` is a copy of `val` from
+// before the parse.
+func (p *parser) audit(val, before reflect.Value, err error) {
+	username, host := auditIdentity()
+	record := AuditRecord{
+		Time:    time.Now(),
+		User:    username,
+		Host:    host,
+		PID:     os.Getpid(),
+		Changes: []AuditChange{},
+		Sources: p.res.Sources,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	for _, change := range diffValues(before, val) {
+		record.Changes = append(record.Changes, AuditChange{
+			Field:  change.Field,
+			Key:    change.Key,
+			Old:    change.Old,
+			New:    change.New,
+			Source: p.res.Sources[change.Field],
+		})
+	}
+	p.opts.audit.Audit(record)
+}
@@ -0,0 +1,121 @@
+package envstruct_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type auditConfig struct {
+	Host     string `env:"DB_HOST"`
+	Password string `env:"DB_PASSWORD,secret"`
+	Port     int    `env:"DB_PORT" default:"5432"`
+}
+
+// auditRecords collects the records sent to it.
+type auditRecords struct {
+	mu      sync.Mutex
+	records []envstruct.AuditRecord
+}
+
+func (a *auditRecords) Audit(record envstruct.AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.records = append(a.records, record)
+}
+
+func TestAudit(t *testing.T) {
+	source := sources.Static(map[string]string{"DB_HOST": "db-1", "DB_PASSWORD": "hunter2"}).Named("static")
+	var records auditRecords
+	store, err := envstruct.NewStore(auditConfig{}, envstruct.WithSources(source), envstruct.WithAudit(&records))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.Set("DB_HOST", "db-2")
+	source.Set("DB_PASSWORD", "hunter3")
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	source.Set("DB_PORT", "abc")
+	if err := store.Reload(); err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+
+	if len(records.records) != 3 {
+		t.Fatalf("got %d records, want one for every parse", len(records.records))
+	}
+	first, reload, failed := records.records[0], records.records[1], records.records[2]
+	if first.PID != os.Getpid() || first.Time.IsZero() || first.Error != "" {
+		t.Errorf("got record %+v", first)
+	}
+	want := []envstruct.AuditChange{
+		{Field: "Host", Key: "DB_HOST", Old: "", New: "db-1", Source: "static"},
+		{Field: "Password", Key: "DB_PASSWORD", Old: "[REDACTED]", New: "[REDACTED]", Source: "static"},
+		{Field: "Port", Key: "DB_PORT", Old: "0", New: "5432", Source: "default"},
+	}
+	if !reflect.DeepEqual(first.Changes, want) {
+		t.Errorf("got changes %+v, want %+v", first.Changes, want)
+	}
+
+	// a reload starts from the original config, so only fields that differ from it are reported
+	want = []envstruct.AuditChange{
+		{Field: "Host", Key: "DB_HOST", Old: "", New: "db-2", Source: "static"},
+		{Field: "Password", Key: "DB_PASSWORD", Old: "[REDACTED]", New: "[REDACTED]", Source: "static"},
+		{Field: "Port", Key: "DB_PORT", Old: "0", New: "5432", Source: "default"},
+	}
+	if !reflect.DeepEqual(reload.Changes, want) {
+		t.Errorf("got changes %+v, want %+v", reload.Changes, want)
+	}
+	if reload.Sources["Host"] != "static" || reload.Sources["Port"] != "default" {
+		t.Errorf("got sources %v", reload.Sources)
+	}
+	if !strings.Contains(failed.Error, "DB_PORT") {
+		t.Errorf("got error %q, want the error of the failed parse", failed.Error)
+	}
+}
+
+func TestJSONAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	source := sources.Static(map[string]string{"DB_HOST": "db-1", "DB_PASSWORD": "hunter2"})
+	for range 2 {
+		var cfg auditConfig
+		if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithAudit(envstruct.JSONAuditSink(&buf))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want a line for every parse", len(lines))
+	}
+	var record envstruct.AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Changes) != 3 || strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("got %s", lines[0])
+	}
+}
+
+func TestSlogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := envstruct.SlogAuditSink(slog.New(slog.NewTextHandler(&buf, nil)))
+	var cfg auditConfig
+	source := sources.Static(map[string]string{"DB_HOST": "db-1", "DB_PORT": "abc"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithAudit(sink)); err == nil {
+		t.Fatal("expected an error")
+	}
+	out := buf.String()
+	for _, want := range []string{"level=ERROR", `msg="config loaded"`, "changes.DB_HOST.new=db-1", "changes.DB_HOST.source=static", "error="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("got %q, want %s", out, want)
+		}
+	}
+}
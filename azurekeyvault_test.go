@@ -0,0 +1,76 @@
+package envstruct_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets/fake"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestAzureKeyVault(t *testing.T) {
+	const vaultURL = "https://my-vault.vault.azure.net"
+	secrets := map[string]string{
+		"my-service-db-password": "hunter2",
+		"my-service-api-key":     "abc123",
+		"my-service-disabled":    "old",
+		"other-host":             "db.internal",
+	}
+	properties := func(name string, enabled bool) *azsecrets.SecretProperties {
+		id := azsecrets.ID(vaultURL + "/secrets/" + name)
+		return &azsecrets.SecretProperties{ID: &id, Attributes: &azsecrets.SecretAttributes{Enabled: &enabled}}
+	}
+	server := fake.Server{
+		NewListSecretPropertiesPager: func(*azsecrets.ListSecretPropertiesOptions) (resp azfake.PagerResponder[azsecrets.ListSecretPropertiesResponse]) {
+			resp.AddPage(http.StatusOK, azsecrets.ListSecretPropertiesResponse{
+				SecretPropertiesListResult: azsecrets.SecretPropertiesListResult{Value: []*azsecrets.SecretProperties{
+					properties("my-service-db-password", true),
+					properties("my-service-disabled", false),
+				}},
+			}, nil)
+			resp.AddPage(http.StatusOK, azsecrets.ListSecretPropertiesResponse{
+				SecretPropertiesListResult: azsecrets.SecretPropertiesListResult{Value: []*azsecrets.SecretProperties{
+					properties("my-service-api-key", true),
+					properties("other-host", true),
+				}},
+			}, nil)
+			return
+		},
+		GetSecret: func(_ context.Context, name, _ string, _ *azsecrets.GetSecretOptions) (resp azfake.Responder[azsecrets.GetSecretResponse], errResp azfake.ErrorResponder) {
+			// the fake server keeps the slash before the blank version that selects the latest one
+			value, ok := secrets[strings.TrimSuffix(name, "/")]
+			if !ok {
+				errResp.SetResponseError(http.StatusNotFound, "SecretNotFound")
+				return
+			}
+			resp.SetResponse(http.StatusOK, azsecrets.GetSecretResponse{Secret: azsecrets.Secret{Value: &value}}, nil)
+			return
+		},
+	}
+	client, err := azsecrets.NewClient(vaultURL, &azfake.TokenCredential{}, &azsecrets.ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: fake.NewServerTransport(&server)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg struct {
+		Password string `env:"DB_PASSWORD"`
+		APIKey   string `env:"API_KEY"`
+		Disabled string `env:"DISABLED"`
+		Host     string `env:"OTHER_HOST"`
+	}
+	source := envstruct.AzureKeyVault(envstruct.AzureKeyVaultConfig{Prefix: "my-service-", Client: client})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.APIKey != "abc123" || cfg.Disabled != "" || cfg.Host != "" {
+		t.Errorf("got %+v, want only the enabled secrets with the prefix", cfg)
+	}
+}
@@ -0,0 +1,206 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagOptions are the options of the `env` tag that Compile accepts.
+var tagOptions = map[string]bool{
+	"secret":    true,
+	"required":  true,
+	"parseurl":  true,
+	"quantity":  true,
+	"source":    true,
+	"exec":      true,
+	"op":        true,
+	"validate":  true,
+	"severity":  true,
+	"encrypted": true,
+	"signed":    true,
+}
+
+// Binder is a compiled binding of the struct type `T` to its environment variables, created with
+// Compile. A Binder is safe for concurrent use.
+type Binder[T any] struct {
+	opts []Option
+}
+
+// Compile checks the tags of `T` and prepares binding it, so tag mistakes are found when a service
+// starts, or in a test, rather than when a value is first set:
+//
+//	binder, err := Compile[Config](WithErrOnMissingValue())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	cfg, err := binder.Bind(os.LookupEnv)
+//
+// Fields are reported with the reason ReasonTag for unknown tag options, types Parse does not
+// support, `default` tags that do not parse, unknown `quantity` scales and unknown `validate`
+// rules. Rules added with RegisterValidation must be registered before Compile is called.
+//
+// When built with the `envstruct_unsafe` build tag, Bind sets string, int and bool fields through
+// pointers at offsets computed once per type instead of through reflection. Other fields are set
+// as usual.
+func Compile[T any](opts ...Option) (b *Binder[T], err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Compile: %w", err)
+		}
+	}()
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s is not a struct", t)
+	}
+	o := newOptions(opts)
+
+	var errs ParseErrors
+	for _, pf := range typeFields(t) {
+		if err := checkPlannedField(pf, o); err != nil {
+			errs = append(errs, &FieldError{
+				Field:  pf.path,
+				Key:    pf.tag.key,
+				Reason: ReasonTag,
+				Hint:   fmt.Sprintf("fix the tags of field '%s'", pf.path),
+				Err:    fmt.Errorf("field '%s' bound to '%s': %w", pf.path, pf.tag.key, err),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.fastSet = true
+	})
+	return &Binder[T]{opts: opts}, nil
+}
+
+// checkPlannedField reports why the tags of `pf` can never be satisfied.
+func checkPlannedField(pf plannedField, o options) error {
+	for _, name := range sortedKeys(pf.tag.options) {
+		if !tagOptions[name] {
+			return fmt.Errorf("unknown tag option '%s'", name)
+		}
+	}
+	if pf.tag.has("encrypted") && o.decryptor == nil {
+		return errors.New("the encrypted option requires a decryptor, use WithDecryptor")
+	}
+	if o.byteSecrets && pf.tag.has("secret") && !byteSecretType(pf.field.Type) {
+		return fmt.Errorf("secret fields must be []byte or Secret[[]byte] with WithByteSecrets, not %s", pf.field.Type)
+	}
+	if pf.tag.has("signed") && o.signature == nil {
+		return errors.New("the signed option requires a signature, use WithSignature")
+	}
+	if scale := pf.tag.get("quantity"); scale != "" && scale != "milli" {
+		return fmt.Errorf("unknown quantity scale '%s'", scale)
+	}
+
+	// set the default, or a probe value of the right kind, on a scratch value to check the type
+	def, hasDefault := pf.field.Tag.Lookup("default")
+	if err := setScratch(pf, def, hasDefault); err != nil {
+		if hasDefault {
+			return fmt.Errorf("invalid default '%s': %w", def, err)
+		}
+		return err
+	}
+
+	var rules []string
+	if rule := pf.tag.get("validate"); rule != "" {
+		rules = append(rules, rule)
+	}
+	if o.validator == nil {
+		rules = append(rules, pf.rules...)
+	}
+	for _, rule := range rules {
+		name, _, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if !ruleExists(name) {
+			return fmt.Errorf("unknown validation rule '%s'", name)
+		}
+	}
+	return nil
+}
+
+// setScratch sets `def`, or a probe value if there is no default, on a new value of the type of
+// `pf`.
+func setScratch(pf plannedField, def string, hasDefault bool) error {
+	scratch := reflect.New(pf.field.Type).Elem()
+	switch {
+	case pf.tag.has("parseurl"):
+		if scratch.Kind() != reflect.Struct {
+			return fmt.Errorf("the parseurl option requires a struct field, not %s", pf.field.Type)
+		}
+		return setURLFields(scratch, pf.tag.key, def, false)
+	case pf.tag.has("quantity"):
+		if !hasDefault {
+			def = "0"
+		}
+		return setQuantityField(scratch, pf.tag.key, def, pf.tag.get("quantity"), false)
+	}
+	if _, ok := textUnmarshaler(scratch); ok && !hasDefault {
+		return nil
+	}
+	if !hasDefault {
+		def = probeValue(scratch.Kind())
+	}
+	ok, err := setField(scratch, pf.tag.key, def, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("type %s is not supported", pf.field.Type)
+	}
+	return nil
+}
+
+// probeValue returns a value that setField accepts for `kind`.
+func probeValue(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return "0"
+	case reflect.Bool:
+		return "false"
+	default:
+		return ""
+	}
+}
+
+// ruleExists reports whether `name` is a built-in or registered validation rule.
+func ruleExists(name string) bool {
+	if _, ok := validationRules[name]; ok {
+		return true
+	}
+	customValidations.RLock()
+	defer customValidations.RUnlock()
+	_, ok := customValidations.funcs[name]
+	return ok
+}
+
+// Bind returns a `T` with its fields set from `lookup`, e.g. `os.LookupEnv`, following the rules
+// of Parse with the options given to Compile. `lookup` takes the place of the source chain; named
+// sources and the exec and op tag options still work. If `lookup` is nil the sources of the
+// options are used.
+func (b *Binder[T]) Bind(lookup func(key string) (string, bool)) (cfg T, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Bind: %w", err)
+		}
+	}()
+	opts := b.opts
+	if lookup != nil {
+		source := &funcSource{
+			name: "lookup",
+			lookup: func(_ context.Context, key string) (string, bool, error) {
+				value, ok := lookup(key)
+				return value, ok, nil
+			},
+		}
+		opts = append(opts[:len(opts):len(opts)], func(o *options) {
+			o.sources = []Source{source}
+		})
+	}
+	_, err = Parse(&cfg, opts...)
+	return cfg, err
+}
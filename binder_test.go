@@ -0,0 +1,155 @@
+package envstruct_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type binderConfig struct {
+	Host    string        `env:"HOST,required"`
+	Port    int           `env:"PORT" default:"8080" validate:"port"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Memory  int64         `env:"MEMORY,quantity" default:"64Mi"`
+	Level   string        `env:"LEVEL,validate=oneof=debug info" default:"info"`
+}
+
+func TestBinder(t *testing.T) {
+	binder, err := envstruct.Compile[binderConfig](envstruct.WithErrOnMissingValue())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{"HOST": "db.internal", "PORT": "5432"}
+	cfg, err := binder.Bind(func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := binderConfig{Host: "db.internal", Port: 5432, Timeout: 5 * time.Second, Memory: 64 << 20, Level: "info"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	// the options given to Compile apply to every Bind
+	if _, err := binder.Bind(func(string) (string, bool) { return "", false }); err == nil {
+		t.Error("expected an error for the missing HOST")
+	}
+}
+
+func TestBinderSources(t *testing.T) {
+	source := sources.Static(map[string]string{"HOST": "db.internal"})
+	binder, err := envstruct.Compile[binderConfig](envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a lookup the sources of the options are used
+	cfg, err := binder.Bind(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" {
+		t.Errorf("got %+v, want HOST from the source", cfg)
+	}
+}
+
+// compileError returns the error of compiling `T`, which must be a single tag error.
+func compileError[T any](t *testing.T, opts ...envstruct.Option) string {
+	t.Helper()
+	_, err := envstruct.Compile[T](opts...)
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want ParseErrors", err)
+	}
+	if len(errs) != 1 || errs[0].Reason != envstruct.ReasonTag {
+		t.Fatalf("got errors %v, want a single tag error", errs)
+	}
+	return err.Error()
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  string
+		got  func(*testing.T, ...envstruct.Option) string
+	}{
+		{"unknown option", "unknown tag option 'requird'", compileError[struct {
+			Host string `env:"HOST,requird"`
+		}]},
+		{"unsupported type", "type float64 is not supported", compileError[struct {
+			Ratio float64 `env:"RATIO"`
+		}]},
+		{"invalid default", "invalid default 'abc'", compileError[struct {
+			Port int `env:"PORT" default:"abc"`
+		}]},
+		{"invalid duration default", "invalid default '5'", compileError[struct {
+			Timeout time.Duration `env:"TIMEOUT" default:"5"`
+		}]},
+		{"unknown quantity scale", "unknown quantity scale 'kilo'", compileError[struct {
+			Memory int64 `env:"MEMORY,quantity=kilo"`
+		}]},
+		{"parseurl on a string", "the parseurl option requires a struct field", compileError[struct {
+			URL string `env:"URL,parseurl"`
+		}]},
+		{"unknown rule", "unknown validation rule 'no_such_rule'", compileError[struct {
+			Host string `env:"HOST" validate:"no_such_rule"`
+		}]},
+		{"unknown rule option", "unknown validation rule 'even'", compileError[struct {
+			Count int `env:"COUNT,validate=even"`
+		}]},
+		{"encrypted without decryptor", "requires a decryptor", compileError[struct {
+			Password string `env:"PASSWORD,encrypted"`
+		}]},
+		{"signed without signature", "requires a signature", compileError[struct {
+			Token string `env:"TOKEN,signed"`
+		}]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(t); !strings.Contains(got, tt.err) {
+				t.Errorf("got error %q, want %q", got, tt.err)
+			}
+		})
+	}
+}
+
+func TestCompileByteSecrets(t *testing.T) {
+	got := compileError[struct {
+		Password string `env:"PASSWORD,secret"`
+	}](t, envstruct.WithByteSecrets())
+	if !strings.Contains(got, "secret fields must be []byte") {
+		t.Errorf("got error %q", got)
+	}
+}
+
+func TestCompileNotStruct(t *testing.T) {
+	if _, err := envstruct.Compile[string](); err == nil {
+		t.Fatal("expected an error for a type that is not a struct")
+	}
+}
+
+func TestCompileRegisteredRule(t *testing.T) {
+	type config struct {
+		Count int `env:"COUNT" validate:"test_compile_even"`
+	}
+	if _, err := envstruct.Compile[config](); err == nil {
+		t.Fatal("expected an error for a rule that is not registered yet")
+	}
+	err := envstruct.RegisterValidation("test_compile_even", func(value any) error {
+		if value.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := envstruct.Compile[config](); err != nil {
+		t.Fatal(err)
+	}
+}
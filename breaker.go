@@ -0,0 +1,201 @@
+package envstruct
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BreakerConfig configures the circuit breaker of WithSourceBreaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that open the breaker. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before a single call is let through to check
+	// whether the source has recovered. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+}
+
+// SourceBreaker is a Source guarded by a circuit breaker, returned by WithSourceBreaker.
+type SourceBreaker struct {
+	source Source
+	cfg    BreakerConfig
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	// probing is set while a call is let through an open breaker.
+	probing bool
+	lastErr error
+	// values holds the last value successfully looked up for each key.
+	values map[string]string
+}
+
+// WithSourceBreaker returns a source that stops calling `source` after it fails
+// `cfg.FailureThreshold` times in a row, so a config backend that is down does not slow down or
+// fail every parse, e.g. during startup or reloads. Errors are returned as usual until the breaker
+// opens.
+//
+// While the breaker is open, keys are resolved from the last value `source` returned for them if
+// there is one, and otherwise fall through to the next source in the chain. Parse reports the
+// source in `Result.Degraded` while it is open. After `cfg.OpenTimeout` a single call is let
+// through again, which closes the breaker if it succeeds.
+//
+// The returned source is recorded in a `Result` under the name of `source`.
+func WithSourceBreaker(source Source, cfg BreakerConfig) *SourceBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	return &SourceBreaker{source: source, cfg: cfg, values: make(map[string]string)}
+}
+
+// Degraded reports whether the breaker is open, meaning the source is currently skipped.
+func (b *SourceBreaker) Degraded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open()
+}
+
+// LastError returns the error of the last failed call to the source, or nil if the last call
+// succeeded.
+func (b *SourceBreaker) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErr
+}
+
+func (b *SourceBreaker) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if !b.allow() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		value, ok := b.values[key]
+		return value, ok, nil
+	}
+	value, ok, err := b.source.Lookup(ctx, key)
+	b.record(err)
+	if err != nil {
+		return b.fallback(key, err)
+	}
+	if ok {
+		b.mu.Lock()
+		b.values[key] = value
+		b.mu.Unlock()
+	}
+	return value, ok, nil
+}
+
+// fallback returns the last value of `key` if the failure opened the breaker, and `err` otherwise.
+func (b *SourceBreaker) fallback(key string, err error) (string, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open() {
+		return "", false, err
+	}
+	value, ok := b.values[key]
+	return value, ok, nil
+}
+
+// Prefetch prefetches the source if it implements Prefetcher and the breaker is closed.
+func (b *SourceBreaker) Prefetch(ctx context.Context) error {
+	prefetcher, ok := b.source.(Prefetcher)
+	if !ok || !b.allow() {
+		return nil
+	}
+	err := prefetcher.Prefetch(ctx)
+	b.record(err)
+	if b.Degraded() {
+		return nil
+	}
+	return err
+}
+
+// PrefetchKeys prefetches the keys from the source if it implements KeyPrefetcher and the breaker
+// is closed.
+func (b *SourceBreaker) PrefetchKeys(ctx context.Context, keys []string) error {
+	prefetcher, ok := b.source.(KeyPrefetcher)
+	if !ok || !b.allow() {
+		return nil
+	}
+	err := prefetcher.PrefetchKeys(ctx, keys)
+	b.record(err)
+	if b.Degraded() {
+		return nil
+	}
+	return err
+}
+
+// Changed forwards the changes reported by the source. If it does not implement Notifier the
+// returned channel never receives.
+func (b *SourceBreaker) Changed() <-chan struct{} {
+	if notifier, ok := b.source.(Notifier); ok {
+		return notifier.Changed()
+	}
+	return nil
+}
+
+func (b *SourceBreaker) String() string {
+	return SourceName(b.source)
+}
+
+func (b *SourceBreaker) limitedToSecrets() bool {
+	src, ok := b.source.(interface{ limitedToSecrets() bool })
+	return ok && src.limitedToSecrets()
+}
+
+func (b *SourceBreaker) watchedFiles() []string {
+	src, ok := b.source.(interface{ watchedFiles() []string })
+	if !ok {
+		return nil
+	}
+	return src.watchedFiles()
+}
+
+func (b *SourceBreaker) reload() {
+	if src, ok := b.source.(interface{ reload() }); ok {
+		src.reload()
+	}
+}
+
+func (b *SourceBreaker) startWatching(ctx context.Context) {
+	if src, ok := b.source.(interface{ startWatching(ctx context.Context) }); ok {
+		src.startWatching(ctx)
+	}
+}
+
+// open reports whether the breaker is open; b.mu must be held.
+func (b *SourceBreaker) open() bool {
+	return b.failures >= b.cfg.FailureThreshold
+}
+
+// allow reports whether a call may be made to the source, letting a single call through once the
+// breaker has been open for `cfg.OpenTimeout`.
+func (b *SourceBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open() {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cfg.OpenTimeout {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates the breaker with the outcome of a call to the source.
+func (b *SourceBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	b.lastErr = err
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.open() {
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,104 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// openBreaker makes `static` fail until the breaker `b` with a threshold of 2 opens.
+func openBreaker(t *testing.T, static *sources.StaticSource, b *envstruct.SourceBreaker) {
+	t.Helper()
+	static.Fail(unavailableError{})
+	if _, _, err := b.Lookup(context.Background(), "HOST"); err == nil {
+		t.Fatal("expected the error of the first failure")
+	}
+	// the failure that opens the breaker is not returned
+	if _, _, err := b.Lookup(context.Background(), "HOST"); err != nil {
+		t.Fatalf("got error %v, want the breaker to open", err)
+	}
+	if !b.Degraded() {
+		t.Fatal("want the breaker to be open")
+	}
+}
+
+func TestSourceBreaker(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db.internal"}).Named("static")
+	breaker := envstruct.WithSourceBreaker(static, envstruct.BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour})
+	if got := envstruct.SourceName(breaker); got != "static" {
+		t.Errorf("got name %q, want the name of the wrapped source", got)
+	}
+	lookup(t, breaker, "HOST")
+	openBreaker(t, static, breaker)
+	if !errors.Is(breaker.LastError(), unavailableError{}) {
+		t.Errorf("got last error %v", breaker.LastError())
+	}
+
+	// while open, the source is not called and the last values are used
+	lookups := static.Lookups("HOST")
+	if value, ok := lookup(t, breaker, "HOST"); value != "db.internal" || !ok {
+		t.Errorf("got %q, want the last value", value)
+	}
+	if _, ok := lookup(t, breaker, "PORT"); ok {
+		t.Error("got a value for a key that was never looked up")
+	}
+	if static.Lookups("HOST") != lookups || static.Lookups("PORT") != 0 {
+		t.Error("want no calls to the source while the breaker is open")
+	}
+}
+
+func TestSourceBreakerFallsThrough(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db.internal"}).Named("remote")
+	breaker := envstruct.WithSourceBreaker(static, envstruct.BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour})
+	openBreaker(t, static, breaker)
+
+	fallback := sources.Static(map[string]string{"HOST": "db.fallback", "PORT": "5432"})
+	var cfg struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(breaker, fallback))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HOST was never looked up successfully, so it comes from the next source
+	if cfg.Host != "db.fallback" || cfg.Port != 5432 {
+		t.Errorf("got %+v, want the values of the fallback source", cfg)
+	}
+	if !slices.Equal(res.Degraded, []string{"remote"}) {
+		t.Errorf("got degraded sources %q, want the open breaker", res.Degraded)
+	}
+}
+
+func TestSourceBreakerRecovers(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db.internal"})
+	breaker := envstruct.WithSourceBreaker(static, envstruct.BreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond})
+	openBreaker(t, static, breaker)
+
+	// a failed probe keeps the breaker open for another timeout
+	time.Sleep(60 * time.Millisecond)
+	lookups := static.Lookups("HOST")
+	lookup(t, breaker, "HOST")
+	lookup(t, breaker, "HOST")
+	if got := static.Lookups("HOST") - lookups; got != 1 {
+		t.Errorf("got %d calls after the timeout, want a single probe", got)
+	}
+	if !breaker.Degraded() {
+		t.Fatal("want the breaker to stay open after a failed probe")
+	}
+
+	static.Fail(nil)
+	static.Set("HOST", "db-2.internal")
+	time.Sleep(60 * time.Millisecond)
+	if value, _ := lookup(t, breaker, "HOST"); value != "db-2.internal" {
+		t.Errorf("got %q, want the value of the successful probe", value)
+	}
+	if breaker.Degraded() || breaker.LastError() != nil {
+		t.Error("want the breaker to close after a successful probe")
+	}
+}
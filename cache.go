@@ -0,0 +1,208 @@
+package envstruct
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithSourceCache returns a source that caches the values looked up from `source` for `ttl`, so
+// frequent parses, such as reloads, do not make a request per key to a rate limited backend like
+// Vault or SSM. Keys the source does not have are cached too, while lookup errors are not.
+//
+// Sources that load all their values at once, such as File or Vault, are made to load them again
+// when a lookup goes to `source` more than `ttl` after they were last loaded, so their values
+// expire too. The returned source is recorded in a `Result` under the name of `source`. If
+// `source` implements Notifier, the cache is cleared whenever it reports a change.
+func WithSourceCache(source Source, ttl time.Duration) Source {
+	return WithStaleSourceCache(source, ttl, 0)
+}
+
+// WithStaleSourceCache is like WithSourceCache, but values that expired less than `stale` ago are
+// still returned while they are refreshed in the background, so only the first lookup of a key
+// waits for `source`. If the refresh fails, the stale value is kept until it expires.
+func WithStaleSourceCache(source Source, ttl, stale time.Duration) Source {
+	return &cacheSource{
+		source:  source,
+		ttl:     ttl,
+		stale:   stale,
+		entries: make(map[string]*cacheEntry),
+		loaded:  time.Now(),
+	}
+}
+
+// cacheSource is the Source returned by WithSourceCache.
+type cacheSource struct {
+	source Source
+	ttl    time.Duration
+	stale  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	// loaded is when `source` was last made to load its values again.
+	loaded time.Time
+
+	watchOnce sync.Once
+	changed   chan struct{}
+}
+
+type cacheEntry struct {
+	value   string
+	ok      bool
+	expires time.Time
+	// refreshing is set while the entry is refreshed in the background.
+	refreshing bool
+}
+
+func (s *cacheSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	entry, cached := s.entries[key]
+	switch {
+	case cached && now.Before(entry.expires):
+		s.mu.Unlock()
+		return entry.value, entry.ok, nil
+	case cached && now.Before(entry.expires.Add(s.stale)):
+		if !entry.refreshing {
+			entry.refreshing = true
+			go s.refresh(context.WithoutCancel(ctx), key)
+		}
+		s.mu.Unlock()
+		return entry.value, entry.ok, nil
+	}
+	s.mu.Unlock()
+
+	s.expireSource()
+	value, ok, err := s.source.Lookup(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+	s.store(key, value, ok)
+	return value, ok, nil
+}
+
+// refresh looks `key` up again and replaces its entry, or keeps the stale entry if that fails.
+func (s *cacheSource) refresh(ctx context.Context, key string) {
+	s.expireSource()
+	value, ok, err := s.source.Lookup(ctx, key)
+	if err != nil {
+		s.mu.Lock()
+		if entry, cached := s.entries[key]; cached {
+			entry.refreshing = false
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.store(key, value, ok)
+}
+
+// expireSource makes the cached source load its values again if it keeps what it loads and last
+// loaded them more than the TTL ago.
+func (s *cacheSource) expireSource() {
+	src, ok := s.source.(interface{ reload() })
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	expired := time.Since(s.loaded) >= s.ttl
+	if expired {
+		s.loaded = time.Now()
+	}
+	s.mu.Unlock()
+	if expired {
+		src.reload()
+	}
+}
+
+func (s *cacheSource) store(key, value string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &cacheEntry{value: value, ok: ok, expires: time.Now().Add(s.ttl)}
+}
+
+// Prefetch prefetches the cached source if it implements Prefetcher.
+func (s *cacheSource) Prefetch(ctx context.Context) error {
+	if prefetcher, ok := s.source.(Prefetcher); ok {
+		return prefetcher.Prefetch(ctx)
+	}
+	return nil
+}
+
+// PrefetchKeys prefetches the keys that are not cached from the cached source if it implements
+// KeyPrefetcher.
+func (s *cacheSource) PrefetchKeys(ctx context.Context, keys []string) error {
+	prefetcher, ok := s.source.(KeyPrefetcher)
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	var uncached []string
+	s.mu.Lock()
+	for _, key := range keys {
+		if entry, cached := s.entries[key]; !cached || !now.Before(entry.expires.Add(s.stale)) {
+			uncached = append(uncached, key)
+		}
+	}
+	s.mu.Unlock()
+	if len(uncached) == 0 {
+		return nil
+	}
+	return prefetcher.PrefetchKeys(ctx, uncached)
+}
+
+// Changed forwards the changes reported by the cached source after clearing the cache. If the
+// cached source does not implement Notifier the returned channel never receives.
+func (s *cacheSource) Changed() <-chan struct{} {
+	s.watchOnce.Do(func() {
+		notifier, ok := s.source.(Notifier)
+		if !ok {
+			return
+		}
+		s.changed = make(chan struct{}, 1)
+		go func() {
+			for range notifier.Changed() {
+				s.mu.Lock()
+				clear(s.entries)
+				s.mu.Unlock()
+				select {
+				case s.changed <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	})
+	return s.changed
+}
+
+func (s *cacheSource) String() string {
+	return SourceName(s.source)
+}
+
+func (s *cacheSource) limitedToSecrets() bool {
+	src, ok := s.source.(interface{ limitedToSecrets() bool })
+	return ok && src.limitedToSecrets()
+}
+
+func (s *cacheSource) watchedFiles() []string {
+	src, ok := s.source.(interface{ watchedFiles() []string })
+	if !ok {
+		return nil
+	}
+	return src.watchedFiles()
+}
+
+func (s *cacheSource) reload() {
+	s.mu.Lock()
+	clear(s.entries)
+	s.loaded = time.Now()
+	s.mu.Unlock()
+	if src, ok := s.source.(interface{ reload() }); ok {
+		src.reload()
+	}
+}
+
+func (s *cacheSource) startWatching(ctx context.Context) {
+	if src, ok := s.source.(interface{ startWatching(ctx context.Context) }); ok {
+		src.startWatching(ctx)
+	}
+}
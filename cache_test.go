@@ -0,0 +1,140 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// lookup looks `key` up in `source`, failing the test on an error.
+func lookup(t *testing.T, source envstruct.Source, key string) (string, bool) {
+	t.Helper()
+	value, ok, err := source.Lookup(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value, ok
+}
+
+func TestSourceCache(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db-1"}).Named("static")
+	source := envstruct.WithSourceCache(static, time.Hour)
+	if got := envstruct.SourceName(source); got != "static" {
+		t.Errorf("got name %q, want the name of the cached source", got)
+	}
+
+	for range 3 {
+		if value, _ := lookup(t, source, "HOST"); value != "db-1" {
+			t.Fatalf("got %q, want db-1", value)
+		}
+		if _, ok := lookup(t, source, "MISSING"); ok {
+			t.Fatal("got a value for a missing key")
+		}
+	}
+	if static.Lookups("HOST") != 1 || static.Lookups("MISSING") != 1 {
+		t.Errorf("got %d and %d lookups, want values and missing keys to be cached",
+			static.Lookups("HOST"), static.Lookups("MISSING"))
+	}
+
+	// values are cached until they expire, even if the source changes
+	static.Set("HOST", "db-2")
+	if value, _ := lookup(t, source, "HOST"); value != "db-1" {
+		t.Errorf("got %q, want the cached value", value)
+	}
+}
+
+func TestSourceCacheExpires(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db-1"})
+	source := envstruct.WithSourceCache(static, time.Nanosecond)
+	lookup(t, source, "HOST")
+	static.Set("HOST", "db-2")
+	time.Sleep(time.Millisecond)
+	if value, _ := lookup(t, source, "HOST"); value != "db-2" {
+		t.Errorf("got %q, want the new value once the cached one expired", value)
+	}
+}
+
+func TestSourceCacheExpiresFile(t *testing.T) {
+	file := writeFile(t, "c.json", `{"PORT": 1}`)
+	source := envstruct.WithSourceCache(envstruct.File(file), 50*time.Millisecond)
+	if value, _ := lookup(t, source, "PORT"); value != "1" {
+		t.Fatalf("got %q, want 1", value)
+	}
+	if err := os.WriteFile(file, []byte(`{"PORT": 2}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := lookup(t, source, "PORT"); value != "1" {
+		t.Errorf("got %q before the cached value expired, want 1", value)
+	}
+	time.Sleep(60 * time.Millisecond)
+	// the file is read again rather than the value it loaded first being looked up
+	if value, _ := lookup(t, source, "PORT"); value != "2" {
+		t.Errorf("got %q after the cached value expired, want the value written to the file", value)
+	}
+}
+
+func TestSourceCacheErrors(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db-1"})
+	source := envstruct.WithSourceCache(static, time.Hour)
+	static.Fail(errors.New("unavailable"))
+	if _, _, err := source.Lookup(context.Background(), "HOST"); err == nil {
+		t.Fatal("expected the error of the source")
+	}
+	// errors are not cached
+	static.Fail(nil)
+	if value, _ := lookup(t, source, "HOST"); value != "db-1" {
+		t.Errorf("got %q, want db-1", value)
+	}
+}
+
+func TestStaleSourceCache(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db-1"})
+	source := envstruct.WithStaleSourceCache(static, time.Nanosecond, time.Hour)
+	lookup(t, source, "HOST")
+	time.Sleep(time.Millisecond)
+
+	// a stale value is returned while it is refreshed in the background
+	static.Set("HOST", "db-2")
+	if value, _ := lookup(t, source, "HOST"); value != "db-1" {
+		t.Errorf("got %q, want the stale value", value)
+	}
+	waitFor(t, "the refresh", func() bool {
+		value, _ := lookup(t, source, "HOST")
+		return value == "db-2"
+	})
+
+	// a failed refresh keeps the stale value
+	static.Fail(errors.New("unavailable"))
+	static.Set("HOST", "db-3")
+	before := static.Lookups("HOST")
+	time.Sleep(time.Millisecond)
+	lookup(t, source, "HOST")
+	waitFor(t, "the failed refresh", func() bool {
+		return static.Lookups("HOST") > before
+	})
+	if value, _ := lookup(t, source, "HOST"); value != "db-2" {
+		t.Errorf("got %q, want the stale value after a failed refresh", value)
+	}
+}
+
+func TestSourceCacheChanged(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db-1"})
+	source := envstruct.WithSourceCache(static, time.Hour)
+	changed := source.(envstruct.Notifier).Changed()
+	lookup(t, source, "HOST")
+
+	static.Set("HOST", "db-2")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not forwarded")
+	}
+	if value, _ := lookup(t, source, "HOST"); value != "db-2" {
+		t.Errorf("got %q, want the cache to be cleared on a change", value)
+	}
+}
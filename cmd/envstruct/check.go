@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/dotenv"
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// problem is a variable that fails the check.
+type problem struct {
+	kind   string
+	key    string
+	field  string
+	reason string
+}
+
+// The kinds of problems.
+const (
+	problemMissing = "missing"
+	problemInvalid = "invalid"
+	problemUnknown = "unknown"
+)
+
+func newCheckCommand() *cobra.Command {
+	var sf structFlags
+	var envFiles []string
+	cmd := &cobra.Command{
+		Use:   "check --type Config [package]",
+		Short: "Check the environment against a config struct",
+		Long: "Check evaluates the environment, or the given dotenv files, against the struct in the " +
+			"package, . by default, and reports every variable that is required but missing or that " +
+			"can not be converted to the type of its field. It exits with status 1 if there are " +
+			"problems, so it can gate deployments in CI.\n\n" +
+			"Values resolved with the exec, op or source tag options, fields with custom types and " +
+			"validate rules are not checked.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+
+			lookup := os.Getenv
+			if len(envFiles) > 0 {
+				values, err := readEnvFiles(envFiles)
+				if err != nil {
+					return err
+				}
+				lookup = func(key string) string { return values[key] }
+			}
+
+			problems := check(st, lookup)
+			if len(problems) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "ok: %d variables of %s checked\n", len(st.Fields), st.Name)
+				return nil
+			}
+			if err := writeProblems(cmd.OutOrStdout(), problems); err != nil {
+				return err
+			}
+			return fmt.Errorf("%d of %d variables of %s failed the check", len(problems), len(st.Fields), st.Name)
+		},
+	}
+	cmd.Flags().StringVar(&sf.typeName, "type", "", "name of the config struct type (required)")
+	cmd.Flags().StringArrayVar(&envFiles, "env-file", nil,
+		"dotenv file to check instead of the environment, may be repeated with later files taking precedence")
+	_ = cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+// readEnvFiles reads and merges the dotenv files in order. Unlike the environment, missing files
+// are an error since they were asked for explicitly.
+func readEnvFiles(files []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := dotenv.Read(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading '%s': %w", name, err)
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// check returns the fields of `st` whose value from `lookup` would fail Parse.
+func check(st *schema.Struct, lookup func(string) string) []problem {
+	var problems []problem
+	for _, f := range st.Fields {
+		if f.Has("exec") || f.Has("op") || f.Has("source") {
+			continue
+		}
+		value := lookup(f.Key)
+		if def, ok := f.Default(); ok && value == "" {
+			value = def
+		}
+		if value == "" {
+			if f.Required() {
+				problems = append(problems, problem{kind: problemMissing, key: f.Key, field: f.Path, reason: "missing or blank"})
+			}
+			continue
+		}
+		if err := checkValue(f, value); err != nil {
+			problems = append(problems, problem{kind: problemInvalid, key: f.Key, field: f.Path, reason: err.Error()})
+		}
+	}
+	return problems
+}
+
+// checkValue converts `value` the way Parse would for the type of `f`. Types that can only be
+// converted at runtime are accepted.
+func checkValue(f schema.Field, value string) error {
+	if schema.IsTextUnmarshaler(f.Type) || f.Has("parseurl") || f.Has("quantity") {
+		return nil
+	}
+	basic, ok := f.Type.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+	var err error
+	switch basic.Kind() {
+	case types.Int:
+		_, err = strconv.Atoi(value)
+	case types.Bool:
+		_, err = strconv.ParseBool(value)
+	}
+	if numErr := (*strconv.NumError)(nil); errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	if err != nil {
+		return fmt.Errorf("can not be parsed as %s: %w", f.TypeString(), err)
+	}
+	return nil
+}
+
+// writeProblems prints `problems` as a table.
+func writeProblems(w io.Writer, problems []problem) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tFIELD\tPROBLEM")
+	for _, p := range problems {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.key, p.field, p.reason)
+	}
+	return tw.Flush()
+}
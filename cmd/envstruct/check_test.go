@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEnvFile writes a dotenv file with `content` and returns its path.
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_PORT", "")
+	t.Setenv("DEBUG", "")
+	out, err := runCommand(t, "check", "--type", "Config", configPackage)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if out != "ok: 9 variables of Config checked\n" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestCheckProblems(t *testing.T) {
+	base := writeEnvFile(t, "DB_HOST=db.internal\nDB_PORT=5432\n")
+	override := writeEnvFile(t, "DB_PORT=http\nDEBUG=maybe\n")
+	out, err := runCommand(t, "check", "--type", "Config", "--env-file", base, "--env-file", override, configPackage)
+	if err == nil || err.Error() != "3 of 9 variables of Config failed the check" {
+		t.Fatalf("got error %v, want 3 problems", err)
+	}
+	checkGolden(t, "check.txt", out)
+}
+
+func TestCheckEnvFileErrors(t *testing.T) {
+	if _, err := runCommand(t, "check", "--type", "Config", "--env-file", "missing.env", configPackage); err == nil {
+		t.Error("expected an error for a missing env file")
+	}
+	invalid := writeEnvFile(t, "NOT A LINE\n")
+	_, err := runCommand(t, "check", "--type", "Config", "--env-file", invalid, configPackage)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("got error %v, want the line that failed", err)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newCloudRunCommand() *cobra.Command {
+	var sf structFlags
+	var platform, secretName string
+	var fromEnv bool
+	cmd := &cobra.Command{
+		Use:   "cloudrun --type Config [package]",
+		Short: "Generate the env of a Cloud Run or Knative service from a config struct",
+		Long: "Cloudrun writes the container env section of a Cloud Run or Knative service manifest. " +
+			"Variables with a default are set to it, or with --from-env to their value in the " +
+			"current environment, and variables tagged secret are read with a secretKeyRef.\n\n" +
+			"For Cloud Run each secret refers to the latest version of the Secret Manager secret " +
+			"named after its key in lowercase kebab case, e.g. db-password. For Knative every " +
+			"secret is a key of the Kubernetes Secret named by --secret.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if platform != "cloudrun" && platform != "knative" {
+				return fmt.Errorf("unsupported platform '%s', must be cloudrun or knative", platform)
+			}
+			if secretName == "" {
+				secretName = kubernetesName(st.Name)
+			}
+			env := cloudRunEnv(st, platform, secretName, fromEnv)
+			manifest := map[string]any{
+				"spec": map[string]any{
+					"template": map[string]any{
+						"spec": map[string]any{
+							"containers": []map[string]any{{"env": env}},
+						},
+					},
+				},
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeYAML(w, manifest)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&platform, "platform", "cloudrun", "platform of the service, cloudrun or knative")
+	cmd.Flags().StringVar(&secretName, "secret", "", "name of the Knative Secret (default the type name in kebab case)")
+	cmd.Flags().BoolVar(&fromEnv, "from-env", false, "use the current values of the environment")
+	return cmd
+}
+
+// cloudRunEnv returns the env of the container for `st`.
+func cloudRunEnv(st *schema.Struct, platform, secretName string, fromEnv bool) []envVar {
+	var env []envVar
+	for _, f := range st.Fields {
+		if f.Secret() {
+			ref := &keySelector{Name: secretName, Key: f.Key}
+			if platform == "cloudrun" {
+				ref = &keySelector{Name: strings.ToLower(strings.ReplaceAll(f.Key, "_", "-")), Key: "latest"}
+			}
+			env = append(env, envVar{Name: f.Key, ValueFrom: &envVarSource{SecretKeyRef: ref}})
+			continue
+		}
+		value, ok := f.Default()
+		if current := os.Getenv(f.Key); fromEnv && current != "" {
+			value, ok = current, true
+		}
+		if ok && value != "" {
+			env = append(env, envVar{Name: f.Key, Value: value})
+		}
+	}
+	return env
+}
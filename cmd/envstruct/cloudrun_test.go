@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloudRun(t *testing.T) {
+	goldenTest(t, "cloudrun.yaml", "cloudrun", "--type", "Config", configPackage)
+}
+
+func TestCloudRunKnative(t *testing.T) {
+	goldenTest(t, "knative.yaml", "cloudrun", "--type", "Config", "--platform", "knative", "--secret", "api-secrets", configPackage)
+}
+
+func TestCloudRunFromEnv(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	out, err := runCommand(t, "cloudrun", "--type", "Config", "--from-env", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "value: db.internal") {
+		t.Errorf("got\n%s\nwant DB_HOST from the environment", out)
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("got\n%s\nwant no secret from the environment", out)
+	}
+}
+
+func TestCloudRunInvalidPlatform(t *testing.T) {
+	_, err := runCommand(t, "cloudrun", "--type", "Config", "--platform", "lambda", configPackage)
+	if err == nil || !strings.Contains(err.Error(), "unsupported platform 'lambda'") {
+		t.Fatalf("got error %v, want an unsupported platform", err)
+	}
+}
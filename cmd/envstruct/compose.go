@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/dotenv"
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newComposeCommand() *cobra.Command {
+	var sf structFlags
+	var service string
+	var envFile bool
+	cmd := &cobra.Command{
+		Use:   "compose --type Config [package]",
+		Short: "Generate the environment of a docker-compose service from a config struct",
+		Long: "Compose writes a compose file with the environment of a service, with every variable of " +
+			"the struct taken from the shell running compose. Required variables fail with " +
+			"${KEY:?...} when they are not set and defaults are filled in with ${KEY:-default}.\n\n" +
+			"With --env-file the content of a file for the env_file attribute is written instead, " +
+			"with required variables left blank as placeholders.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if service == "" {
+				service = kubernetesName(st.Name)
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				if envFile {
+					return writeComposeEnvFile(w, st)
+				}
+				return writeYAML(w, composeFile(st, service))
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&service, "service", "", "name of the service (default the type name in kebab case)")
+	cmd.Flags().BoolVar(&envFile, "env-file", false, "write the content of an env_file instead")
+	return cmd
+}
+
+// composeFile returns the compose file for `st` with the environment of the service `service`.
+func composeFile(st *schema.Struct, service string) *yaml.Node {
+	environment := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range st.Fields {
+		environment.Content = append(environment.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: f.Key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: composeValue(f), Style: yaml.DoubleQuotedStyle},
+		)
+	}
+	mapping := func(key string, value *yaml.Node) *yaml.Node {
+		return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: key}, value,
+		}}
+	}
+	root := mapping("services", mapping(service, mapping("environment", environment)))
+	root.HeadComment = fmt.Sprintf("Generated by envstruct from %s.%s.", st.Package.PkgPath, st.Name)
+	return root
+}
+
+// composeValue returns the interpolation of the variable of `f` from the shell.
+func composeValue(f schema.Field) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "$", "$$")
+	}
+	if def, ok := f.Default(); ok {
+		return fmt.Sprintf("${%s:-%s}", f.Key, escape(def))
+	}
+	if f.Required() {
+		return fmt.Sprintf("${%s:?%s is required}", f.Key, f.Key)
+	}
+	return fmt.Sprintf("${%s:-}", f.Key)
+}
+
+// writeComposeEnvFile writes the env_file content for `st`.
+func writeComposeEnvFile(w io.Writer, st *schema.Struct) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	for _, f := range st.Fields {
+		def, _ := f.Default()
+		if f.Required() && def == "" {
+			fmt.Fprintf(&sb, "# %s is required\n", f.Key)
+		}
+		sb.WriteString(f.Key + "=" + dotenv.Quote(def) + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,11 @@
+package main
+
+import "testing"
+
+func TestCompose(t *testing.T) {
+	goldenTest(t, "compose.yaml", "compose", "--type", "Config", "--service", "api", configPackage)
+}
+
+func TestComposeEnvFile(t *testing.T) {
+	goldenTest(t, "compose.env", "compose", "--type", "Config", "--env-file", configPackage)
+}
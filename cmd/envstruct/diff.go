@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newDiffCommand() *cobra.Command {
+	var sf structFlags
+	var envFiles, prefixes []string
+	cmd := &cobra.Command{
+		Use:   "diff --type Config [package]",
+		Short: "Diff the environment or dotenv files against a config struct",
+		Long: "Diff compares the environment, or the given dotenv files, with the struct and reports " +
+			"variables that are set but not read by the struct, variables that are required but " +
+			"missing, and variables whose value does not parse as the type of their field. It exits " +
+			"with status 1 if there are differences.\n\n" +
+			"Every variable of dotenv files is compared. Since the environment holds many unrelated " +
+			"variables, only those starting with a --prefix are compared for it, by default the " +
+			"prefixes up to the first underscore of the keys of the struct, e.g. DB_ for DB_HOST.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+
+			values := make(map[string]string)
+			if len(envFiles) > 0 {
+				if values, err = readEnvFiles(envFiles); err != nil {
+					return err
+				}
+			} else {
+				if len(prefixes) == 0 {
+					prefixes = keyPrefixes(st)
+				}
+				for _, env := range os.Environ() {
+					key, value, _ := strings.Cut(env, "=")
+					if hasAnyPrefix(key, prefixes) {
+						values[key] = value
+					}
+				}
+			}
+
+			problems := diff(st, values)
+			if len(problems) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "no differences with %s\n", st.Name)
+				return nil
+			}
+			if err := writeDiff(cmd.OutOrStdout(), problems); err != nil {
+				return err
+			}
+			return fmt.Errorf("%d differences with %s", len(problems), st.Name)
+		},
+	}
+	cmd.Flags().StringVar(&sf.typeName, "type", "", "name of the config struct type (required)")
+	cmd.Flags().StringArrayVar(&envFiles, "env-file", nil,
+		"dotenv file to compare instead of the environment, may be repeated with later files taking precedence")
+	cmd.Flags().StringSliceVar(&prefixes, "prefix", nil, "prefix of the environment variables to compare")
+	_ = cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+// diff returns the differences between `values` and `st`: the problems found by check, followed
+// by the keys of `values` that no field of `st` reads, in sorted order.
+func diff(st *schema.Struct, values map[string]string) []problem {
+	problems := check(st, func(key string) string { return values[key] })
+	known := make(map[string]bool, len(st.Fields))
+	for _, f := range st.Fields {
+		known[f.Key] = true
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, problem{kind: problemUnknown, key: key, reason: "set but not read by " + st.Name})
+	}
+	return problems
+}
+
+// keyPrefixes returns the distinct prefixes up to and including the first underscore of the keys
+// of `st`, or the whole key if it has no underscore.
+func keyPrefixes(st *schema.Struct) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, f := range st.Fields {
+		prefix := f.Key
+		if i := strings.Index(f.Key, "_"); i > 0 {
+			prefix = f.Key[:i+1]
+		}
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeDiff prints `problems` as a table.
+func writeDiff(w io.Writer, problems []problem) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIFF\tKEY\tFIELD\tDETAIL")
+	for _, p := range problems {
+		field := p.field
+		if field == "" {
+			field = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.kind, p.key, field, p.reason)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffEnvFiles(t *testing.T) {
+	base := writeEnvFile(t, "DB_HOST=db.internal\nDB_PORT=5432\nDB_NAME=orders\n")
+	override := writeEnvFile(t, "DB_PORT=http\nCACHE_URL=redis://cache\n")
+	out, err := runCommand(t, "diff", "--type", "Config", "--env-file", base, "--env-file", override, configPackage)
+	if err == nil || err.Error() != "4 differences with Config" {
+		t.Fatalf("got error %v, want 4 differences", err)
+	}
+	checkGolden(t, "diff.txt", out)
+}
+
+func TestDiffNoDifferences(t *testing.T) {
+	values := writeEnvFile(t, "DB_HOST=db.internal\nDB_PASSWORD=hunter2\nLOG_LEVEL=debug\n")
+	out, err := runCommand(t, "diff", "--type", "Config", "--env-file", values, configPackage)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if out != "no differences with Config\n" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestDiffEnvironment(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_NAME", "orders")
+	t.Setenv("UNRELATED_SETTING", "on")
+	out, _ := runCommand(t, "diff", "--type", "Config", configPackage)
+	// only variables with the prefix of a key of the struct are compared
+	if !strings.Contains(out, "DB_NAME") {
+		t.Errorf("got\n%s\nwant DB_NAME, which starts with the DB_ prefix", out)
+	}
+	if strings.Contains(out, "UNRELATED_SETTING") {
+		t.Errorf("got\n%s\nwant no variables without a prefix of the struct", out)
+	}
+
+	out, err := runCommand(t, "diff", "--type", "Config", "--prefix", "UNRELATED_", configPackage)
+	if err == nil {
+		t.Fatal("expected UNRELATED_SETTING to differ")
+	}
+	if !strings.Contains(out, "UNRELATED_SETTING") || strings.Contains(out, "DB_NAME") {
+		t.Errorf("got\n%s\nwant only the variables with the given prefix", out)
+	}
+}
+
+func TestKeyPrefixes(t *testing.T) {
+	sf := structFlags{typeName: "Config"}
+	st, err := sf.load([]string{configPackage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DB_", "DEBUG", "LOG_", "API_", "LEGACY_", "SERVER_"}
+	if got := keyPrefixes(st); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newDockerfileCommand() *cobra.Command {
+	var sf structFlags
+	var args bool
+	cmd := &cobra.Command{
+		Use:   "dockerfile --type Config [package]",
+		Short: "Generate Dockerfile ENV and ARG lines from a config struct",
+		Long: "Dockerfile writes ENV lines that set the defaults of the struct in an image, to be " +
+			"pasted or included in a Dockerfile. Variables tagged secret are never written.\n\n" +
+			"With --args every variable also gets an ARG, defaulting to the default of the field, so " +
+			"it can be overridden with --build-arg. Variables without a default are then set to a " +
+			"blank value, which Parse treats as unset.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, pkgArgs []string) error {
+			st, err := sf.load(pkgArgs)
+			if err != nil {
+				return err
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeDockerfile(w, st, args)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().BoolVar(&args, "args", false, "add an ARG for every variable")
+	return cmd
+}
+
+// writeDockerfile writes the ARG and ENV lines for `st`.
+func writeDockerfile(w io.Writer, st *schema.Struct, args bool) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	var env []string
+	for _, f := range st.Fields {
+		if f.Secret() {
+			continue
+		}
+		def, hasDefault := f.Default()
+		if args {
+			if hasDefault {
+				fmt.Fprintf(&sb, "ARG %s=%s\n", f.Key, dockerfileQuote(def))
+			} else {
+				fmt.Fprintf(&sb, "ARG %s\n", f.Key)
+			}
+			env = append(env, fmt.Sprintf("%s=\"${%s}\"", f.Key, f.Key))
+			continue
+		}
+		if hasDefault {
+			env = append(env, f.Key+"="+dockerfileQuote(def))
+		}
+	}
+	if len(env) > 0 {
+		sb.WriteString("ENV " + strings.Join(env, " \\\n    ") + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dockerfileQuote double quotes `value` for an ARG or ENV instruction, escaping variable
+// substitution.
+func dockerfileQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockerfile(t *testing.T) {
+	goldenTest(t, "Dockerfile", "dockerfile", "--type", "Config", configPackage)
+}
+
+func TestDockerfileArgs(t *testing.T) {
+	goldenTest(t, "Dockerfile.args", "dockerfile", "--type", "Config", "--args", configPackage)
+}
+
+func TestDockerfileSecrets(t *testing.T) {
+	for _, args := range [][]string{nil, {"--args"}} {
+		out, err := runCommand(t, append([]string{"dockerfile", "--type", "Config"}, append(args, configPackage)...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"DB_PASSWORD", "API_TOKEN"} {
+			if strings.Contains(out, key) {
+				t.Errorf("got\n%s\nwant no secret %s", out, key)
+			}
+		}
+	}
+}
+
+func TestDockerfileQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":      `"plain"`,
+		"$HOME":      `"\$HOME"`,
+		`say "hi"\`:  `"say \"hi\"\\"`,
+		"two\nlines": `"two\nlines"`,
+	}
+	for value, want := range tests {
+		if got := dockerfileQuote(value); got != want {
+			t.Errorf("dockerfileQuote(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
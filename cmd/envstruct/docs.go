@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newDocsCommand() *cobra.Command {
+	var sf structFlags
+	var title string
+	cmd := &cobra.Command{
+		Use:   "docs --type Config [package]",
+		Short: "Generate Markdown reference docs for a config struct",
+		Long: "Docs writes a Markdown table with the key, type, whether it is required, the default " +
+			"and the desc tag of every variable of the struct, to be shipped as the configuration " +
+			"reference of a release.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if title == "" {
+				title = st.Name
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeDocs(w, st, title)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&title, "title", "", "heading of the document (default the type name)")
+	return cmd
+}
+
+// writeDocs writes the Markdown reference for `st` under the heading `title`.
+func writeDocs(w io.Writer, st *schema.Struct, title string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "<!-- Generated by envstruct from %s.%s. DO NOT EDIT. -->\n\n", st.Package.PkgPath, st.Name)
+	sb.WriteString("| Key | Type | Required | Default | Description |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, f := range st.Fields {
+		required := "no"
+		if f.Required() {
+			required = "yes"
+		}
+		def := ""
+		if value, ok := f.Default(); ok {
+			def = markdownCode(value)
+		}
+		desc := f.Desc()
+		if f.Secret() {
+			desc = strings.TrimSpace("**Secret.** " + desc)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n",
+			markdownCode(f.Key), markdownCode(f.TypeString()), required, def, markdownText(desc))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownCode formats `s` as inline code that is safe in a table cell.
+func markdownCode(s string) string {
+	if s == "" {
+		return "`\"\"`"
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + strings.ReplaceAll(s, "|", `\|`) + fence
+}
+
+// markdownText makes `s` safe in a table cell.
+func markdownText(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestDocs(t *testing.T) {
+	goldenTest(t, "docs.md", "docs", "--type", "Config", "--title", "API configuration", configPackage)
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := map[string]string{
+		"":        "`\"\"`",
+		"5432":    "`5432`",
+		"a|b":     "`a\\|b`",
+		"`quoted": "`` `quoted ``",
+		"a`b":     "``a`b``",
+	}
+	for s, want := range tests {
+		if got := markdownCode(s); got != want {
+			t.Errorf("markdownCode(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// finding is a problem found by the doctor command and how to fix it.
+type finding struct {
+	priority int
+	kind     string
+	key      string
+	problem  string
+	fix      string
+}
+
+// The priorities of findings, most urgent first.
+const (
+	priorityMissing = iota
+	priorityInvalid
+	priorityNearMiss
+	priorityDeprecated
+	priorityDuplicate
+)
+
+func newDoctorCommand() *cobra.Command {
+	var sf structFlags
+	var schemaFile string
+	var envFiles []string
+	cmd := &cobra.Command{
+		Use:   "doctor (--type Config [package] | --schema file)",
+		Short: "Diagnose the environment of a service and explain how to fix it",
+		Long: "Doctor checks the environment against a config struct and prints the problems it finds, " +
+			"most urgent first, with the steps to fix them: required variables that are missing, " +
+			"values that do not parse or fail a validate rule, variables whose name is a near miss " +
+			"of a key, deprecated variables that are still set, and variables set by more than one " +
+			"source. It exits with status 1 if there are problems that would fail Parse.\n\n" +
+			"Containers rarely include the source, so instead of --type the JSON Schema written by " +
+			"the jsonschema command can be given with --schema:\n\n" +
+			"\tenvstruct jsonschema --type Config -o config.schema.json\n" +
+			"\tenvstruct doctor --schema /etc/app/config.schema.json",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var doc jsonSchema
+			var duplicates []finding
+			switch {
+			case schemaFile != "" && sf.typeName != "":
+				return errors.New("only one of --type and --schema can be given")
+			case schemaFile != "":
+				data, err := os.ReadFile(schemaFile)
+				if err != nil {
+					return err
+				}
+				if err := json.Unmarshal(data, &doc); err != nil {
+					return fmt.Errorf("error reading schema '%s': %w", schemaFile, err)
+				}
+			case sf.typeName != "":
+				st, err := sf.load(args)
+				if err != nil {
+					return err
+				}
+				doc = newJSONSchema(st)
+				fields := make(map[string]string)
+				for _, f := range st.Fields {
+					if first, ok := fields[f.Key]; ok {
+						duplicates = append(duplicates, finding{
+							priority: priorityDuplicate,
+							kind:     "duplicate",
+							key:      f.Key,
+							problem:  fmt.Sprintf("%s is read by both %s and %s, which get the same value.", f.Key, first, f.Path),
+							fix:      "Rename the key of one of the fields if they are meant to be configured separately.",
+						})
+					}
+					fields[f.Key] = f.Path
+				}
+			default:
+				return errors.New("one of --type and --schema is required")
+			}
+
+			env := make(map[string]string)
+			for _, kv := range os.Environ() {
+				key, value, _ := strings.Cut(kv, "=")
+				env[key] = value
+			}
+			files := make(map[string]string)
+			if len(envFiles) > 0 {
+				var err error
+				if files, err = readEnvFiles(envFiles); err != nil {
+					return err
+				}
+			}
+
+			findings := append(diagnose(doc, env, files), duplicates...)
+			sort.SliceStable(findings, func(i, j int) bool {
+				return findings[i].priority < findings[j].priority
+			})
+			if err := writeFindings(cmd.OutOrStdout(), doc.Title, findings); err != nil {
+				return err
+			}
+			for _, f := range findings {
+				if f.priority <= priorityInvalid {
+					return fmt.Errorf("%s can not be loaded from this environment", doc.Title)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&sf.typeName, "type", "", "name of the config struct type")
+	cmd.Flags().StringVar(&schemaFile, "schema", "", "JSON Schema written by the jsonschema command")
+	cmd.Flags().StringArrayVar(&envFiles, "env-file", nil,
+		"dotenv file that is loaded by the service, may be repeated with later files taking precedence")
+	return cmd
+}
+
+// diagnose returns the findings for the variables of `doc`. Values are taken from `env`, then
+// `files`, like LoadDotEnv.
+func diagnose(doc jsonSchema, env, files map[string]string) []finding {
+	required := make(map[string]bool, len(doc.Required))
+	for _, key := range doc.Required {
+		required[key] = true
+	}
+	lookup := func(key string) (string, string) {
+		if value := env[key]; value != "" {
+			return value, "the environment"
+		}
+		return files[key], "the env file"
+	}
+
+	var findings []finding
+	for _, key := range doc.Properties.keys {
+		prop := doc.Properties.values[key]
+		value, from := lookup(key)
+
+		if fileValue, ok := files[key]; ok && env[key] != "" && fileValue != env[key] {
+			findings = append(findings, finding{
+				priority: priorityDuplicate,
+				kind:     "duplicate",
+				key:      key,
+				problem:  fmt.Sprintf("%s is set in both the environment and the env file, with different values.", key),
+				fix:      "The value from the environment is used. Remove one of them to avoid confusion.",
+			})
+		}
+		if value != "" && prop.Deprecated {
+			findings = append(findings, finding{
+				priority: priorityDeprecated,
+				kind:     "deprecated",
+				key:      key,
+				problem:  fmt.Sprintf("%s is deprecated but is set in %s.", key, from),
+				fix:      deprecatedHint(key, prop),
+			})
+		}
+
+		if value == "" {
+			if prop.Default != nil {
+				value = jsonString(prop.Default)
+			} else {
+				if required[key] {
+					findings = append(findings, finding{
+						priority: priorityMissing,
+						kind:     "missing",
+						key:      key,
+						problem:  fmt.Sprintf("%s is required but not set.", key),
+						fix:      setHint(key, prop),
+					})
+				}
+				continue
+			}
+		}
+		if err := checkProperty(prop, value); err != nil {
+			shown := strconv.Quote(value)
+			if prop.WriteOnly {
+				shown = "the secret value"
+			}
+			findings = append(findings, finding{
+				priority: priorityInvalid,
+				kind:     "invalid",
+				key:      key,
+				problem:  fmt.Sprintf("%s is set to %s in %s, which %s.", key, shown, from, err),
+				fix:      setHint(key, prop),
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(env, files) {
+		if _, known := doc.Properties.values[key]; known {
+			continue
+		}
+		match := nearMiss(key, doc.Properties.keys)
+		if match == "" || env[match] != "" || files[match] != "" {
+			continue
+		}
+		findings = append(findings, finding{
+			priority: priorityNearMiss,
+			kind:     "near miss",
+			key:      key,
+			problem:  fmt.Sprintf("%s is set but not read, and %s is not set.", key, match),
+			fix:      fmt.Sprintf("Rename %s to %s if it is a typo.", key, match),
+		})
+	}
+	return findings
+}
+
+// checkProperty reports why `value` does not satisfy `prop`, phrased to follow "which".
+func checkProperty(prop jsonProperty, value string) error {
+	var n int64
+	switch prop.Type {
+	case "integer":
+		converted, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("is not a whole number")
+		}
+		n = int64(converted)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("is not true or false")
+		}
+	default:
+		n = int64(len([]rune(value)))
+	}
+
+	if len(prop.Enum) > 0 {
+		allowed := make([]string, len(prop.Enum))
+		for i, v := range prop.Enum {
+			allowed[i] = jsonString(v)
+		}
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("is not one of %s", strings.Join(allowed, ", "))
+		}
+	}
+	unit := ""
+	minimum, maximum := prop.Minimum, prop.Maximum
+	if prop.Type == "string" {
+		unit = " characters long"
+		minimum, maximum = prop.MinLength, prop.MaxLength
+	}
+	if minimum != nil && n < *minimum {
+		return fmt.Errorf("is less than %d%s", *minimum, unit)
+	}
+	if maximum != nil && n > *maximum {
+		return fmt.Errorf("is more than %d%s", *maximum, unit)
+	}
+	return nil
+}
+
+// setHint returns the step to set `key` to a valid value.
+func setHint(key string, prop jsonProperty) string {
+	expected := map[string]string{"integer": "a whole number", "boolean": "true or false"}[prop.Type]
+	if expected == "" {
+		expected = "a valid value"
+	}
+	hint := fmt.Sprintf("Set %s to %s", key, expected)
+	if len(prop.Enum) > 0 {
+		allowed := make([]string, len(prop.Enum))
+		for i, v := range prop.Enum {
+			allowed[i] = jsonString(v)
+		}
+		hint = fmt.Sprintf("Set %s to one of %s", key, strings.Join(allowed, ", "))
+	}
+	if prop.WriteOnly {
+		hint += ", e.g. from the secret store of the deployment"
+	}
+	hint += "."
+	if desc := firstLine(prop.Description); desc != "" {
+		hint += " " + key + ": " + desc
+	}
+	return hint
+}
+
+// deprecatedHint returns the step to stop using the deprecated `key`.
+func deprecatedHint(key string, prop jsonProperty) string {
+	msg := strings.TrimPrefix(firstLine(prop.Description), "Deprecated: ")
+	if msg == "" {
+		return fmt.Sprintf("Stop setting %s.", key)
+	}
+	return fmt.Sprintf("Stop setting %s and %s.", key, strings.TrimSuffix(msg, "."))
+}
+
+// nearMiss returns the key that `name` is most likely a misspelling of, or a blank string.
+func nearMiss(name string, keys []string) string {
+	best, bestDist := "", 3
+	for _, key := range keys {
+		if strings.EqualFold(name, key) {
+			return key
+		}
+		if len(key) < 4 {
+			continue
+		}
+		if dist := editDistance(name, key); dist < bestDist {
+			best, bestDist = key, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between `a` and `b`.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// writeFindings prints the findings as numbered steps.
+func writeFindings(w io.Writer, name string, findings []finding) error {
+	var sb strings.Builder
+	if len(findings) == 0 {
+		fmt.Fprintf(&sb, "No problems found with the environment of %s.\n", name)
+	} else {
+		fmt.Fprintf(&sb, "Found %d problems with the environment of %s, most urgent first:\n", len(findings), name)
+	}
+	for i, f := range findings {
+		fmt.Fprintf(&sb, "\n%d. [%s] %s\n", i+1, f.kind, f.problem)
+		if f.fix != "" {
+			fmt.Fprintf(&sb, "   Fix: %s\n", f.fix)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// jsonString formats a JSON value of a schema as the environment variable it stands for.
+func jsonString(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// sortedKeys returns the keys of the maps in sorted order, without duplicates.
+func sortedKeys(maps ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setDoctorEnv sets the variables of the test config, unsetting the others so the environment of
+// the test does not leak into the findings.
+func setDoctorEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	for _, key := range []string{"DB_HOST", "DB_PORT", "DB_PASSWORD", "DEBUG", "LOG_LEVEL", "API_TOKEN", "LEGACY_HOST", "SERVER_NAME", "SERVER_TIMEOUT"} {
+		t.Setenv(key, values[key])
+	}
+	for key, value := range values {
+		t.Setenv(key, value)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	setDoctorEnv(t, map[string]string{
+		"DB_HOTS":     "db.internal",
+		"DB_PORT":     "70000",
+		"DEBUG":       "false",
+		"LOG_LEVEL":   "trace",
+		"LEGACY_HOST": "db.old",
+	})
+	envFile := writeEnvFile(t, "DEBUG=true\nSERVER_TIMEOUT=1m\n")
+	out, err := runCommand(t, "doctor", "--type", "Config", "--env-file", envFile, configPackage)
+	if err == nil || err.Error() != "Config can not be loaded from this environment" {
+		t.Fatalf("got error %v, want Config to fail to load", err)
+	}
+	checkGolden(t, "doctor.txt", out)
+}
+
+func TestDoctorSchema(t *testing.T) {
+	schema, err := runCommand(t, "jsonschema", "--type", "Config", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaFile := filepath.Join(t.TempDir(), "config.schema.json")
+	if err := os.WriteFile(schemaFile, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	setDoctorEnv(t, map[string]string{"DB_HOST": "db.internal", "DB_PASSWORD": "hunter2"})
+	out, err := runCommand(t, "doctor", "--schema", schemaFile)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if out != "No problems found with the environment of Config.\n" {
+		t.Errorf("got %q", out)
+	}
+
+	// findings that do not fail Parse do not fail the command
+	setDoctorEnv(t, map[string]string{"DB_HOST": "db.internal", "DB_PASSWORD": "hunter2", "LEGACY_HOST": "db.old"})
+	out, err = runCommand(t, "doctor", "--schema", schemaFile)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if !strings.Contains(out, "[deprecated] LEGACY_HOST is deprecated") {
+		t.Errorf("got\n%s\nwant LEGACY_HOST to be deprecated", out)
+	}
+}
+
+func TestDoctorDuplicateFields(t *testing.T) {
+	setDoctorEnv(t, map[string]string{"DB_HOST": "db.internal"})
+	out, err := runCommand(t, "doctor", "--type", "Config", "./testdata/doctor")
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if !strings.Contains(out, "[duplicate] DB_HOST is read by both Primary.Host and Replica.Host") {
+		t.Errorf("got\n%s\nwant DB_HOST to be read by two fields", out)
+	}
+}
+
+func TestDoctorFlags(t *testing.T) {
+	if _, err := runCommand(t, "doctor", configPackage); err == nil {
+		t.Error("expected an error without --type or --schema")
+	}
+	if _, err := runCommand(t, "doctor", "--type", "Config", "--schema", "config.schema.json", configPackage); err == nil {
+		t.Error("expected an error with both --type and --schema")
+	}
+	invalid := writeEnvFile(t, "not json")
+	if _, err := runCommand(t, "doctor", "--schema", invalid); err == nil {
+		t.Error("expected an error for a schema that is not JSON")
+	}
+}
+
+func TestNearMiss(t *testing.T) {
+	keys := []string{"DB_HOST", "DB_PORT", "TZ"}
+	tests := map[string]string{
+		"db_host":  "DB_HOST",
+		"DB_HOTS":  "DB_HOST",
+		"DBHOST":   "DB_HOST",
+		"DB_PORTS": "DB_PORT",
+		"DB_NAME":  "",
+		"TX":       "",
+	}
+	for name, want := range tests {
+		if got := nearMiss(name, keys); got != want {
+			t.Errorf("nearMiss(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"DB_HOST", "DB_HOST", 0},
+		{"DB_HOTS", "DB_HOST", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
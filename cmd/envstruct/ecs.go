@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// ecsEnvironment is the environment of a container definition of an ECS task definition.
+type ecsEnvironment struct {
+	Environment []ecsKeyValue `json:"environment"`
+	Secrets     []ecsSecret   `json:"secrets"`
+}
+
+// ecsKeyValue is an entry of the `environment` array.
+type ecsKeyValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ecsSecret is an entry of the `secrets` array.
+type ecsSecret struct {
+	Name      string `json:"name"`
+	ValueFrom string `json:"valueFrom"`
+}
+
+func newECSCommand() *cobra.Command {
+	var sf structFlags
+	var secretPrefix string
+	var fromEnv bool
+	cmd := &cobra.Command{
+		Use:   "ecs --type Config [package]",
+		Short: "Generate the environment and secrets of an ECS container definition",
+		Long: "ECS writes the environment and secrets arrays of a container definition of an ECS task " +
+			"definition as JSON. Variables with a default are added to environment, or with " +
+			"--from-env all variables that are set in the current environment. Variables tagged " +
+			"secret are added to secrets, read from the SSM parameter or Secrets Manager secret " +
+			"named by --secret-prefix followed by the key, e.g.\n\n" +
+			"\t--secret-prefix arn:aws:ssm:us-east-1:123456789012:parameter/app/",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			env := newECSEnvironment(st, secretPrefix, fromEnv)
+			return sf.write(cmd, func(w io.Writer) error {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(env)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&secretPrefix, "secret-prefix", "", "ARN or name prefix of the secrets, followed by the key")
+	cmd.Flags().BoolVar(&fromEnv, "from-env", false, "use the current values of the environment")
+	return cmd
+}
+
+// newECSEnvironment returns the environment and secrets for `st`.
+func newECSEnvironment(st *schema.Struct, secretPrefix string, fromEnv bool) ecsEnvironment {
+	env := ecsEnvironment{Environment: []ecsKeyValue{}, Secrets: []ecsSecret{}}
+	for _, f := range st.Fields {
+		if f.Secret() {
+			env.Secrets = append(env.Secrets, ecsSecret{Name: f.Key, ValueFrom: secretPrefix + f.Key})
+			continue
+		}
+		value, ok := f.Default()
+		if current := os.Getenv(f.Key); fromEnv && current != "" {
+			value, ok = current, true
+		}
+		if ok {
+			env.Environment = append(env.Environment, ecsKeyValue{Name: f.Key, Value: value})
+		}
+	}
+	return env
+}
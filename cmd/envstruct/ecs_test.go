@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestECS(t *testing.T) {
+	goldenTest(t, "ecs.json", "ecs", "--type", "Config", "--secret-prefix", "arn:aws:ssm:us-east-1:123456789012:parameter/app/", configPackage)
+}
+
+func TestECSFromEnv(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("DEBUG", "")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	out, err := runCommand(t, "ecs", "--type", "Config", "--from-env", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env ecsEnvironment
+	if err := json.Unmarshal([]byte(out), &env); err != nil {
+		t.Fatal(err)
+	}
+	// blank variables keep their default, and secrets are never taken from the environment
+	want := []ecsKeyValue{
+		{"DB_HOST", "db.internal"},
+		{"DB_PORT", "5432"},
+		{"DEBUG", "false"},
+		{"LOG_LEVEL", "debug"},
+		{"SERVER_NAME", `api "$HOSTNAME"`},
+		{"SERVER_TIMEOUT", "30s"},
+	}
+	if !slices.Equal(env.Environment, want) {
+		t.Errorf("got environment %+v, want %+v", env.Environment, want)
+	}
+	wantSecrets := []ecsSecret{{"DB_PASSWORD", "DB_PASSWORD"}, {"API_TOKEN", "API_TOKEN"}}
+	if !slices.Equal(env.Secrets, wantSecrets) {
+		t.Errorf("got secrets %+v, want %+v", env.Secrets, wantSecrets)
+	}
+}
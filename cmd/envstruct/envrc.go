@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newEnvrcCommand() *cobra.Command {
+	var sf structFlags
+	var dotenvFile string
+	cmd := &cobra.Command{
+		Use:   "envrc --type Config [package]",
+		Short: "Generate a direnv .envrc from a config struct",
+		Long: "Envrc writes a .envrc for direnv that exports the defaults of the struct, unless they " +
+			"are already set, with a '# required:' comment for each required variable. With " +
+			"--dotenv the given dotenv file is loaded first instead of exporting defaults. Secrets " +
+			"are expected in .envrc.local, which is sourced if it exists and should not be " +
+			"committed, and direnv reports required variables that are still missing.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeEnvrc(w, st, dotenvFile)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&dotenvFile, "dotenv", "", "dotenv file to load instead of exporting defaults")
+	return cmd
+}
+
+// writeEnvrc writes the .envrc for `st`.
+func writeEnvrc(w io.Writer, st *schema.Struct, dotenvFile string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	var required []string
+	for _, f := range st.Fields {
+		if f.Required() {
+			line := fmt.Sprintf("# required: %s (%s)", f.Key, f.TypeString())
+			if desc := f.Desc(); desc != "" && !strings.Contains(desc, "\n") {
+				line += " - " + desc
+			}
+			sb.WriteString(line + "\n")
+			required = append(required, f.Key)
+		}
+	}
+	sb.WriteString("\n")
+
+	if dotenvFile != "" {
+		fmt.Fprintf(&sb, "dotenv_if_exists %s\n", shellQuote(dotenvFile))
+	} else {
+		for _, f := range st.Fields {
+			if def, ok := f.Default(); ok && !f.Secret() {
+				fmt.Fprintf(&sb, "export %s=\"${%s:-%s}\"\n", f.Key, f.Key, doubleQuoteShell(def))
+			}
+		}
+	}
+	sb.WriteString("source_env_if_exists .envrc.local\n")
+	if len(required) > 0 {
+		fmt.Fprintf(&sb, "env_vars_required %s\n", strings.Join(required, " "))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// doubleQuoteShell escapes `s` for use inside double quotes in a POSIX shell.
+func doubleQuoteShell(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvrc(t *testing.T) {
+	goldenTest(t, "envrc", "envrc", "--type", "Config", configPackage)
+}
+
+func TestEnvrcDotenv(t *testing.T) {
+	goldenTest(t, "envrc-dotenv", "envrc", "--type", "Config", "--dotenv", ".env.local", configPackage)
+}
+
+// direnvStdlib stubs the functions of the direnv stdlib used by generated .envrc files.
+const direnvStdlib = `
+source_env_if_exists() { :; }
+dotenv_if_exists() { :; }
+env_vars_required() { :; }
+`
+
+func TestEnvrcKeepsSetVariables(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell")
+	}
+	out, err := runCommand(t, "envrc", "--type", "Config", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envrc := filepath.Join(t.TempDir(), ".envrc")
+	if err := os.WriteFile(envrc, []byte(direnvStdlib+out), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("DB_PORT", "")
+	got, err := exec.Command(sh, "-c", `. "$1" && printf '%s|%s|%s' "$LOG_LEVEL" "$DB_PORT" "$SERVER_NAME"`, "sh", envrc).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `debug|5432|api "$HOSTNAME"`; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDoubleQuoteShell(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"$HOME":      `\$HOME`,
+		"`date`":     "\\`date\\`",
+		`say "hi"\`:  `say \"hi\"\\`,
+		"it's plain": "it's plain",
+	}
+	for s, want := range tests {
+		if got := doubleQuoteShell(s); got != want {
+			t.Errorf("doubleQuoteShell(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
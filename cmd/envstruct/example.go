@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/dotenv"
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newExampleCommand() *cobra.Command {
+	var sf structFlags
+	cmd := &cobra.Command{
+		Use:   "example --type Config [package]",
+		Short: "Generate a .env.example file from a config struct",
+		Long: "Example writes a dotenv file with a line for every variable of the struct, set to its " +
+			"default, with the type, whether it is required and the desc tag in a comment above it. " +
+			"Secrets are always left blank. Add a go:generate directive to keep the file in sync:\n\n" +
+			"\t//go:generate go run github.com/jha-captech/env-vars-to-struct/cmd/envstruct example --type Config -o .env.example",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeExample(w, st)
+			})
+		},
+	}
+	sf.register(cmd)
+	return cmd
+}
+
+// writeExample writes the dotenv example for `st`, with fields separated by blank lines.
+func writeExample(w io.Writer, st *schema.Struct) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s. DO NOT EDIT.\n", st.Package.PkgPath, st.Name)
+	for _, f := range st.Fields {
+		sb.WriteString("\n")
+		notes := []string{f.TypeString()}
+		if f.Required() {
+			notes = append(notes, "required")
+		}
+		if f.Secret() {
+			notes = append(notes, "secret")
+		}
+		fmt.Fprintf(&sb, "# %s (%s)\n", f.Key, strings.Join(notes, ", "))
+		if desc := f.Desc(); desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				sb.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+			}
+		}
+		value, _ := f.Default()
+		if f.Secret() {
+			value = ""
+		}
+		sb.WriteString(f.Key + "=" + dotenv.Quote(value) + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
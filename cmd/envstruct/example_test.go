@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	goldenTest(t, "example.env", "example", "--type", "Config", configPackage)
+}
+
+func TestExampleOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), ".env.example")
+	out, err := runCommand(t, "example", "--type", "WorkerConfig", "-o", output, configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "" {
+		t.Errorf("got output %q, want it written to the file", out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "example-worker.env", string(data))
+}
+
+func TestExampleErrors(t *testing.T) {
+	if _, err := runCommand(t, "example", configPackage); err == nil {
+		t.Error("expected an error without --type")
+	}
+	if _, err := runCommand(t, "example", "--type", "Missing", configPackage); err == nil {
+		t.Error("expected an error for a missing type")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newHelmCommand() *cobra.Command {
+	var sf structFlags
+	var valuesFile, templateFile, define string
+	cmd := &cobra.Command{
+		Use:   "helm --type Config [package]",
+		Short: "Generate Helm values and an env template from a config struct",
+		Long: "Helm generates the values and a named template for a Helm chart. The values have a " +
+			"lowerCamelCase key under config for every variable of the struct, set to its default, " +
+			"and secretName for the Secret that holds variables tagged secret. The template renders " +
+			"the env block of a container from them, failing the render if a required value is blank:\n\n" +
+			"\tenv:\n\t  {{- include \"config.env\" . | nindent 12 }}\n\n" +
+			"Both are printed unless --values or --template is given.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if define == "" {
+				define = kubernetesName(st.Name) + ".env"
+			}
+			values, err := helmValues(st)
+			if err != nil {
+				return err
+			}
+			template := helmTemplate(st, define)
+			if valuesFile == "" && templateFile == "" {
+				_, err := fmt.Fprintf(cmd.OutOrStdout(), "# values.yaml\n%s\n# templates/_env.tpl\n%s", values, template)
+				return err
+			}
+			for _, out := range [][2]string{{valuesFile, values}, {templateFile, template}} {
+				if out[0] == "" {
+					continue
+				}
+				if err := writeOutput(cmd, out[0], func(w io.Writer) error {
+					_, err := io.WriteString(w, out[1])
+					return err
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&sf.typeName, "type", "", "name of the config struct type (required)")
+	cmd.Flags().StringVar(&valuesFile, "values", "", "file to write the values to")
+	cmd.Flags().StringVar(&templateFile, "template", "", "file to write the template to")
+	cmd.Flags().StringVar(&define, "define", "", "name of the template (default <type in kebab case>.env)")
+	_ = cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+// helmValues returns the values.yaml content for `st`, with the type and description of each
+// variable in a comment.
+func helmValues(st *schema.Struct) (string, error) {
+	config := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range st.Fields {
+		if f.Secret() {
+			continue
+		}
+		def, _ := f.Default()
+		comment := fmt.Sprintf("%s (%s)", f.Key, f.TypeString())
+		if f.Required() {
+			comment += ", required"
+		}
+		if desc := f.Desc(); desc != "" {
+			comment += "\n" + desc
+		}
+		config.Content = append(config.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: helmValueName(f.Key), HeadComment: comment},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: def, Style: yaml.DoubleQuotedStyle},
+		)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "config"}, config,
+		{Kind: yaml.ScalarNode, Value: "secretName", HeadComment: "Secret holding the variables tagged secret"},
+		{Kind: yaml.ScalarNode, Value: kubernetesName(st.Name), Style: yaml.DoubleQuotedStyle},
+	}}
+	root.HeadComment = fmt.Sprintf("Generated by envstruct from %s.%s.", st.Package.PkgPath, st.Name)
+
+	var sb strings.Builder
+	if err := writeYAML(&sb, root); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// helmTemplate returns the named template `define` that renders the env block for `st`.
+func helmTemplate(st *schema.Struct, define string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "{{/* Generated by envstruct from %s.%s. DO NOT EDIT. */}}\n", st.Package.PkgPath, st.Name)
+	fmt.Fprintf(&sb, "{{- define %q -}}\n", define)
+	for _, f := range st.Fields {
+		fmt.Fprintf(&sb, "- name: %s\n", f.Key)
+		if f.Secret() {
+			sb.WriteString("  valueFrom:\n    secretKeyRef:\n")
+			sb.WriteString("      name: {{ .Values.secretName | quote }}\n")
+			fmt.Fprintf(&sb, "      key: %s\n", f.Key)
+			if !f.Required() {
+				sb.WriteString("      optional: true\n")
+			}
+			continue
+		}
+		value := ".Values.config." + helmValueName(f.Key)
+		if f.Required() {
+			value = fmt.Sprintf("required %q %s", "config."+helmValueName(f.Key)+" is required", value)
+		}
+		fmt.Fprintf(&sb, "  value: {{ %s | quote }}\n", value)
+	}
+	sb.WriteString("{{- end }}\n")
+	return sb.String()
+}
+
+// helmValueName converts an environment variable to a lowerCamelCase value name, e.g. `DB_HOST`
+// to `dbHost`.
+func helmValueName(key string) string {
+	var sb strings.Builder
+	for i, word := range strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	}) {
+		if i > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
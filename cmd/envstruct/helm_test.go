@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHelm(t *testing.T) {
+	goldenTest(t, "helm.txt", "helm", "--type", "Config", configPackage)
+}
+
+func TestHelmFiles(t *testing.T) {
+	dir := t.TempDir()
+	values := filepath.Join(dir, "values.yaml")
+	template := filepath.Join(dir, "_env.tpl")
+	out, err := runCommand(t, "helm", "--type", "WorkerConfig", "--values", values, "--template", template,
+		"--define", "worker.env", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "" {
+		t.Errorf("got output %q, want it written to the files", out)
+	}
+	for _, file := range []string{values, template} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, "helm-worker"+filepath.Ext(file), string(data))
+	}
+}
+
+func TestHelmValueName(t *testing.T) {
+	tests := map[string]string{"DB_HOST": "dbHost", "LOG_LEVEL": "logLevel", "DEBUG": "debug", "api.key-id": "apiKeyId"}
+	for key, want := range tests {
+		if got := helmValueName(key); got != want {
+			t.Errorf("helmValueName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
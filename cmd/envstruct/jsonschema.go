@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// jsonSchema is a JSON Schema document for a config struct.
+type jsonSchema struct {
+	Schema     string         `json:"$schema"`
+	ID         string         `json:"$id,omitempty"`
+	Title      string         `json:"title"`
+	Type       string         `json:"type"`
+	Properties jsonProperties `json:"properties"`
+	Required   []string       `json:"required,omitempty"`
+	Additional bool           `json:"additionalProperties"`
+}
+
+// jsonProperty is the schema of a single variable.
+type jsonProperty struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Minimum     *int64 `json:"minimum,omitempty"`
+	Maximum     *int64 `json:"maximum,omitempty"`
+	MinLength   *int64 `json:"minLength,omitempty"`
+	MaxLength   *int64 `json:"maxLength,omitempty"`
+	WriteOnly   bool   `json:"writeOnly,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+// jsonProperties are the properties of a jsonSchema, encoded in field order.
+type jsonProperties struct {
+	keys   []string
+	values map[string]jsonProperty
+}
+
+func (p jsonProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p *jsonProperties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("properties must be an object, not %v", tok)
+	}
+	p.values = make(map[string]jsonProperty)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var prop jsonProperty
+		if err := dec.Decode(&prop); err != nil {
+			return fmt.Errorf("property %s: %w", key, err)
+		}
+		if _, seen := p.values[key]; !seen {
+			p.keys = append(p.keys, key)
+		}
+		p.values[key] = prop
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func newJSONSchemaCommand() *cobra.Command {
+	var sf structFlags
+	var id string
+	cmd := &cobra.Command{
+		Use:   "jsonschema --type Config [package]",
+		Short: "Generate a JSON Schema from a config struct",
+		Long: "JSONSchema writes a JSON Schema (draft 2020-12) for an object with a property for every " +
+			"variable of the struct, with its type, default, description and whether it is required. " +
+			"The validate rules len, min, max, oneof, url, email, hostname, uuid and port are " +
+			"translated to their JSON Schema keywords, variables tagged secret are writeOnly and " +
+			"fields with a deprecated tag are deprecated.\n\n" +
+			"The schema can be shipped in images to run the doctor command where the source is not " +
+			"available.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			doc := newJSONSchema(st)
+			doc.ID = id
+			return sf.write(cmd, func(w io.Writer) error {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				enc.SetEscapeHTML(false)
+				return enc.Encode(doc)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&id, "id", "", "$id of the schema")
+	return cmd
+}
+
+// newJSONSchema returns the schema for `st`.
+func newJSONSchema(st *schema.Struct) jsonSchema {
+	doc := jsonSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Title:      st.Name,
+		Type:       "object",
+		Properties: jsonProperties{values: make(map[string]jsonProperty)},
+	}
+	for _, f := range st.Fields {
+		prop := jsonProperty{
+			Type:        jsonType(f),
+			Description: f.Desc(),
+			WriteOnly:   f.Secret(),
+		}
+		if msg, ok := f.Deprecated(); ok {
+			prop.Deprecated = true
+			prop.Description = strings.TrimSpace("Deprecated: " + msg + "\n" + prop.Description)
+		}
+		if def, ok := f.Default(); ok {
+			prop.Default = jsonValue(prop.Type, def)
+		}
+		for _, rule := range f.Rules() {
+			applyRule(&prop, rule)
+		}
+		if _, seen := doc.Properties.values[f.Key]; !seen {
+			doc.Properties.keys = append(doc.Properties.keys, f.Key)
+		}
+		doc.Properties.values[f.Key] = prop
+		if f.Required() {
+			doc.Required = append(doc.Required, f.Key)
+		}
+	}
+	return doc
+}
+
+// jsonType returns the JSON type of the variable of `f`.
+func jsonType(f schema.Field) string {
+	if basic, ok := f.Type.Underlying().(*types.Basic); ok && !schema.IsTextUnmarshaler(f.Type) {
+		switch basic.Kind() {
+		case types.Bool:
+			return "boolean"
+		case types.Int:
+			if !f.Has("quantity") {
+				return "integer"
+			}
+		}
+	}
+	return "string"
+}
+
+// jsonValue converts `value` to the JSON type `typ`, falling back to the string if it does not
+// parse.
+func jsonValue(typ, value string) any {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// applyRule adds the keywords for the validate rule `rule` to `prop`. Rules without an equivalent
+// keyword are ignored.
+func applyRule(prop *jsonProperty, rule string) {
+	name, arg, _ := strings.Cut(rule, "=")
+	n, numErr := strconv.ParseInt(arg, 10, 64)
+	isString := prop.Type == "string"
+	switch name {
+	case "min":
+		if numErr != nil {
+			return
+		}
+		if isString {
+			prop.MinLength = &n
+		} else {
+			prop.Minimum = &n
+		}
+	case "max":
+		if numErr != nil {
+			return
+		}
+		if isString {
+			prop.MaxLength = &n
+		} else {
+			prop.Maximum = &n
+		}
+	case "len":
+		if numErr != nil {
+			return
+		}
+		if isString {
+			prop.MinLength, prop.MaxLength = &n, &n
+		} else {
+			prop.Minimum, prop.Maximum = &n, &n
+		}
+	case "oneof":
+		for _, value := range strings.Fields(arg) {
+			prop.Enum = append(prop.Enum, jsonValue(prop.Type, value))
+		}
+	case "url":
+		prop.Format = "uri"
+	case "email", "hostname", "uuid":
+		prop.Format = name
+	case "port":
+		if isString {
+			return
+		}
+		low, high := int64(1), int64(65535)
+		if arg == "unprivileged" {
+			low = 1024
+		}
+		prop.Minimum, prop.Maximum = &low, &high
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	goldenTest(t, "config.schema.json", "jsonschema", "--type", "Config", "--id", "https://example.com/config.schema.json", configPackage)
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	out, err := runCommand(t, "jsonschema", "--type", "Config", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonSchema
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatal(err)
+	}
+	// properties keep the order of the fields
+	want := []string{"DB_HOST", "DB_PORT", "DB_PASSWORD", "DEBUG", "LOG_LEVEL", "API_TOKEN", "LEGACY_HOST", "SERVER_NAME", "SERVER_TIMEOUT"}
+	if !slices.Equal(doc.Properties.keys, want) {
+		t.Errorf("got properties %q, want %q", doc.Properties.keys, want)
+	}
+	if !slices.Equal(doc.Required, []string{"DB_HOST", "DB_PASSWORD"}) {
+		t.Errorf("got required %q", doc.Required)
+	}
+}
+
+func TestApplyRule(t *testing.T) {
+	n := func(v int64) *int64 { return &v }
+	tests := []struct {
+		typ, rule string
+		want      jsonProperty
+	}{
+		{"integer", "min=1", jsonProperty{Minimum: n(1)}},
+		{"string", "min=1", jsonProperty{MinLength: n(1)}},
+		{"integer", "max=10", jsonProperty{Maximum: n(10)}},
+		{"string", "max=10", jsonProperty{MaxLength: n(10)}},
+		{"string", "len=36", jsonProperty{MinLength: n(36), MaxLength: n(36)}},
+		{"integer", "oneof=1 2", jsonProperty{Enum: []any{1, 2}}},
+		{"string", "url", jsonProperty{Format: "uri"}},
+		{"string", "uuid", jsonProperty{Format: "uuid"}},
+		{"integer", "port=unprivileged", jsonProperty{Minimum: n(1024), Maximum: n(65535)}},
+		{"string", "port", jsonProperty{}},
+		{"string", "min=1s", jsonProperty{}},
+		{"string", "cron", jsonProperty{}},
+	}
+	for _, tt := range tests {
+		prop := jsonProperty{Type: tt.typ}
+		applyRule(&prop, tt.rule)
+		tt.want.Type = tt.typ
+		got, _ := json.Marshal(prop)
+		want, _ := json.Marshal(tt.want)
+		if string(got) != string(want) {
+			t.Errorf("%s with %s: got %s, want %s", tt.typ, tt.rule, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// metadata is the metadata of a Kubernetes object.
+type metadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// configMap is a Kubernetes ConfigMap.
+type configMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   metadata          `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+func newConfigMapCommand() *cobra.Command {
+	var sf structFlags
+	var meta metadata
+	var fromEnv bool
+	cmd := &cobra.Command{
+		Use:   "configmap --type Config [package]",
+		Short: "Generate a Kubernetes ConfigMap from a config struct",
+		Long: "ConfigMap writes a ConfigMap manifest with a key for every variable of the struct that " +
+			"is not tagged secret, set to its default. With --from-env the current values of the " +
+			"environment are used instead where they are set.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if meta.Name == "" {
+				meta.Name = kubernetesName(st.Name)
+			}
+			cm := configMap{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Metadata:   meta,
+				Data:       make(map[string]string),
+			}
+			for _, f := range st.Fields {
+				if f.Secret() {
+					continue
+				}
+				value, _ := f.Default()
+				if env, ok := os.LookupEnv(f.Key); fromEnv && ok && env != "" {
+					value = env
+				}
+				cm.Data[f.Key] = value
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeYAML(w, cm)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&meta.Name, "name", "", "name of the ConfigMap (default the type name in kebab case)")
+	cmd.Flags().StringVar(&meta.Namespace, "namespace", "", "namespace of the ConfigMap")
+	cmd.Flags().BoolVar(&fromEnv, "from-env", false, "use the current values of the environment")
+	return cmd
+}
+
+// writeYAML writes `v` as a YAML document indented by two spaces, as is usual for manifests.
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// wordBoundaries match the starts of words in Go identifiers, e.g. `aC` and `PIC` in `APIConfig`.
+var (
+	wordBoundary       = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	initialismBoundary = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
+)
+
+// kubernetesName converts a Go type name to a Kubernetes object name, e.g. `APIConfig` to
+// `api-config`.
+func kubernetesName(name string) string {
+	name = wordBoundary.ReplaceAllString(name, "$1-$2")
+	name = initialismBoundary.ReplaceAllString(name, "$1-$2")
+	return strings.ToLower(name)
+}
+
+// envVar is an entry of the `env` list of a Kubernetes container.
+type envVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *envVarSource `yaml:"valueFrom,omitempty"`
+}
+
+// envVarSource is the source of the value of an envVar. Only one of the fields is set.
+type envVarSource struct {
+	ConfigMapKeyRef *keySelector `yaml:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *keySelector `yaml:"secretKeyRef,omitempty"`
+}
+
+// keySelector selects a key of a ConfigMap or Secret.
+type keySelector struct {
+	Name     string `yaml:"name"`
+	Key      string `yaml:"key"`
+	Optional bool   `yaml:"optional,omitempty"`
+}
+
+func newDeploymentEnvCommand() *cobra.Command {
+	var sf structFlags
+	var configMapName, secretName string
+	cmd := &cobra.Command{
+		Use:   "deployment-env --type Config [package]",
+		Short: "Generate the env block of a Kubernetes container from a config struct",
+		Long: "Deployment-env writes the env block of a container spec with an entry for every " +
+			"variable of the struct. Variables tagged secret are read with a secretKeyRef and all " +
+			"others with a configMapKeyRef, such as to the ConfigMap generated by the configmap " +
+			"command. References of variables that are not required are optional.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if configMapName == "" {
+				configMapName = kubernetesName(st.Name)
+			}
+			if secretName == "" {
+				secretName = kubernetesName(st.Name)
+			}
+			var env []envVar
+			for _, f := range st.Fields {
+				ref := &keySelector{Name: configMapName, Key: f.Key, Optional: !f.Required()}
+				source := envVarSource{ConfigMapKeyRef: ref}
+				if f.Secret() {
+					ref.Name = secretName
+					source = envVarSource{SecretKeyRef: ref}
+				}
+				env = append(env, envVar{Name: f.Key, ValueFrom: &source})
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeYAML(w, map[string][]envVar{"env": env})
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&configMapName, "configmap", "", "name of the ConfigMap (default the type name in kebab case)")
+	cmd.Flags().StringVar(&secretName, "secret", "", "name of the Secret (default the type name in kebab case)")
+	return cmd
+}
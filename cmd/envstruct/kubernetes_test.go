@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestConfigMap(t *testing.T) {
+	goldenTest(t, "configmap.yaml", "configmap", "--type", "Config", "--namespace", "shop", configPackage)
+}
+
+func TestConfigMapFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_PORT", "")
+	goldenTest(t, "configmap-from-env.yaml", "configmap", "--type", "Config", "--name", "api", "--from-env", configPackage)
+}
+
+func TestKubernetesName(t *testing.T) {
+	tests := map[string]string{
+		"Config":       "config",
+		"APIConfig":    "api-config",
+		"WorkerConfig": "worker-config",
+		"HTTPServer2":  "http-server2",
+	}
+	for name, want := range tests {
+		if got := kubernetesName(name); got != want {
+			t.Errorf("kubernetesName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDeploymentEnv(t *testing.T) {
+	goldenTest(t, "deployment-env.yaml", "deployment-env", "--type", "Config", "--secret", "api-secrets", configPackage)
+}
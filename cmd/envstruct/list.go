@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// variable is a row of the list output.
+type variable struct {
+	Key        string `json:"key"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	HasDefault bool   `json:"hasDefault"`
+	Required   bool   `json:"required"`
+	Secret     bool   `json:"secret"`
+	Field      string `json:"field"`
+}
+
+func newListCommand() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "list [packages]",
+		Short: "List the environment variables declared by structs in packages",
+		Long: "List loads the packages matching the patterns, ./... by default, and prints every " +
+			"environment variable bound by a struct with env tags, along with its type, default and " +
+			"whether it is required.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"./..."}
+			}
+			pkgs, err := schema.LoadPackages(".", args...)
+			if err != nil {
+				return err
+			}
+			var vars []variable
+			for _, st := range schema.All(pkgs) {
+				for _, f := range st.Fields {
+					def, hasDefault := f.Default()
+					vars = append(vars, variable{
+						Key:        f.Key,
+						Type:       f.TypeString(),
+						Default:    def,
+						HasDefault: hasDefault,
+						Required:   f.Required(),
+						Secret:     f.Secret(),
+						Field:      st.Package.PkgPath + "." + st.Name + "." + f.Path,
+					})
+				}
+			}
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(vars)
+			}
+			return writeList(cmd.OutOrStdout(), vars)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the variables as a JSON array")
+	return cmd
+}
+
+// writeList prints `vars` as a table. Defaults are quoted so blank defaults stand out.
+func writeList(w io.Writer, vars []variable) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTYPE\tDEFAULT\tREQUIRED\tFIELD")
+	for _, v := range vars {
+		def := "-"
+		if v.HasDefault {
+			def = strconv.Quote(v.Default)
+		}
+		key := v.Key
+		if v.Secret {
+			key += " (secret)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", key, v.Type, def, v.Required, v.Field)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	goldenTest(t, "list.txt", "list", configPackage)
+}
+
+func TestListJSON(t *testing.T) {
+	out, err := runCommand(t, "list", "--json", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vars []variable
+	if err := json.Unmarshal([]byte(out), &vars); err != nil {
+		t.Fatal(err)
+	}
+	// both structs of the package are listed
+	if len(vars) != 11 {
+		t.Fatalf("got %d variables, want 11", len(vars))
+	}
+	want := variable{
+		Key:        "DB_PORT",
+		Type:       "int",
+		Default:    "5432",
+		HasDefault: true,
+		Field:      "github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.Config.Port",
+	}
+	if vars[1] != want {
+		t.Errorf("got %+v, want %+v", vars[1], want)
+	}
+	if !vars[2].Secret || !vars[2].Required {
+		t.Errorf("got %+v, want DB_PASSWORD to be a required secret", vars[2])
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// structFlags are the flags of commands that work on a single config struct.
+type structFlags struct {
+	typeName string
+	output   string
+}
+
+// register adds the `--type` and `--output` flags to `cmd`.
+func (sf *structFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sf.typeName, "type", "", "name of the config struct type (required)")
+	cmd.Flags().StringVarP(&sf.output, "output", "o", "", "file to write to instead of stdout")
+	_ = cmd.MarkFlagRequired("type")
+}
+
+// load returns the struct named by `--type` from the package in `args`, . by default.
+func (sf *structFlags) load(args []string) (*schema.Struct, error) {
+	pattern := "."
+	if len(args) == 1 {
+		pattern = args[0]
+	}
+	pkgs, err := schema.LoadPackages(".", pattern)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Lookup(pkgs, sf.typeName)
+}
+
+// write calls `fn` with the file named by `--output`, or stdout of `cmd` if it is not set.
+func (sf *structFlags) write(cmd *cobra.Command, fn func(w io.Writer) error) error {
+	return writeOutput(cmd, sf.output, fn)
+}
+
+// writeOutput calls `fn` with the file `name`, or stdout of `cmd` if `name` is blank.
+func writeOutput(cmd *cobra.Command, name string, fn func(w io.Writer) error) error {
+	if name == "" {
+		return fn(cmd.OutOrStdout())
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := fn(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
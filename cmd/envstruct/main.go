@@ -0,0 +1,35 @@
+// Command envstruct inspects the config structs of Go packages from their source, e.g. to audit
+// which environment variables a service reads:
+//
+//	envstruct list ./...
+//
+// or to check the environment against a struct before deploying:
+//
+//	envstruct check --type Config ./cmd/api
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "envstruct: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand returns the envstruct command with all of its subcommands.
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "envstruct",
+		Short:         "Inspect config structs with env tags",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(newListCommand(), newCheckCommand(), newExampleCommand(), newDocsCommand(), newConfigMapCommand(), newDeploymentEnvCommand(), newHelmCommand(), newComposeCommand(), newTerraformCommand(), newJSONSchemaCommand(), newShellCommand(), newSystemdCommand(), newDockerfileCommand(), newECSCommand(), newNomadCommand(), newCloudRunCommand(), newEnvrcCommand(), newDiffCommand(), newDoctorCommand())
+	return root
+}
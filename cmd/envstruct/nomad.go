@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newNomadCommand() *cobra.Command {
+	var sf structFlags
+	var vaultPath string
+	var kvVersion int
+	cmd := &cobra.Command{
+		Use:   "nomad --type Config [package]",
+		Short: "Generate Nomad env and template blocks from a config struct",
+		Long: "Nomad writes the env block of a task with the defaults of the struct, and a template " +
+			"block that reads the variables tagged secret from the Vault secret at --vault-path " +
+			"into the environment of the task. Required variables without a default are listed in " +
+			"comments to be filled in.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			if vaultPath == "" {
+				vaultPath = "secret/data/" + kubernetesName(st.Name)
+			}
+			if kvVersion != 1 && kvVersion != 2 {
+				return fmt.Errorf("unsupported KV version %d, must be 1 or 2", kvVersion)
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeNomad(w, st, vaultPath, kvVersion)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&vaultPath, "vault-path", "", "Vault path of the secret (default secret/data/<type in kebab case>)")
+	cmd.Flags().IntVar(&kvVersion, "vault-kv-version", 2, "version of the Vault KV secrets engine")
+	return cmd
+}
+
+// writeNomad writes the env and template blocks for `st`.
+func writeNomad(w io.Writer, st *schema.Struct, vaultPath string, kvVersion int) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	var secrets []schema.Field
+	width := 0
+	for _, f := range st.Fields {
+		width = max(width, len(f.Key))
+	}
+	sb.WriteString("env {\n")
+	for _, f := range st.Fields {
+		if f.Secret() {
+			secrets = append(secrets, f)
+			continue
+		}
+		def, ok := f.Default()
+		switch {
+		case ok:
+			fmt.Fprintf(&sb, "  %-*s = %s\n", width, f.Key, hclString(def))
+		case f.Required():
+			fmt.Fprintf(&sb, "  # %-*s = \"\" # required\n", width, f.Key)
+		}
+	}
+	sb.WriteString("}\n")
+
+	if len(secrets) > 0 {
+		data := ".Data"
+		if kvVersion == 2 {
+			data = ".Data.data"
+		}
+		sb.WriteString("\ntemplate {\n")
+		sb.WriteString("  destination = \"secrets/env\"\n")
+		sb.WriteString("  env         = true\n")
+		sb.WriteString("  data        = <<EOH\n")
+		fmt.Fprintf(&sb, "{{ with secret %q }}\n", vaultPath)
+		for _, f := range secrets {
+			fmt.Fprintf(&sb, "%s={{ index %s %q | toJSON }}\n", f.Key, data, f.Key)
+		}
+		sb.WriteString("{{ end }}\n")
+		sb.WriteString("EOH\n")
+		sb.WriteString("}\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
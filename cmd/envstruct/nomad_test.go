@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNomad(t *testing.T) {
+	goldenTest(t, "nomad.hcl", "nomad", "--type", "Config", configPackage)
+}
+
+func TestNomadKVVersion1(t *testing.T) {
+	out, err := runCommand(t, "nomad", "--type", "Config", "--vault-path", "secret/api", "--vault-kv-version", "1", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`{{ with secret "secret/api" }}`,
+		`DB_PASSWORD={{ index .Data "DB_PASSWORD" | toJSON }}`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("got\n%s\nwant a line %s", out, line)
+		}
+	}
+}
+
+func TestNomadInvalidKVVersion(t *testing.T) {
+	_, err := runCommand(t, "nomad", "--type", "Config", "--vault-kv-version", "3", configPackage)
+	if err == nil || !strings.Contains(err.Error(), "unsupported KV version 3") {
+		t.Fatalf("got error %v, want an unsupported KV version", err)
+	}
+}
+
+func TestNomadNoSecrets(t *testing.T) {
+	out, err := runCommand(t, "nomad", "--type", "WorkerConfig", configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "template") {
+		t.Errorf("got\n%s\nwant no template block for a struct without secrets", out)
+	}
+}
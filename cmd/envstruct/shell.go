@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newShellCommand() *cobra.Command {
+	var sf structFlags
+	var prompt bool
+	cmd := &cobra.Command{
+		Use:   "shell --type Config [package]",
+		Short: "Generate a shell script that exports the variables of a config struct",
+		Long: "Shell writes a POSIX shell script to be sourced in a development shell. Variables with " +
+			"a default are exported with it, variables without one are commented out, and required " +
+			"variables and secrets get a placeholder to fill in.\n\n" +
+			"With --prompt the script asks for required variables and secrets that are not already " +
+			"set instead, without echoing secrets:\n\n" +
+			"\tenvstruct shell --type Config --prompt -o dev.sh && . ./dev.sh",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeShell(w, st, prompt)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().BoolVar(&prompt, "prompt", false, "prompt for required variables and secrets")
+	return cmd
+}
+
+// writeShell writes the export script for `st`.
+func writeShell(w io.Writer, st *schema.Struct, prompt bool) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	for _, f := range st.Fields {
+		def, hasDefault := f.Default()
+		ask := (f.Required() && def == "") || f.Secret()
+		switch {
+		case ask && prompt:
+			writePrompt(&sb, f)
+		case ask:
+			fmt.Fprintf(&sb, "export %s=%s\n", f.Key, shellQuote("<"+placeholder(f)+">"))
+		case hasDefault:
+			fmt.Fprintf(&sb, "export %s=%s\n", f.Key, shellQuote(def))
+		default:
+			fmt.Fprintf(&sb, "# export %s=''\n", f.Key)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writePrompt writes the statements that read the variable of `f` from the terminal if it is not
+// already set.
+func writePrompt(sb *strings.Builder, f schema.Field) {
+	fmt.Fprintf(sb, "if [ -z \"${%s:-}\" ]; then\n", f.Key)
+	fmt.Fprintf(sb, "  printf '%%s: ' %s >&2\n", shellQuote(f.Key+" ("+placeholder(f)+")"))
+	if f.Secret() {
+		sb.WriteString("  stty -echo 2>/dev/null\n")
+		fmt.Fprintf(sb, "  read -r %s\n", f.Key)
+		sb.WriteString("  stty echo 2>/dev/null\n")
+		sb.WriteString("  printf '\\n' >&2\n")
+	} else {
+		fmt.Fprintf(sb, "  read -r %s\n", f.Key)
+	}
+	sb.WriteString("fi\n")
+	fmt.Fprintf(sb, "export %s\n", f.Key)
+}
+
+// placeholder describes the value expected for `f`, e.g. `required string`.
+func placeholder(f schema.Field) string {
+	var words []string
+	if f.Required() {
+		words = append(words, "required")
+	}
+	if f.Secret() {
+		words = append(words, "secret")
+	}
+	words = append(words, f.TypeString())
+	if desc := f.Desc(); desc != "" && !strings.Contains(desc, "\n") {
+		words = append(words, "- "+desc)
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote single quotes `s` for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShell(t *testing.T) {
+	goldenTest(t, "shell.sh", "shell", "--type", "Config", configPackage)
+}
+
+func TestShellPrompt(t *testing.T) {
+	goldenTest(t, "shell-prompt.sh", "shell", "--type", "Config", "--prompt", configPackage)
+}
+
+// sourceShell generates a shell script with `args`, sources it with `stdin` and returns what
+// `print` prints afterwards.
+func sourceShell(t *testing.T, stdin, print string, args ...string) string {
+	t.Helper()
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell")
+	}
+	out, err := runCommand(t, append([]string{"shell", "--type", "Config"}, append(args, configPackage)...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(t.TempDir(), "dev.sh")
+	if err := os.WriteFile(script, []byte(out), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(sh, "-c", `. "$1" && `+print, "sh", script)
+	cmd.Stdin = strings.NewReader(stdin)
+	got, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(got)
+}
+
+func TestShellSourced(t *testing.T) {
+	got := sourceShell(t, "", `printf '%s|%s|%s' "$DB_PORT" "$SERVER_NAME" "$DB_HOST"`)
+	if want := `5432|api "$HOSTNAME"|<required string - Host of the database>`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShellPromptSourced(t *testing.T) {
+	// DB_HOST is already set, so only the secrets are read
+	t.Setenv("DB_HOST", "db.internal")
+	got := sourceShell(t, "hunter2\ntoken\n", `printf '%s|%s|%s' "$DB_HOST" "$DB_PASSWORD" "$API_TOKEN"`, "--prompt")
+	if want := "db.internal|hunter2|token"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":         `''`,
+		"plain":    `'plain'`,
+		"$HOME":    `'$HOME'`,
+		"it's":     `'it'\''s'`,
+		"a\nb":     "'a\nb'",
+		`"quoted"`: `'"quoted"'`,
+	}
+	for s, want := range tests {
+		if got := shellQuote(s); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newSystemdCommand() *cobra.Command {
+	var sf structFlags
+	var valueFiles []string
+	var set map[string]string
+	cmd := &cobra.Command{
+		Use:   "systemd --type Config [package]",
+		Short: "Generate a systemd EnvironmentFile from a config struct",
+		Long: "Systemd writes a file for the EnvironmentFile setting of a unit with a line for every " +
+			"variable of the struct, commented with its type and description. Values are taken from " +
+			"--set, then the dotenv files given with --values, then the default of the field. " +
+			"Variables without a value are commented out, so they stay unset.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			values := make(map[string]string)
+			if len(valueFiles) > 0 {
+				if values, err = readEnvFiles(valueFiles); err != nil {
+					return err
+				}
+			}
+			for key, value := range set {
+				values[key] = value
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeSystemd(w, st, values)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringArrayVar(&valueFiles, "values", nil, "dotenv file with values, may be repeated with later files taking precedence")
+	cmd.Flags().StringToStringVar(&set, "set", nil, "value of a variable as KEY=value, may be repeated")
+	return cmd
+}
+
+// writeSystemd writes the EnvironmentFile for `st` with the given values.
+func writeSystemd(w io.Writer, st *schema.Struct, values map[string]string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s.\n", st.Package.PkgPath, st.Name)
+	for _, f := range st.Fields {
+		sb.WriteString("\n")
+		fmt.Fprintf(&sb, "# %s\n", placeholder(f))
+		value, ok := values[f.Key]
+		if !ok || value == "" {
+			value, ok = f.Default()
+		}
+		if !ok || value == "" {
+			fmt.Fprintf(&sb, "#%s=\n", f.Key)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", f.Key, systemdQuote(value))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// systemdQuote quotes `value` for an EnvironmentFile. Values without special characters are
+// written as is, values without single quotes are single quoted, and others are double quoted
+// with backslash escapes.
+func systemdQuote(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n'\"\\$`#;") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + replacer.Replace(value) + `"`
+}
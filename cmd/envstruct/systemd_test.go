@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemd(t *testing.T) {
+	values := writeEnvFile(t, "DB_HOST=db.internal\nLOG_LEVEL=debug\n")
+	goldenTest(t, "systemd.env", "systemd", "--type", "Config", "--values", values,
+		"--set", "LOG_LEVEL=warn", "--set", "API_TOKEN=it's secret", configPackage)
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":         "plain",
+		"two words":     "'two words'",
+		"$HOME":         "'$HOME'",
+		"a#b":           "'a#b'",
+		"it's":          `"it's"`,
+		`it's "$x" \ y`: `"it's \"\$x\" \\ y"`,
+	}
+	for value, want := range tests {
+		if got := systemdQuote(value); got != want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestSystemdValueFiles(t *testing.T) {
+	base := writeEnvFile(t, "DB_HOST=db.internal\nDB_PORT=6543\n")
+	prod := writeEnvFile(t, "DB_HOST=db.prod\nDB_PORT=\n")
+	out, err := runCommand(t, "systemd", "--type", "Config", "--values", base, "--values", prod, configPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// later files take precedence, and empty values fall back to the default
+	for _, line := range []string{"\nDB_HOST=db.prod\n", "\nDB_PORT=5432\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("got\n%s\nwant a line %q", out, strings.TrimSpace(line))
+		}
+	}
+}
+
+func TestSystemdMissingValues(t *testing.T) {
+	if _, err := runCommand(t, "systemd", "--type", "Config", "--values", "missing.env", configPackage); err == nil {
+		t.Fatal("expected an error for a values file that does not exist")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func newTerraformCommand() *cobra.Command {
+	var sf structFlags
+	var local string
+	cmd := &cobra.Command{
+		Use:   "terraform --type Config [package]",
+		Short: "Generate Terraform variables and an environment map from a config struct",
+		Long: "Terraform writes a variable block for every variable of the struct and a local value " +
+			"that maps the environment variables to them, ready to be used as the environment of an " +
+			"ECS task, Lambda function or Cloud Run service:\n\n" +
+			"\tenvironment = local.environment\n\n" +
+			"Variables are typed bool or number for fields of those kinds and string otherwise. " +
+			"Required variables have no default, variables tagged secret are sensitive, and " +
+			"variables that are not set are left out of the map.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			st, err := sf.load(args)
+			if err != nil {
+				return err
+			}
+			return sf.write(cmd, func(w io.Writer) error {
+				return writeTerraform(w, st, local)
+			})
+		},
+	}
+	sf.register(cmd)
+	cmd.Flags().StringVar(&local, "local", "environment", "name of the local value holding the environment map")
+	return cmd
+}
+
+// writeTerraform writes the variables for `st` and the local value `local`.
+func writeTerraform(w io.Writer, st *schema.Struct, local string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by envstruct from %s.%s. DO NOT EDIT.\n", st.Package.PkgPath, st.Name)
+	for _, f := range st.Fields {
+		typ := terraformType(f)
+		fmt.Fprintf(&sb, "\nvariable %q {\n", terraformName(f.Key))
+		fmt.Fprintf(&sb, "  type        = %s\n", typ)
+		desc := f.Desc()
+		if desc == "" {
+			desc = "Value of " + f.Key + "."
+		}
+		fmt.Fprintf(&sb, "  description = %s\n", hclString(desc))
+		switch def, ok := f.Default(); {
+		case ok:
+			fmt.Fprintf(&sb, "  default     = %s\n", terraformValue(typ, def))
+		case !f.Required():
+			sb.WriteString("  default     = null\n")
+		}
+		if f.Secret() {
+			sb.WriteString("  sensitive   = true\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	fmt.Fprintf(&sb, "\nlocals {\n  %s = {\n    for key, value in {\n", local)
+	width := 0
+	for _, f := range st.Fields {
+		width = max(width, len(f.Key))
+	}
+	for _, f := range st.Fields {
+		value := "var." + terraformName(f.Key)
+		if terraformType(f) != "string" {
+			value = "tostring(" + value + ")"
+		}
+		fmt.Fprintf(&sb, "      %-*s = %s\n", width, f.Key, value)
+	}
+	sb.WriteString("    } : key => value if value != null\n  }\n}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// terraformName converts an environment variable to a Terraform variable name, e.g. `DB_HOST` to
+// `db_host`.
+func terraformName(key string) string {
+	return strings.ToLower(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// terraformType returns the type of the variable for `f`.
+func terraformType(f schema.Field) string {
+	if basic, ok := f.Type.Underlying().(*types.Basic); ok && !schema.IsTextUnmarshaler(f.Type) {
+		switch basic.Kind() {
+		case types.Bool:
+			return "bool"
+		case types.Int:
+			if !f.Has("quantity") {
+				return "number"
+			}
+		}
+	}
+	return "string"
+}
+
+// terraformValue returns `value` as a literal of type `typ`, falling back to a string if it does
+// not parse.
+func terraformValue(typ, value string) string {
+	switch typ {
+	case "bool":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case "number":
+		if n, err := strconv.Atoi(value); err == nil {
+			return strconv.Itoa(n)
+		}
+	}
+	return hclString(value)
+}
+
+// hclString returns `s` as a quoted HCL string, escaping template sequences.
+func hclString(s string) string {
+	s = strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < ' ' {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestTerraform(t *testing.T) {
+	goldenTest(t, "terraform.tf", "terraform", "--type", "Config", "--local", "api_environment", configPackage)
+}
+
+func TestHCLString(t *testing.T) {
+	tests := map[string]string{
+		"plain":          `"plain"`,
+		"${var.x}":       `"$${var.x}"`,
+		"%{if}":          `"%%{if}"`,
+		"say \"hi\"\\":   `"say \"hi\"\\"`,
+		"tab\tnewline\n": `"tab\tnewline\n"`,
+		"\x01":           `"\u0001"`,
+	}
+	for s, want := range tests {
+		if got := hclString(s); got != want {
+			t.Errorf("hclString(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
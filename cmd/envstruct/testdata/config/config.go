@@ -0,0 +1,23 @@
+// Package config declares the config structs the tests of the envstruct command run against.
+package config
+
+import "time"
+
+type Config struct {
+	Host     string `env:"DB_HOST,required" desc:"Host of the database"`
+	Port     int    `env:"DB_PORT" default:"5432" validate:"port"`
+	Password string `env:"DB_PASSWORD,secret,required"`
+	Debug    bool   `env:"DEBUG" default:"false"`
+	Level    string `env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn"`
+	APIToken string `env:"API_TOKEN,secret"`
+	Legacy   string `env:"LEGACY_HOST" deprecated:"use DB_HOST"`
+	Server   struct {
+		Name    string        `env:"SERVER_NAME" default:"api \"$HOSTNAME\""`
+		Timeout time.Duration `env:"SERVER_TIMEOUT" default:"30s"`
+	}
+}
+
+type WorkerConfig struct {
+	Queue       string `env:"QUEUE_NAME,required"`
+	Concurrency int    `env:"QUEUE_CONCURRENCY" default:"4"`
+}
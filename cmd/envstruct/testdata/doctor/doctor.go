@@ -0,0 +1,12 @@
+// Package doctor declares a config struct with a key read by two fields, which only the doctor
+// command reports.
+package doctor
+
+type Config struct {
+	Primary struct {
+		Host string `env:"DB_HOST,required"`
+	}
+	Replica struct {
+		Host string `env:"DB_HOST"`
+	}
+}
@@ -0,0 +1,7 @@
+{{/* Generated by envstruct from github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.WorkerConfig. DO NOT EDIT. */}}
+{{- define "worker.env" -}}
+- name: QUEUE_NAME
+  value: {{ required "config.queueName is required" .Values.config.queueName | quote }}
+- name: QUEUE_CONCURRENCY
+  value: {{ .Values.config.queueConcurrency | quote }}
+{{- end }}
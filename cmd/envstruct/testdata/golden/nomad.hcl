@@ -0,0 +1,20 @@
+# Generated by envstruct from github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.Config.
+env {
+  # DB_HOST        = "" # required
+  DB_PORT        = "5432"
+  DEBUG          = "false"
+  LOG_LEVEL      = "info"
+  SERVER_NAME    = "api \"$HOSTNAME\""
+  SERVER_TIMEOUT = "30s"
+}
+
+template {
+  destination = "secrets/env"
+  env         = true
+  data        = <<EOH
+{{ with secret "secret/data/config" }}
+DB_PASSWORD={{ index .Data.data "DB_PASSWORD" | toJSON }}
+API_TOKEN={{ index .Data.data "API_TOKEN" | toJSON }}
+{{ end }}
+EOH
+}
@@ -0,0 +1,28 @@
+# Generated by envstruct from github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.Config.
+if [ -z "${DB_HOST:-}" ]; then
+  printf '%s: ' 'DB_HOST (required string - Host of the database)' >&2
+  read -r DB_HOST
+fi
+export DB_HOST
+export DB_PORT='5432'
+if [ -z "${DB_PASSWORD:-}" ]; then
+  printf '%s: ' 'DB_PASSWORD (required secret string)' >&2
+  stty -echo 2>/dev/null
+  read -r DB_PASSWORD
+  stty echo 2>/dev/null
+  printf '\n' >&2
+fi
+export DB_PASSWORD
+export DEBUG='false'
+export LOG_LEVEL='info'
+if [ -z "${API_TOKEN:-}" ]; then
+  printf '%s: ' 'API_TOKEN (secret string)' >&2
+  stty -echo 2>/dev/null
+  read -r API_TOKEN
+  stty echo 2>/dev/null
+  printf '\n' >&2
+fi
+export API_TOKEN
+# export LEGACY_HOST=''
+export SERVER_NAME='api "$HOSTNAME"'
+export SERVER_TIMEOUT='30s'
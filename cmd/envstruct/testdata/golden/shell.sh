@@ -0,0 +1,10 @@
+# Generated by envstruct from github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.Config.
+export DB_HOST='<required string - Host of the database>'
+export DB_PORT='5432'
+export DB_PASSWORD='<required secret string>'
+export DEBUG='false'
+export LOG_LEVEL='info'
+export API_TOKEN='<secret string>'
+# export LEGACY_HOST=''
+export SERVER_NAME='api "$HOSTNAME"'
+export SERVER_TIMEOUT='30s'
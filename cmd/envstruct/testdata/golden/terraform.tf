@@ -0,0 +1,71 @@
+# Generated by envstruct from github.com/jha-captech/env-vars-to-struct/cmd/envstruct/testdata/config.Config. DO NOT EDIT.
+
+variable "db_host" {
+  type        = string
+  description = "Host of the database"
+}
+
+variable "db_port" {
+  type        = number
+  description = "Value of DB_PORT."
+  default     = 5432
+}
+
+variable "db_password" {
+  type        = string
+  description = "Value of DB_PASSWORD."
+  sensitive   = true
+}
+
+variable "debug" {
+  type        = bool
+  description = "Value of DEBUG."
+  default     = false
+}
+
+variable "log_level" {
+  type        = string
+  description = "Value of LOG_LEVEL."
+  default     = "info"
+}
+
+variable "api_token" {
+  type        = string
+  description = "Value of API_TOKEN."
+  default     = null
+  sensitive   = true
+}
+
+variable "legacy_host" {
+  type        = string
+  description = "Value of LEGACY_HOST."
+  default     = null
+}
+
+variable "server_name" {
+  type        = string
+  description = "Value of SERVER_NAME."
+  default     = "api \"$HOSTNAME\""
+}
+
+variable "server_timeout" {
+  type        = string
+  description = "Value of SERVER_TIMEOUT."
+  default     = "30s"
+}
+
+locals {
+  api_environment = {
+    for key, value in {
+      DB_HOST        = var.db_host
+      DB_PORT        = tostring(var.db_port)
+      DB_PASSWORD    = var.db_password
+      DEBUG          = tostring(var.debug)
+      LOG_LEVEL      = var.log_level
+      API_TOKEN      = var.api_token
+      LEGACY_HOST    = var.legacy_host
+      SERVER_NAME    = var.server_name
+      SERVER_TIMEOUT = var.server_timeout
+    } : key => value if value != null
+  }
+}
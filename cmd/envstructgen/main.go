@@ -0,0 +1,235 @@
+// Command envstructgen generates a function that loads a config struct from the environment with
+// direct field assignments instead of reflection, so unsupported field types are reported when
+// the code is generated and large configs load without the cost of reflection.
+//
+// Add a directive next to the struct and run `go generate`:
+//
+//	//go:generate go run github.com/jha-captech/env-vars-to-struct/cmd/envstructgen -type Config
+//
+// For a type `Config` this writes `config_env.go` with:
+//
+//	func LoadConfigFromEnv() (Config, error)
+//
+// The generated function follows the rules of Parse with the default environment source: every
+// field with an `env` tag is set from its environment variable, nested structs are filled in,
+// blank variables leave the field unset unless it has a `default` tag or the `required` option,
+// and all conversion and missing value errors are returned together. Fields of kinds Parse does
+// not support are skipped with a warning. Tag options that need the runtime, such as `exec` or
+// `source`, are rejected, and `validate` rules are not checked.
+//
+// Usage:
+//
+//	envstructgen -type Config [-output file] [-func name] [dir]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// runtimeOptions are the tag options that can not be generated.
+var runtimeOptions = []string{"exec", "op", "source", "parseurl", "quantity"}
+
+func main() {
+	typeName := flag.String("type", "", "name of the config struct type (required)")
+	output := flag.String("output", "", "output file name (default <type>_env.go in the package directory)")
+	funcName := flag.String("func", "", "name of the generated function (default Load<type>FromEnv)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: envstructgen -type Config [-output file] [-func name] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeName == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(*typeName)+"_env.go")
+	}
+	if *funcName == "" {
+		*funcName = "Load" + *typeName + "FromEnv"
+	}
+
+	if err := run(dir, *typeName, *funcName, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "envstructgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, typeName, funcName, output string) error {
+	pkgs, err := schema.LoadPackages(dir, ".")
+	if err != nil {
+		return err
+	}
+	st, err := schema.Lookup(pkgs, typeName)
+	if err != nil {
+		return err
+	}
+	src, err := generate(st, funcName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// generator builds the source of the generated file.
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string
+	body    bytes.Buffer
+}
+
+// generate returns the formatted source of the file for `st`.
+func generate(st *schema.Struct, funcName string) ([]byte, error) {
+	g := &generator{
+		pkg:     st.Package.Types,
+		imports: map[string]string{"errors": "errors"},
+	}
+	for _, f := range st.Fields {
+		for _, opt := range runtimeOptions {
+			if f.Has(opt) {
+				return nil, fmt.Errorf("field %s: the '%s' tag option is only supported by Parse", f.Path, opt)
+			}
+		}
+		if err := g.field(f); err != nil {
+			fmt.Fprintf(os.Stderr, "envstructgen: skipping field %s: %v\n", f.Path, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by envstructgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", st.Package.Name)
+	buf.WriteString("import (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if name := g.imports[path]; name != filepath.Base(path) {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+	}
+	buf.WriteString(")\n\n")
+	fmt.Fprintf(&buf, "// %s returns a %s with every field that has an `env` tag set from the\n", funcName, st.Name)
+	buf.WriteString("// environment. Blank variables leave the field unset or set it to its default. All errors\n")
+	buf.WriteString("// are returned together.\n")
+	fmt.Fprintf(&buf, "func %s() (%s, error) {\n", funcName, st.Name)
+	fmt.Fprintf(&buf, "\tvar cfg %s\n\tvar errs []error\n", st.Name)
+	buf.Write(g.body.Bytes())
+	buf.WriteString("\treturn cfg, errors.Join(errs...)\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// qualifier names packages in type expressions, importing them as needed.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+// field writes the code that sets `f`.
+func (g *generator) field(f schema.Field) error {
+	target := "cfg." + f.Path
+	// the type name used in error messages, matching the root package
+	typeName := f.TypeString()
+
+	if schema.IsTextUnmarshaler(f.Type) {
+		g.imports["fmt"] = "fmt"
+		g.open(f)
+		fmt.Fprintf(&g.body, "if err := %s.UnmarshalText([]byte(value)); err != nil {\n", target)
+		g.parseError(f.Key, typeName)
+		g.body.WriteString("}\n")
+		g.body.WriteString("}\n")
+		return nil
+	}
+
+	basic, ok := f.Type.Underlying().(*types.Basic)
+	if !ok {
+		return fmt.Errorf("type %s is not supported", typeName)
+	}
+	// named types need a conversion
+	conv := func(expr string) string {
+		if _, named := f.Type.(*types.Basic); named {
+			return expr
+		}
+		return types.TypeString(f.Type, g.qualifier) + "(" + expr + ")"
+	}
+
+	switch basic.Kind() {
+	case types.String:
+		g.open(f)
+		fmt.Fprintf(&g.body, "%s = %s\n", target, conv("value"))
+		g.body.WriteString("}\n")
+	case types.Int:
+		g.convert(f, target, "strconv.Atoi(value)", conv("converted"), "int64")
+	case types.Bool:
+		g.convert(f, target, "strconv.ParseBool(value)", conv("converted"), "bool")
+	default:
+		return fmt.Errorf("type %s is not supported", typeName)
+	}
+	return nil
+}
+
+// open writes the start of the block that runs with the value of `f` in `value`. The value falls
+// back to the `default` tag, and a missing value is an error for fields with the `required`
+// option. The block is closed with a single brace.
+func (g *generator) open(f schema.Field) {
+	g.imports["os"] = "os"
+	key := strconv.Quote(f.Key)
+	expr := fmt.Sprintf("os.Getenv(%s)", key)
+	if def, ok := f.Default(); ok {
+		g.imports["cmp"] = "cmp"
+		expr = fmt.Sprintf("cmp.Or(%s, %s)", expr, strconv.Quote(def))
+	}
+
+	if !f.Required() {
+		fmt.Fprintf(&g.body, "if value := %s; value != \"\" {\n", expr)
+		return
+	}
+	msg := fmt.Sprintf("environment variable '%s' is missing or blank", f.Key)
+	fmt.Fprintf(&g.body, "if value := %s; value == \"\" {\n", expr)
+	fmt.Fprintf(&g.body, "errs = append(errs, errors.New(%s))\n", strconv.Quote(msg))
+	g.body.WriteString("} else {\n")
+}
+
+// convert writes the code that sets `target` from a strconv call.
+func (g *generator) convert(f schema.Field, target, call, assign, typeName string) {
+	g.imports["fmt"] = "fmt"
+	g.imports["strconv"] = "strconv"
+	g.open(f)
+	fmt.Fprintf(&g.body, "if converted, err := %s; err != nil {\n", call)
+	g.parseError(f.Key, typeName)
+	fmt.Fprintf(&g.body, "} else {\n%s = %s\n}\n", target, assign)
+	g.body.WriteString("}\n")
+}
+
+// parseError writes the statement that records a conversion error, worded like the root package.
+func (g *generator) parseError(key, typeName string) {
+	msg := fmt.Sprintf("error parsing environment variable '%s' to type '%s': %%w", key, typeName)
+	fmt.Fprintf(&g.body, "errs = append(errs, fmt.Errorf(%s, err))\n", strconv.Quote(msg))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+func TestGenerate(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config_env.go")
+	if err := run("testdata/config", "Config", "LoadConfigFromEnv", output); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), output, src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+
+	code := string(src)
+	for _, want := range []string{
+		"// Code generated by envstructgen. DO NOT EDIT.",
+		"package config",
+		"func LoadConfigFromEnv() (Config, error) {",
+		`if value := os.Getenv("DB_HOST"); value == "" {`,
+		`errs = append(errs, errors.New("environment variable 'DB_HOST' is missing or blank"))`,
+		`if value := cmp.Or(os.Getenv("DB_PORT"), "5432"); value != "" {`,
+		"if converted, err := strconv.Atoi(value); err != nil {",
+		`"error parsing environment variable 'DB_PORT' to type 'int64': %w"`,
+		"if converted, err := strconv.ParseBool(value); err != nil {",
+		`cfg.Level = Level(value)`,
+		"if err := cfg.Started.UnmarshalText([]byte(value)); err != nil {",
+		`if value := os.Getenv("SERVER_NAME"); value != "" {`,
+		"cfg.Server.Name = value",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q:\n%s", want, code)
+		}
+	}
+	// time.Duration is an int64 the generator does not support, so the field is skipped
+	if strings.Contains(code, "TIMEOUT") {
+		t.Errorf("got code for the unsupported Timeout field:\n%s", code)
+	}
+}
+
+func TestGenerateRuntimeOption(t *testing.T) {
+	pkgs, err := schema.LoadPackages("testdata/config", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := schema.Lookup(pkgs, "RuntimeConfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate(st, "LoadRuntimeConfigFromEnv"); err == nil || !strings.Contains(err.Error(), "exec") {
+		t.Errorf("got error %v, want an error for the exec option", err)
+	}
+}
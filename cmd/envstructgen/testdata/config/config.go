@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type Level string
+
+type Config struct {
+	Host    string        `env:"DB_HOST,required"`
+	Port    int           `env:"DB_PORT" default:"5432"`
+	Debug   bool          `env:"DEBUG"`
+	Level   Level         `env:"LOG_LEVEL" default:"info"`
+	Started time.Time     `env:"STARTED"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Server  struct {
+		Name string `env:"SERVER_NAME"`
+	}
+}
+
+type RuntimeConfig struct {
+	Token string `env:"TOKEN,exec=print-token"`
+}
@@ -0,0 +1,16 @@
+// Command envtagcheck checks the `env` struct tags of config structs. It can be run on its own or
+// with `go vet`:
+//
+//	envtagcheck ./...
+//	go vet -vettool=$(which envtagcheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/jha-captech/env-vars-to-struct/envtagcheck"
+)
+
+func main() {
+	singlechecker.Main(envtagcheck.Analyzer)
+}
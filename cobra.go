@@ -0,0 +1,106 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// BindCobraFlags registers a persistent flag on `cmd` for every field of `obj` that has an `env`
+// tag and parses `obj` before the command runs. Values are resolved from the flags set on the
+// command line first, then from the sources given with WithSources, or the environment if none
+// are given, and otherwise keep the field's current value, which is also shown as the flag's
+// default.
+//
+// Flag names and usage text are derived the same way as Flags, and fields that share a key share
+// one flag. Parsing happens in a PersistentPreRunE hook that runs any hook already set on `cmd`
+// afterwards. Note that cobra only runs the closest persistent hook, so subcommands that set their
+// own PersistentPreRunE need `cobra.EnableTraverseRunHooks` for `obj` to be parsed.
+func BindCobraFlags(cmd *cobra.Command, obj any, opts ...Option) {
+	flags := cmd.PersistentFlags()
+	keys := make(map[string]string)
+	walkFields(structValue(obj), "", func(f boundField) {
+		if _, ok := keys[f.tag.key]; ok {
+			return
+		}
+		current, ok := formatValue(f.value)
+		if !ok {
+			return
+		}
+		name := FlagName(f.tag.key)
+		usage := strings.TrimSpace(fmt.Sprintf("%s (env %s)", f.field.Tag.Get("desc"), f.tag.key))
+		flag := flags.VarPF(&pflagValue{
+			value:    current,
+			typeName: f.value.Kind().String(),
+		}, name, "", usage)
+		if f.value.Kind() == reflect.Bool {
+			flag.NoOptDefVal = "true"
+		}
+		keys[f.tag.key] = name
+	})
+
+	source := &funcSource{
+		name: "flags",
+		lookup: func(_ context.Context, key string) (string, bool, error) {
+			name, ok := keys[key]
+			if !ok {
+				return "", false, nil
+			}
+			flag := flags.Lookup(name)
+			if flag == nil || !flag.Changed {
+				return "", false, nil
+			}
+			return flag.Value.String(), true, nil
+		},
+	}
+
+	o := newOptions(opts)
+	sources := append([]Source{source}, o.sources...)
+	if len(o.sources) == 0 {
+		sources = append(sources, Env())
+	}
+	opts = append(opts, func(o *options) {
+		o.sources = sources
+	})
+
+	next := cmd.PersistentPreRunE
+	nextNoErr := cmd.PersistentPreRun
+	cmd.PersistentPreRun = nil
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := Parse(obj, opts...); err != nil {
+			return fmt.Errorf("in BindCobraFlags: %w", err)
+		}
+		switch {
+		case next != nil:
+			return next(cmd, args)
+		case nextNoErr != nil:
+			nextNoErr(cmd, args)
+		}
+		return nil
+	}
+}
+
+// pflagValue is the `pflag.Value` counterpart of flagValue.
+type pflagValue struct {
+	value    string
+	typeName string
+}
+
+var _ pflag.Value = (*pflagValue)(nil)
+
+func (v *pflagValue) String() string {
+	return v.value
+}
+
+func (v *pflagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *pflagValue) Type() string {
+	return v.typeName
+}
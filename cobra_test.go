@@ -0,0 +1,80 @@
+package envstruct_test
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestBindCobraFlags(t *testing.T) {
+	cfg := flagConfig{Port: 5432}
+	var ran []string
+	cmd := &cobra.Command{
+		Use: "serve",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			ran = append(ran, "pre-run")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ran = append(ran, "run")
+			return nil
+		},
+	}
+	env := sources.Static(map[string]string{"DB_HOST": "env.internal", "LOG_LEVEL": "info"})
+	envstruct.BindCobraFlags(cmd, &cfg, envstruct.WithSources(env))
+
+	if port := cmd.PersistentFlags().Lookup("db-port"); port == nil || port.DefValue != "5432" {
+		t.Fatalf("got flag %+v, want the current value as default", port)
+	}
+	cmd.SetArgs([]string{"--db-host", "flag.internal", "--debug"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if want := (flagConfig{Host: "flag.internal", Port: 5432, Debug: true, Level: "info"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if len(ran) != 2 || ran[0] != "pre-run" || ran[1] != "run" {
+		t.Errorf("got %q, want the existing hook to run after parsing", ran)
+	}
+}
+
+func TestBindCobraFlagsError(t *testing.T) {
+	var cfg flagConfig
+	cmd := &cobra.Command{
+		Use: "serve",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t.Error("the command ran")
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	envstruct.BindCobraFlags(cmd, &cfg, envstruct.WithSources(sources.Static(nil)))
+	cmd.SetArgs([]string{"--db-port", "abc"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid flag value")
+	}
+}
+
+func TestBindCobraFlagsSharedKey(t *testing.T) {
+	var cfg struct {
+		Host    string `env:"DB_HOST"`
+		Replica struct {
+			Host string `env:"DB_HOST"`
+		}
+	}
+	cmd := &cobra.Command{
+		Use:  "serve",
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	envstruct.BindCobraFlags(cmd, &cfg, envstruct.WithSources(sources.Static(nil)))
+	cmd.SetArgs([]string{"--db-host", "flag.internal"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "flag.internal" || cfg.Replica.Host != "flag.internal" {
+		t.Errorf("got %+v, want the flag to set both fields", cfg)
+	}
+}
@@ -0,0 +1,183 @@
+package envstruct
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	// listSeparator separates the items of slice fields and the pairs of map fields.
+	listSeparator = ","
+	// pairSeparator separates the key of a map field pair from its value.
+	pairSeparator = ":"
+)
+
+// itemType reports whether `t` can be the item type of a slice or map field: a type setField
+// supports other than slices, maps and secrets.
+func itemType(t reflect.Type) bool {
+	if isSecretType(t) {
+		return false
+	}
+	if t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// setListField sets the slice `field` from the comma separated items of `value`, e.g. `a,b,c` or
+// `1s,5s,30s`, each converted like a field of the item type. Spaces around items are trimmed. It
+// reports false for slices whose items are not supported.
+func setListField(field reflect.Value, key, value string, errIfMissing bool) (bool, error) {
+	t := field.Type()
+	if !itemType(t.Elem()) {
+		return false, nil
+	}
+	if value == "" {
+		if errIfMissing {
+			return false, &envVarError{reason: ReasonMissing, key: key, typeName: t.String()}
+		}
+		field.SetZero()
+		return true, nil
+	}
+
+	items := strings.Split(value, listSeparator)
+	list := reflect.MakeSlice(t, len(items), len(items))
+	for i, item := range items {
+		if _, err := setField(list.Index(i), key, strings.TrimSpace(item), false); err != nil {
+			return false, itemError(t, key, i, err)
+		}
+	}
+	field.Set(list)
+	return true, nil
+}
+
+// setMapField sets the map `field` from the comma separated `key:value` pairs of `value`, e.g.
+// `orders:5s,users:1s`, with the values converted like a field of the value type. The value is
+// everything after the first colon, and spaces around keys and values are trimmed. It reports
+// false for maps whose keys are not strings or whose values are not supported.
+func setMapField(field reflect.Value, key, value string, errIfMissing bool) (bool, error) {
+	t := field.Type()
+	if t.Key().Kind() != reflect.String || !itemType(t.Elem()) {
+		return false, nil
+	}
+	if value == "" {
+		if errIfMissing {
+			return false, &envVarError{reason: ReasonMissing, key: key, typeName: t.String()}
+		}
+		field.SetZero()
+		return true, nil
+	}
+
+	pairs := strings.Split(value, listSeparator)
+	m := reflect.MakeMapWithSize(t, len(pairs))
+	for i, pair := range pairs {
+		name, item, ok := strings.Cut(pair, pairSeparator)
+		if !ok {
+			return false, itemError(t, key, i, fmt.Errorf("'%s' is not a key%svalue pair", pair, pairSeparator))
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if _, err := setField(elem, key, strings.TrimSpace(item), false); err != nil {
+			return false, itemError(t, key, i, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(name)).Convert(t.Key()), elem)
+	}
+	field.Set(m)
+	return true, nil
+}
+
+// itemError returns the parse error of the item at index `i` of the value of `key`.
+func itemError(t reflect.Type, key string, i int, err error) error {
+	var envErr *envVarError
+	if errors.As(err, &envErr) && envErr.err != nil {
+		err = envErr.err
+	}
+	return &envVarError{reason: ReasonParse, key: key, typeName: t.String(), err: fmt.Errorf("item %d: %w", i+1, err)}
+}
+
+// formatList joins the items of the slice `val` the way setListField splits them. It returns an
+// error if the items would not be split back into the same items.
+func formatList(val reflect.Value) (string, error) {
+	items := make([]string, val.Len())
+	for i := range items {
+		item, err := formatItem(val.Index(i))
+		if err != nil {
+			return "", err
+		}
+		items[i] = item
+	}
+	if len(items) == 1 && items[0] == "" {
+		return "", errors.New("a single blank item can not be told apart from an empty list")
+	}
+	return strings.Join(items, listSeparator), nil
+}
+
+// formatMap joins the pairs of the map `val` sorted by key, the way setMapField splits them. It
+// returns an error if the pairs would not be split back into the same pairs.
+func formatMap(val reflect.Value) (string, error) {
+	pairs := make([]string, 0, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		if iter.Key().Kind() != reflect.String {
+			return "", fmt.Errorf("type %s is not supported", val.Type())
+		}
+		name := iter.Key().String()
+		if strings.Contains(name, pairSeparator) {
+			return "", fmt.Errorf("key '%s' contains '%s'", name, pairSeparator)
+		}
+		if err := checkItem(name); err != nil {
+			return "", err
+		}
+		item, err := formatItem(iter.Value())
+		if err != nil {
+			return "", err
+		}
+		pairs = append(pairs, name+pairSeparator+item)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, listSeparator), nil
+}
+
+// formatItem formats an item of a slice or map field.
+func formatItem(val reflect.Value) (string, error) {
+	if !itemType(val.Type()) {
+		return "", fmt.Errorf("items of type %s are not supported", val.Type())
+	}
+	item, ok := formatValue(val)
+	if !ok {
+		return "", fmt.Errorf("items of type %s are not supported", val.Type())
+	}
+	return item, checkItem(item)
+}
+
+// checkItem returns an error if `item` would not be split back into the same item.
+func checkItem(item string) error {
+	if strings.Contains(item, listSeparator) {
+		return fmt.Errorf("item '%s' contains '%s'", item, listSeparator)
+	}
+	if strings.TrimSpace(item) != item {
+		return fmt.Errorf("item '%s' has leading or trailing spaces", item)
+	}
+	return nil
+}
+
+// formatError explains why formatValue could not format `val`.
+func formatError(val reflect.Value) error {
+	var err error
+	switch val.Kind() {
+	case reflect.Slice:
+		_, err = formatList(val)
+	case reflect.Map:
+		_, err = formatMap(val)
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("type %s is not supported", val.Type())
+}
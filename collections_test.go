@@ -0,0 +1,141 @@
+package envstruct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type collectionsConfig struct {
+	Timeout  time.Duration            `env:"TIMEOUT"`
+	Hosts    []string                 `env:"HOSTS"`
+	Ports    []int                    `env:"PORTS"`
+	Flags    []bool                   `env:"FLAGS"`
+	Backoff  []time.Duration          `env:"BACKOFF"`
+	Limits   map[string]int           `env:"LIMITS"`
+	Timeouts map[string]time.Duration `env:"TIMEOUTS"`
+}
+
+func TestParseCollections(t *testing.T) {
+	var cfg struct {
+		Timeout  time.Duration            `env:"TIMEOUT"`
+		Hosts    []string                 `env:"HOSTS"`
+		Ports    []int                    `env:"PORTS"`
+		Flags    []bool                   `env:"FLAGS"`
+		Backoff  []time.Duration          `env:"BACKOFF"`
+		Limits   map[string]int           `env:"LIMITS"`
+		Timeouts map[string]time.Duration `env:"TIMEOUTS"`
+		Labels   map[string]string        `env:"LABELS"`
+	}
+	source := sources.Static(map[string]string{
+		"TIMEOUT":  "1m30s",
+		"HOSTS":    "a.internal, b.internal,c.internal",
+		"PORTS":    "80,443",
+		"FLAGS":    "true,false",
+		"BACKOFF":  "100ms,1s,10s",
+		"LIMITS":   "orders:5, users:10",
+		"TIMEOUTS": "orders:5s",
+		"LABELS":   "url:https://example.com",
+	})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 90*time.Second {
+		t.Errorf("got Timeout %v", cfg.Timeout)
+	}
+	if want := []string{"a.internal", "b.internal", "c.internal"}; !reflect.DeepEqual(cfg.Hosts, want) {
+		t.Errorf("got Hosts %q, want %q", cfg.Hosts, want)
+	}
+	if want := []int{80, 443}; !reflect.DeepEqual(cfg.Ports, want) {
+		t.Errorf("got Ports %v, want %v", cfg.Ports, want)
+	}
+	if want := []bool{true, false}; !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("got Flags %v, want %v", cfg.Flags, want)
+	}
+	if want := []time.Duration{100 * time.Millisecond, time.Second, 10 * time.Second}; !reflect.DeepEqual(cfg.Backoff, want) {
+		t.Errorf("got Backoff %v, want %v", cfg.Backoff, want)
+	}
+	if want := map[string]int{"orders": 5, "users": 10}; !reflect.DeepEqual(cfg.Limits, want) {
+		t.Errorf("got Limits %v, want %v", cfg.Limits, want)
+	}
+	if want := map[string]time.Duration{"orders": 5 * time.Second}; !reflect.DeepEqual(cfg.Timeouts, want) {
+		t.Errorf("got Timeouts %v, want %v", cfg.Timeouts, want)
+	}
+	if want := map[string]string{"url": "https://example.com"}; !reflect.DeepEqual(cfg.Labels, want) {
+		t.Errorf("got Labels %v, want %v", cfg.Labels, want)
+	}
+}
+
+func TestParseCollectionsErrors(t *testing.T) {
+	tests := map[string]struct {
+		key, value string
+		wantErr    string
+	}{
+		"duration":      {key: "TIMEOUT", value: "90", wantErr: "missing unit"},
+		"slice item":    {key: "PORTS", value: "80,http", wantErr: "item 2"},
+		"map pair":      {key: "LIMITS", value: "orders:5,users", wantErr: "'users' is not a key:value pair"},
+		"map value":     {key: "LIMITS", value: "orders:five", wantErr: "item 1"},
+		"duration item": {key: "BACKOFF", value: "1s,soon", wantErr: "item 2"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg collectionsConfig
+			source := sources.Static(map[string]string{tt.key: tt.value})
+			_, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+			var fieldErr *envstruct.FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonParse {
+				t.Fatalf("got error %v, want a parse error", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCollectionsRequired(t *testing.T) {
+	var cfg struct {
+		Hosts []string `env:"HOSTS,required"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(nil)))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonMissing {
+		t.Fatalf("got error %v, want a missing error", err)
+	}
+}
+
+func TestCompileCollections(t *testing.T) {
+	type config struct {
+		Timeout time.Duration     `env:"TIMEOUT" default:"30s"`
+		Hosts   []string          `env:"HOSTS" default:"a,b"`
+		Labels  map[string]string `env:"LABELS"`
+	}
+	binder, err := envstruct.Compile[config]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := binder.Bind(func(string) (string, bool) { return "", false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 30*time.Second || !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) {
+		t.Errorf("got %+v", cfg)
+	}
+
+	type invalid struct {
+		Nested  [][]string                 `env:"NESTED"`
+		ByInt   map[int]string             `env:"BY_INT"`
+		Timeout time.Duration              `env:"TIMEOUT" default:"soon"`
+		Secrets []envstruct.Secret[string] `env:"SECRETS"`
+	}
+	_, err = envstruct.Compile[invalid]()
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("got error %v, want 4 tag errors", err)
+	}
+}
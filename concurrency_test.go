@@ -0,0 +1,131 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// barrierSource is a source whose lookups block until `n` of them are in flight at once, so it
+// only answers when fields are resolved concurrently. Lookups of `failKey` fail.
+type barrierSource struct {
+	n       int
+	values  map[string]string
+	failKey string
+
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	release  chan struct{}
+}
+
+func newBarrierSource(n int, values map[string]string) *barrierSource {
+	return &barrierSource{n: n, values: values, release: make(chan struct{})}
+}
+
+func (s *barrierSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.maxSeen = max(s.maxSeen, s.inFlight)
+	if s.inFlight == s.n {
+		close(s.release)
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+	if key == s.failKey {
+		return "", false, errors.New("lookup failed")
+	}
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+type concurrentConfig struct {
+	A string `env:"A"`
+	B int    `env:"B"`
+	C bool   `env:"C"`
+	D string `env:"D" default:"d"`
+}
+
+func TestWithConcurrency(t *testing.T) {
+	source := newBarrierSource(4, map[string]string{"A": "a", "B": "2", "C": "true"})
+	var cfg concurrentConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithConcurrency(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (concurrentConfig{A: "a", B: 2, C: true, D: "d"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if res.Sources["D"] != "default" {
+		t.Errorf("got sources %v, want D from its default", res.Sources)
+	}
+}
+
+// waitFor fails the test if `cond` does not become true within a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	// the barrier is never reached, so at most 2 lookups are in flight until the context ends
+	source := newBarrierSource(3, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		var cfg concurrentConfig
+		_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithConcurrency(2),
+			envstruct.WithContext(ctx))
+		done <- err
+	}()
+	waitFor(t, "2 lookups in flight", func() bool {
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.inFlight == 2
+	})
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("expected the canceled lookups to fail")
+	}
+	if source.maxSeen != 2 {
+		t.Errorf("got %d lookups in flight, want at most 2", source.maxSeen)
+	}
+}
+
+func TestWithConcurrencyErrorOrder(t *testing.T) {
+	source := newBarrierSource(4, map[string]string{"A": "a", "B": "two", "C": "true"})
+	source.failKey = "A"
+	var cfg concurrentConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithConcurrency(4))
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got error %v, want 2 field errors", err)
+	}
+	// errors are reported in field order, however the lookups finished
+	if errs[0].Field != "A" || errs[0].Reason != envstruct.ReasonSource {
+		t.Errorf("got first error %v, want the source error of A", errs[0])
+	}
+	if errs[1].Field != "B" || errs[1].Reason != envstruct.ReasonParse {
+		t.Errorf("got second error %v, want the parse error of B", errs[1])
+	}
+}
@@ -0,0 +1,90 @@
+package envstruct
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ConfigInfo is a snapshot of the metadata of a parsed config that is safe to expose to operators:
+// the key, source and redacted value of every field. It implements `expvar.Var` and
+// `http.Handler`, so the effective configuration of a running process can be inspected with
+// either:
+//
+//	info := NewConfigInfo(&cfg, res)
+//	expvar.Publish("config", info)
+//	http.Handle("/debug/config", info)
+type ConfigInfo struct {
+	// LoadedAt is when the snapshot was taken.
+	LoadedAt time.Time `json:"loaded_at"`
+	// Fields are the fields with an `env` tag in field order.
+	Fields []ConfigInfoField `json:"fields"`
+	// Degraded is copied from `Result.Degraded`.
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// ConfigInfoField is a field of a ConfigInfo.
+type ConfigInfoField struct {
+	Field string `json:"field"`
+	Key   string `json:"key"`
+	// Value is the current value, redacted like Dump redacts it.
+	Value string `json:"value"`
+	// Source is the source the value was resolved from, or blank if the field was not set.
+	Source string `json:"source,omitempty"`
+	// Origin is copied from `Resolution.Origin`.
+	Origin string `json:"origin,omitempty"`
+}
+
+// NewConfigInfo takes a snapshot of the fields of `cfg` as parsed by the Parse call that returned
+// `res`. Secrets are redacted like Dump does, or left out if WithOmitSecrets is used.
+func NewConfigInfo(cfg any, res Result, opts ...Option) *ConfigInfo {
+	o := newOptions(opts)
+	resolutions := make(map[string]Resolution, len(res.Fields))
+	for _, resolution := range res.Fields {
+		resolutions[resolution.Field] = resolution
+	}
+
+	info := &ConfigInfo{LoadedAt: time.Now(), Fields: []ConfigInfoField{}, Degraded: res.Degraded}
+	walkFields(structValue(cfg), "", func(f boundField) {
+		if f.tag.has("secret") && o.omitSecrets {
+			return
+		}
+		field := ConfigInfoField{
+			Field:  f.path,
+			Key:    f.tag.key,
+			Value:  displayValue(f),
+			Source: res.Sources[f.path],
+		}
+		if resolution, ok := resolutions[f.path]; ok {
+			field.Origin = resolution.Origin
+		}
+		info.Fields = append(info.Fields, field)
+	})
+	return info
+}
+
+// String renders the snapshot as JSON, as `expvar.Var` requires.
+func (info *ConfigInfo) String() string {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// ServeHTTP responds with the snapshot as indented JSON.
+func (info *ConfigInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,114 @@
+package envstruct_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type infoConfig struct {
+	Host     string `env:"DB_HOST"`
+	Password string `env:"DB_PASSWORD,secret"`
+	Port     int    `env:"DB_PORT" default:"5432"`
+	Level    string `env:"LOG_LEVEL"`
+}
+
+func newConfigInfo(t *testing.T, opts ...envstruct.Option) *envstruct.ConfigInfo {
+	t.Helper()
+	dotenv := writeFile(t, ".env", "DB_HOST=db.internal\nDB_PASSWORD=hunter2\n")
+	var cfg infoConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.DotEnv(dotenv)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := envstruct.NewConfigInfo(&cfg, res, opts...)
+	for i, f := range info.Fields {
+		if f.Origin == dotenv {
+			info.Fields[i].Origin = ".env"
+		}
+		if f.Source == "dotenv:"+dotenv {
+			info.Fields[i].Source = "dotenv"
+		}
+	}
+	return info
+}
+
+func TestConfigInfo(t *testing.T) {
+	info := newConfigInfo(t)
+	want := []envstruct.ConfigInfoField{
+		{Field: "Host", Key: "DB_HOST", Value: "db.internal", Source: "dotenv", Origin: ".env"},
+		{Field: "Password", Key: "DB_PASSWORD", Value: "[REDACTED]", Source: "dotenv", Origin: ".env"},
+		{Field: "Port", Key: "DB_PORT", Value: "5432", Source: "default"},
+		{Field: "Level", Key: "LOG_LEVEL", Value: ""},
+	}
+	if !reflect.DeepEqual(info.Fields, want) {
+		t.Errorf("got %+v, want %+v", info.Fields, want)
+	}
+	if info.LoadedAt.IsZero() {
+		t.Error("want the time of the snapshot")
+	}
+
+	omitted := newConfigInfo(t, envstruct.WithOmitSecrets())
+	for _, f := range omitted.Fields {
+		if f.Key == "DB_PASSWORD" {
+			t.Error("want secrets to be left out with WithOmitSecrets")
+		}
+	}
+}
+
+func TestConfigInfoExpvar(t *testing.T) {
+	var v expvar.Var = newConfigInfo(t)
+	var decoded envstruct.ConfigInfo
+	if err := json.Unmarshal([]byte(v.String()), &decoded); err != nil {
+		t.Fatalf("got invalid JSON %s: %v", v.String(), err)
+	}
+	if len(decoded.Fields) != 4 || strings.Contains(v.String(), "hunter2") {
+		t.Errorf("got %s", v.String())
+	}
+}
+
+func TestConfigInfoHandler(t *testing.T) {
+	info := newConfigInfo(t)
+	rec := httptest.NewRecorder()
+	info.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("got status %d and content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("want the response not to be cached")
+	}
+	var decoded envstruct.ConfigInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Fields, info.Fields) {
+		t.Errorf("got %+v, want %+v", decoded.Fields, info.Fields)
+	}
+
+	rec = httptest.NewRecorder()
+	info.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/config", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("got status %d for a POST, want 405", rec.Code)
+	}
+}
+
+func TestConfigInfoDegraded(t *testing.T) {
+	static := sources.Static(nil).Named("remote")
+	breaker := envstruct.WithSourceBreaker(static, envstruct.BreakerConfig{FailureThreshold: 1})
+	static.Fail(unavailableError{})
+	var cfg infoConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(breaker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := envstruct.NewConfigInfo(&cfg, res); !reflect.DeepEqual(info.Degraded, []string{"remote"}) {
+		t.Errorf("got degraded %q, want the open breaker", info.Degraded)
+	}
+}
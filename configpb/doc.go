@@ -0,0 +1,5 @@
+// Package configpb contains the ConfigService protocol used by the GRPC source. Implement
+// `ConfigServiceServer` to serve configuration values from an internal config plane.
+package configpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative config.proto
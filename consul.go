@@ -0,0 +1,154 @@
+package envstruct
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// remoteTimeout bounds every request made by the remote sources.
+const remoteTimeout = 10 * time.Second
+
+// Consul returns a source that reads every key under a prefix from the Consul KV store. The
+// source is configured with a URL of the form:
+//
+//	consul://localhost:8500/my-service/config?dc=dc1&token=...&scheme=https
+//
+// The `token` parameter defaults to the `CONSUL_HTTP_TOKEN` environment variable. Keys are made
+// relative to the prefix and flattened like config files, so `my-service/config/db/host` is
+// resolved as `DB_HOST`. Put Env() before the source in the chain to let environment variables
+// override values from Consul.
+//
+// All keys are fetched with a single recursive request the first time a key is looked up.
+func Consul(rawURL string) Source {
+	return newMapSource(stripQuery(rawURL), func(ctx context.Context) (map[string]string, error) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "consul" {
+			return nil, fmt.Errorf("expected a consul:// URL, got '%s'", rawURL)
+		}
+		query := u.Query()
+		prefix := strings.Trim(u.Path, "/")
+
+		apiURL := url.URL{
+			Scheme:   "http",
+			Host:     u.Host,
+			Path:     "/v1/kv/" + prefix,
+			RawQuery: url.Values{"recurse": {"true"}}.Encode(),
+		}
+		if scheme := query.Get("scheme"); scheme != "" {
+			apiURL.Scheme = scheme
+		}
+		if dc := query.Get("dc"); dc != "" {
+			apiURL.RawQuery += "&dc=" + url.QueryEscape(dc)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		token := query.Get("token")
+		if token == "" {
+			token = os.Getenv("CONSUL_HTTP_TOKEN")
+		}
+		if token != "" {
+			req.Header.Set("X-Consul-Token", token)
+		}
+
+		var pairs []struct {
+			Key   string
+			Value string
+		}
+		found, err := doJSONRequest(newHTTPClient(nil), req, &pairs)
+		if err != nil || !found {
+			return map[string]string{}, err
+		}
+
+		values := make(map[string]string)
+		for _, pair := range pairs {
+			// a recursive get matches any key starting with the prefix, so skip keys that only
+			// share a partial path segment with it
+			key, ok := strings.CutPrefix(pair.Key, prefix)
+			if !ok || (prefix != "" && !strings.HasPrefix(key, "/")) || strings.HasSuffix(key, "/") {
+				continue
+			}
+			value, err := base64.StdEncoding.DecodeString(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding value of '%s': %w", pair.Key, err)
+			}
+			values[flattenPath(key, "/", defaultKeySeparator)] = string(value)
+		}
+		return values, nil
+	})
+}
+
+// flattenPath converts a `/` separated path such as a KV key into an environment variable style
+// key, the same way nested keys in config files are flattened.
+func flattenPath(path, pathSep, sep string) string {
+	var key string
+	for _, part := range strings.Split(path, pathSep) {
+		if part != "" {
+			key = joinFlatKey(key, part, sep)
+		}
+	}
+	return key
+}
+
+// stripQuery removes the query string from `rawURL` so credentials passed as parameters are not
+// included in source names.
+func stripQuery(rawURL string) string {
+	base, _, _ := strings.Cut(rawURL, "?")
+	return base
+}
+
+// newHTTPClient returns the client used by the remote sources. If `tlsConfig` is nil the default
+// TLS configuration is used.
+func newHTTPClient(tlsConfig *tls.Config) *http.Client {
+	client := &http.Client{Timeout: remoteTimeout}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+	return client
+}
+
+// statusError is returned by the HTTP based sources for unexpected response statuses.
+type statusError struct {
+	status string
+	code   int
+	url    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status '%s' from %s", e.status, e.url)
+}
+
+// doJSONRequest sends `req` and decodes the JSON response body into `out`. It reports false
+// without an error if the server responds with 404 Not Found.
+func doJSONRequest(client *http.Client, req *http.Request, out any) (bool, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, &statusError{status: resp.Status, code: resp.StatusCode, url: req.URL.Redacted()}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("error decoding response from %s: %w", req.URL.Redacted(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package envstruct_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type remoteConfig struct {
+	Host  string `env:"DB_HOST"`
+	Port  int    `env:"DB_PORT"`
+	Level string `env:"LOG_LEVEL"`
+}
+
+func TestConsul(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/my-service/config" || r.URL.Query().Get("recurse") != "true" ||
+			r.URL.Query().Get("dc") != "dc1" || r.Header.Get("X-Consul-Token") != "secret-token" {
+			t.Errorf("unexpected request %s with token %q", r.URL, r.Header.Get("X-Consul-Token"))
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		pair := func(key, value string) map[string]string {
+			return map[string]string{"Key": key, "Value": base64.StdEncoding.EncodeToString([]byte(value))}
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]string{
+			pair("my-service/config/", ""),
+			pair("my-service/config/db/host", "db.internal"),
+			pair("my-service/config/db/port", "5432"),
+			pair("my-service/config-other/log-level", "debug"),
+		})
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	source := envstruct.Consul("consul://" + host + "/my-service/config?dc=dc1&token=secret-token")
+	var cfg remoteConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if name := res.Sources["Host"]; strings.Contains(name, "secret-token") {
+		t.Errorf("got source name %q, want the token left out", name)
+	}
+}
+
+func TestConsulErrors(t *testing.T) {
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// a prefix without keys is not an error
+	var cfg remoteConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Consul("consul://"+host+"/empty"))); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusInternalServerError
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Consul("consul://"+host+"/failing")))
+	if err == nil || !envstruct.IsRetryable(err) {
+		t.Errorf("got error %v, want a retryable error", err)
+	}
+
+	_, err = envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Consul("http://"+host+"/wrong-scheme")))
+	if err == nil {
+		t.Error("expected an error for a URL that is not a consul:// URL")
+	}
+}
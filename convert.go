@@ -0,0 +1,39 @@
+package envstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConvertValue converts `value` the way Parse converts it for a field of type `T` whose `env` tag
+// has the options `options`, such as `parseurl` or `quantity=milli`, without a struct or source.
+// It is the entry point for fuzz targets of the value parsers:
+//
+//	func FuzzQuantity(f *testing.F) {
+//		for _, seed := range []string{"500m", "2Gi", "1e3", "-0.5", ""} {
+//			f.Add(seed)
+//		}
+//		f.Fuzz(func(t *testing.T, value string) {
+//			_, _ = ConvertValue[int64](value, "quantity")
+//		})
+//	}
+//
+// A blank value is an error, as it is for required fields.
+func ConvertValue[T any](value string, options ...string) (converted T, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in ConvertValue: %w", err)
+		}
+	}()
+	tag := parseEnvTag(strings.Join(append([]string{"VALUE"}, options...), ","))
+	field := reflect.ValueOf(&converted).Elem()
+	ok, err := setTaggedField(field, tag, tag.key, value, true)
+	if err != nil {
+		return converted, err
+	}
+	if !ok {
+		return converted, fmt.Errorf("type %s is not supported", field.Type())
+	}
+	return converted, nil
+}
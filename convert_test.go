@@ -0,0 +1,179 @@
+package envstruct_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestConvertValue(t *testing.T) {
+	if got, err := envstruct.ConvertValue[int]("42"); err != nil || got != 42 {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if got, err := envstruct.ConvertValue[time.Duration]("1m30s"); err != nil || got != 90*time.Second {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if got, err := envstruct.ConvertValue[[]int]("1,2,3"); err != nil || !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if got, err := envstruct.ConvertValue[int64]("2Ki", "quantity"); err != nil || got != 2048 {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if got, err := envstruct.ConvertValue[int]("1.5", "quantity=milli"); err != nil || got != 1500 {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := envstruct.ConvertValue[int](""); err == nil {
+		t.Error("expected an error for a blank value")
+	}
+	if _, err := envstruct.ConvertValue[float64]("1.5"); err == nil {
+		t.Error("expected an error for an unsupported type")
+	}
+}
+
+// holder binds a single value of type `T`, so a converted value can be marshaled again.
+type holder[T any] struct {
+	V T `env:"V"`
+}
+
+// quantityHolder binds a single value of type `T` with the `quantity` option.
+type quantityHolder[T any] struct {
+	V T `env:"V,quantity"`
+}
+
+// milliHolder binds a single value of type `T` with the `quantity=milli` option.
+type milliHolder[T any] struct {
+	V T `env:"V,quantity=milli"`
+}
+
+// roundTrip checks that a value converted from `value` is marshaled to a string that converts to
+// the same value.
+func roundTrip[T any](t *testing.T, value string) {
+	t.Helper()
+	roundTripWith(t, value, func(v T) any { return &holder[T]{V: v} })
+}
+
+// roundTripWith works like roundTrip for values bound with the options of the `env` tag of the
+// struct `wrap` returns.
+func roundTripWith[T any](t *testing.T, value string, wrap func(T) any, options ...string) {
+	t.Helper()
+	converted, err := envstruct.ConvertValue[T](value, options...)
+	if err != nil {
+		return
+	}
+	values, err := envstruct.Marshal(wrap(converted))
+	if err != nil {
+		// values such as list items with commas can be parsed but not marshaled
+		return
+	}
+	again, err := envstruct.ConvertValue[T](values["V"], options...)
+	if err != nil {
+		t.Fatalf("%q was converted to %v and marshaled to %q, which does not convert: %v", value, converted, values["V"], err)
+	}
+	if !reflect.DeepEqual(converted, again) {
+		t.Fatalf("%q was converted to %v, but its marshaled form %q to %v", value, converted, values["V"], again)
+	}
+}
+
+func FuzzInt(f *testing.F) {
+	for _, seed := range []string{"0", "-1", "+42", "9223372036854775807", "9223372036854775808", "0x10", " 1"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTrip[int](t, value)
+	})
+}
+
+func FuzzBool(f *testing.F) {
+	for _, seed := range []string{"true", "false", "1", "0", "T", "yes", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTrip[bool](t, value)
+	})
+}
+
+func FuzzDuration(f *testing.F) {
+	for _, seed := range []string{"1m30s", "-1.5h", "0", "1us", "1µs", "9223372036854775807ns", "2562048h", ".5s", "1"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTrip[time.Duration](t, value)
+	})
+}
+
+func FuzzSlice(f *testing.F) {
+	for _, seed := range []string{"a,b,c", " a , b ", "a,,b", ",", "1,2,3", "1s,5m"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTrip[[]string](t, value)
+		roundTrip[[]int](t, value)
+		roundTrip[[]bool](t, value)
+		roundTrip[[]time.Duration](t, value)
+	})
+}
+
+func FuzzMap(f *testing.F) {
+	for _, seed := range []string{"a:1,b:2", "a:1,a:2", " a : 1 ", ":1", "a", "url:https://example.com", "a:1s,b:1m"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTrip[map[string]string](t, value)
+		roundTrip[map[string]int](t, value)
+		roundTrip[map[string]time.Duration](t, value)
+	})
+}
+
+func FuzzQuantity(f *testing.F) {
+	for _, seed := range []string{"500m", "2Gi", "1e3", "-0.5", "1.5k", "1e99999999", "0.001m", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		roundTripWith(t, value, func(v int64) any { return &quantityHolder[int64]{V: v} }, "quantity")
+		roundTripWith(t, value, func(v uint32) any { return &quantityHolder[uint32]{V: v} }, "quantity")
+		roundTripWith(t, value, func(v int) any { return &milliHolder[int]{V: v} }, "quantity=milli")
+		_, _ = envstruct.ConvertValue[float64](value, "quantity")
+	})
+}
+
+func FuzzParseURL(f *testing.F) {
+	for _, seed := range []string{"postgres://app:pw@db:5432/orders?sslmode=disable", "redis://:pw@[::1]:6379/0", "http://h:99999", "%zz", "db"} {
+		f.Add(seed)
+	}
+	type endpoint struct {
+		Scheme   string
+		Host     string
+		Port     int
+		Username string
+		Password string
+		Path     string
+		Query    map[string]string
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		_, _ = envstruct.ConvertValue[endpoint](value, "parseurl")
+	})
+}
+
+// FuzzParse parses a struct with a field of every supported kind from a single value, so values
+// that reach every parser through Parse are covered too.
+func FuzzParse(f *testing.F) {
+	f.Add("1")
+	f.Add("a:1,b:2")
+	f.Add("1s,2s")
+	f.Fuzz(func(t *testing.T, value string) {
+		var cfg struct {
+			String   string            `env:"V"`
+			Int      int               `env:"V"`
+			Bool     bool              `env:"V"`
+			Bytes    []byte            `env:"V"`
+			Duration time.Duration     `env:"V"`
+			Ints     []int             `env:"V"`
+			Labels   map[string]string `env:"V"`
+			Quantity int64             `env:"V,quantity"`
+		}
+		_, _ = envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(map[string]string{"V": value})))
+	})
+}
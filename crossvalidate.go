@@ -0,0 +1,118 @@
+package envstruct
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithCrossValidation adds `fn` to the checks run once every field is bound and validated, for
+// invariants that involve more than one field. `fn` is called with the value passed to Parse. It
+// can be given more than once, and the checks run in order.
+//
+// Errors returned by `fn` are added to the parse errors with the reason ReasonValidation. Use
+// FieldErrorf to report the field an error is about, and `errors.Join` to report several:
+//
+//	WithCrossValidation(func(cfg any) error {
+//		c := cfg.(*Config)
+//		if c.ReadTimeout >= c.IdleTimeout {
+//			return FieldErrorf("ReadTimeout", "must be less than IdleTimeout")
+//		}
+//		return nil
+//	})
+//
+// Cross validation is skipped if any field failed to parse or validate, since invariants can not
+// be checked reliably on a partially bound value.
+func WithCrossValidation(fn func(cfg any) error) Option {
+	return func(o *options) {
+		o.crossValidations = append(o.crossValidations, fn)
+	}
+}
+
+// WithTypedCrossValidation is like WithCrossValidation but calls `fn` with the typed value, which
+// must be the `*T` passed to Parse:
+//
+//	WithTypedCrossValidation(func(c *Config) error {
+//		...
+//	})
+func WithTypedCrossValidation[T any](fn func(cfg *T) error) Option {
+	return WithCrossValidation(func(cfg any) error {
+		typed, ok := cfg.(*T)
+		if !ok {
+			var want *T
+			return fmt.Errorf("cross validation expects %T, got %T", want, cfg)
+		}
+		return fn(typed)
+	})
+}
+
+// FieldErrorf returns an error about the field at the dotted path `field`, e.g.
+// `Server.ReadTimeout`, for use in cross validation. The message should describe the requirement,
+// e.g. `must be less than IdleTimeout`, and is formatted like `fmt.Errorf`.
+func FieldErrorf(field, format string, args ...any) error {
+	return &FieldError{
+		Field:  field,
+		Reason: ReasonValidation,
+		Err:    fmt.Errorf(format, args...),
+	}
+}
+
+// runCrossValidations runs the checks given with WithCrossValidation on `obj` and adds their
+// errors to the parse errors.
+func (p *parser) runCrossValidations(obj any) {
+	if len(p.errs) > 0 || len(p.opts.crossValidations) == 0 {
+		return
+	}
+
+	keys := make(map[string]string)
+	walkFields(structValue(obj), "", func(f boundField) {
+		keys[f.path] = f.tag.key
+	})
+
+	for _, fn := range p.opts.crossValidations {
+		for _, err := range flattenErrors(fn(obj)) {
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				// copy so errors kept by `fn` are not changed
+				copied := *fieldErr
+				fieldErr = &copied
+			} else {
+				fieldErr = &FieldError{Err: err}
+			}
+			if fieldErr.Reason == "" {
+				fieldErr.Reason = ReasonValidation
+			}
+			if fieldErr.Key == "" {
+				fieldErr.Key = keys[fieldErr.Field]
+			}
+			if fieldErr.Hint == "" && fieldErr.Field != "" {
+				name := fieldErr.Key
+				if name == "" {
+					name = fieldErr.Field
+				}
+				fieldErr.Hint = fmt.Sprintf("'%s' %v", name, fieldErr.Err)
+				fieldErr.Err = &validationError{key: name, rule: "cross-field", err: fieldErr.Err}
+			}
+			p.errs = append(p.errs, fieldErr)
+		}
+	}
+}
+
+// flattenErrors splits errors joined with `errors.Join`, or any other error wrapping several
+// errors, into a list. A nil error gives an empty list.
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*FieldError); ok {
+		return []error{err}
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, wrapped := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(wrapped)...)
+	}
+	return errs
+}
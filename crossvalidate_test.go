@@ -0,0 +1,80 @@
+package envstruct_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type timeoutConfig struct {
+	Server struct {
+		ReadTimeout time.Duration `env:"READ_TIMEOUT"`
+		IdleTimeout time.Duration `env:"IDLE_TIMEOUT"`
+	}
+	Workers int `env:"WORKERS"`
+}
+
+func checkTimeouts(c *timeoutConfig) error {
+	var errs []error
+	if c.Server.ReadTimeout >= c.Server.IdleTimeout {
+		errs = append(errs, envstruct.FieldErrorf("Server.ReadTimeout", "must be less than IdleTimeout"))
+	}
+	if c.Workers > 4 && c.Server.ReadTimeout < time.Second {
+		errs = append(errs, errors.New("many workers need a read timeout of at least 1s"))
+	}
+	return errors.Join(errs...)
+}
+
+func TestCrossValidation(t *testing.T) {
+	source := sources.Static(map[string]string{"READ_TIMEOUT": "100ms", "IDLE_TIMEOUT": "50ms", "WORKERS": "8"})
+	var cfg timeoutConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithTypedCrossValidation(checkTimeouts))
+
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got error %v, want 2 errors", err)
+	}
+	if errs[0].Field != "Server.ReadTimeout" || errs[0].Key != "READ_TIMEOUT" || errs[0].Reason != envstruct.ReasonValidation {
+		t.Errorf("got %+v, want a validation error for READ_TIMEOUT", errs[0])
+	}
+	if errs[0].Hint != "'READ_TIMEOUT' must be less than IdleTimeout" {
+		t.Errorf("got hint %q", errs[0].Hint)
+	}
+	if errs[1].Field != "" || errs[1].Reason != envstruct.ReasonValidation {
+		t.Errorf("got %+v, want a validation error for no field", errs[1])
+	}
+
+	source.Set("IDLE_TIMEOUT", "5m")
+	source.Set("READ_TIMEOUT", "5s")
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithTypedCrossValidation(checkTimeouts)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrossValidationSkipped(t *testing.T) {
+	source := sources.Static(map[string]string{"READ_TIMEOUT": "soon"})
+	called := false
+	var cfg timeoutConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithCrossValidation(func(any) error {
+		called = true
+		return nil
+	}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if called {
+		t.Error("cross validation ran after a field failed to parse")
+	}
+}
+
+func TestTypedCrossValidationWrongType(t *testing.T) {
+	var cfg timeoutConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(nil)),
+		envstruct.WithTypedCrossValidation(func(*struct{}) error { return nil }))
+	if err == nil {
+		t.Fatal("expected an error for a check of another type")
+	}
+}
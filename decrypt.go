@@ -0,0 +1,271 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Decryptor decrypts the values of fields tagged with the `encrypted` option, e.g.
+// `env:"DB_PASSWORD,encrypted"`, so encrypted values can be passed through ordinary environment
+// variables and config files and are only decrypted at parse time.
+type Decryptor interface {
+	Decrypt(ctx context.Context, ciphertext string) (string, error)
+}
+
+// DecryptorFunc adapts a function to a Decryptor.
+type DecryptorFunc func(ctx context.Context, ciphertext string) (string, error)
+
+func (fn DecryptorFunc) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	return fn(ctx, ciphertext)
+}
+
+// WithDecryptor sets the Decryptor used for fields tagged with the `encrypted` option. Parse fails
+// for such fields if no decryptor is set. Only values resolved from sources are decrypted, not
+// `default` tags.
+func WithDecryptor(decryptor Decryptor) Option {
+	return func(o *options) {
+		o.decryptor = decryptor
+	}
+}
+
+// decryptError is returned by resolveValue when a value could not be decrypted.
+type decryptError struct {
+	err error
+}
+
+func (e *decryptError) Error() string {
+	return "error decrypting value: " + e.err.Error()
+}
+
+func (e *decryptError) Unwrap() error {
+	return e.err
+}
+
+// resolveValue resolves the value of `f` like resolve, and decrypts it if `f` is tagged with the
+// `encrypted` option.
+func (o options) resolveValue(f boundField) (resolved, error) {
+	res, err := o.resolve(f)
+	if err != nil {
+		return res, err
+	}
+	return o.decryptValue(f, res)
+}
+
+// decryptValue decrypts the value resolved for `f` if `f` is tagged with the `encrypted` option.
+func (o options) decryptValue(f boundField, res resolved) (resolved, error) {
+	if res.value == "" || !f.tag.has("encrypted") {
+		return res, nil
+	}
+	if o.decryptor == nil {
+		return resolved{source: res.source}, &decryptError{err: errors.New("no decryptor is configured, use WithDecryptor")}
+	}
+	plaintext, err := o.decryptor.Decrypt(o.context(), res.value)
+	if err != nil {
+		return resolved{source: res.source}, &decryptError{err: err}
+	}
+	res.value = plaintext
+	return res, nil
+}
+
+// AgeDecryptor returns a Decryptor for values encrypted with age to one of the identities in the
+// file `identityFile`, such as a file created with `age-keygen`. Values can be ASCII armored, as
+// created with `age --armor`, or the base64 encoding of the binary format.
+//
+// The identity file is read when the first value is decrypted.
+func AgeDecryptor(identityFile string) Decryptor {
+	var (
+		once       sync.Once
+		identities []age.Identity
+		loadErr    error
+	)
+	return DecryptorFunc(func(_ context.Context, ciphertext string) (string, error) {
+		once.Do(func() {
+			file, err := os.Open(identityFile)
+			if err != nil {
+				loadErr = err
+				return
+			}
+			defer file.Close()
+			identities, err = age.ParseIdentities(file)
+			if err != nil {
+				loadErr = fmt.Errorf("error reading identities from '%s': %w", identityFile, err)
+			}
+		})
+		if loadErr != nil {
+			return "", loadErr
+		}
+
+		var encrypted io.Reader
+		if strings.HasPrefix(strings.TrimSpace(ciphertext), armor.Header) {
+			encrypted = armor.NewReader(strings.NewReader(strings.TrimSpace(ciphertext)))
+		} else {
+			data, err := base64.StdEncoding.DecodeString(ciphertext)
+			if err != nil {
+				return "", fmt.Errorf("error decoding age ciphertext: %w", err)
+			}
+			encrypted = bytes.NewReader(data)
+		}
+		decrypted, err := age.Decrypt(encrypted, identities...)
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := io.ReadAll(decrypted)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	})
+}
+
+// AWSKMSDecryptorConfig configures the AWSKMSDecryptor.
+type AWSKMSDecryptorConfig struct {
+	// KeyID restricts decryption to a key, which is required for asymmetric keys. If blank, KMS
+	// finds the key from the ciphertext.
+	KeyID string
+	// EncryptionContext must match the encryption context the values were encrypted with.
+	EncryptionContext map[string]string
+	// Client is used to call KMS. If nil, a client is created from the default AWS configuration.
+	Client AWSKMSClient
+}
+
+// AWSKMSClient is the part of the KMS client used by the AWSKMSDecryptor.
+type AWSKMSClient interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// AWSKMSDecryptor returns a Decryptor for values encrypted with AWS KMS, given as the base64
+// encoding of the ciphertext blob, such as the output of `aws kms encrypt`.
+func AWSKMSDecryptor(cfg AWSKMSDecryptorConfig) Decryptor {
+	var (
+		mu     sync.Mutex
+		client = cfg.Client
+	)
+	return DecryptorFunc(func(ctx context.Context, ciphertext string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		blob, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			return "", fmt.Errorf("error decoding KMS ciphertext: %w", err)
+		}
+
+		mu.Lock()
+		if client == nil {
+			awsCfg, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				mu.Unlock()
+				return "", fmt.Errorf("error loading AWS config: %w", err)
+			}
+			client = kms.NewFromConfig(awsCfg)
+		}
+		kmsClient := client
+		mu.Unlock()
+
+		input := &kms.DecryptInput{
+			CiphertextBlob:    blob,
+			EncryptionContext: cfg.EncryptionContext,
+		}
+		if cfg.KeyID != "" {
+			input.KeyId = aws.String(cfg.KeyID)
+		}
+		out, err := kmsClient.Decrypt(ctx, input)
+		if err != nil {
+			return "", err
+		}
+		return string(out.Plaintext), nil
+	})
+}
+
+// gcpKMSEndpoint is the default Cloud KMS API endpoint.
+const gcpKMSEndpoint = "https://cloudkms.googleapis.com"
+
+// GCPKMSDecryptorConfig configures the GCPKMSDecryptor.
+type GCPKMSDecryptorConfig struct {
+	// KeyName is the resource name of the key, e.g.
+	// `projects/x/locations/global/keyRings/my-ring/cryptoKeys/my-key`.
+	KeyName string
+	// TokenSource authenticates requests. If nil, Application Default Credentials are used.
+	TokenSource oauth2.TokenSource
+	// Endpoint overrides the Cloud KMS API endpoint.
+	Endpoint string
+}
+
+// GCPKMSDecryptor returns a Decryptor for values encrypted with a symmetric Google Cloud KMS key,
+// given as the base64 encoding of the ciphertext, such as the output of
+// `gcloud kms encrypt ... | base64`. It uses the Cloud KMS REST API.
+func GCPKMSDecryptor(cfg GCPKMSDecryptorConfig) Decryptor {
+	var (
+		mu     sync.Mutex
+		client *http.Client
+	)
+	return DecryptorFunc(func(ctx context.Context, ciphertext string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		mu.Lock()
+		if client == nil {
+			tokenSource := cfg.TokenSource
+			if tokenSource == nil {
+				var err error
+				tokenSource, err = google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloudkms")
+				if err != nil {
+					mu.Unlock()
+					return "", fmt.Errorf("error finding default credentials: %w", err)
+				}
+			}
+			// the token source must outlive the context of this call
+			client = oauth2.NewClient(context.Background(), tokenSource)
+			client.Timeout = remoteTimeout
+		}
+		httpClient := client
+		mu.Unlock()
+
+		body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+		if err != nil {
+			return "", err
+		}
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = gcpKMSEndpoint
+		}
+		url := strings.TrimSuffix(endpoint, "/") + "/v1/" + cfg.KeyName + ":decrypt"
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		var resp struct {
+			Plaintext string `json:"plaintext"`
+		}
+		found, err := doJSONRequest(httpClient, req, &resp)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("key '%s' not found", cfg.KeyName)
+		}
+		plaintext, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+		if err != nil {
+			return "", fmt.Errorf("error decoding plaintext: %w", err)
+		}
+		return string(plaintext), nil
+	})
+}
@@ -0,0 +1,230 @@
+package envstruct_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"golang.org/x/oauth2"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type encryptedConfig struct {
+	Password string `env:"DB_PASSWORD,encrypted"`
+	Token    string `env:"API_TOKEN,encrypted" default:"enc:not-decrypted"`
+	Host     string `env:"DB_HOST"`
+}
+
+// prefixDecryptor decrypts values by removing the prefix `enc:`.
+var prefixDecryptor = envstruct.DecryptorFunc(func(_ context.Context, ciphertext string) (string, error) {
+	plaintext, ok := strings.CutPrefix(ciphertext, "enc:")
+	if !ok {
+		return "", errors.New("not encrypted")
+	}
+	return plaintext, nil
+})
+
+func TestDecryptor(t *testing.T) {
+	source := sources.Static(map[string]string{"DB_PASSWORD": "enc:hunter2", "DB_HOST": "enc:db.internal"})
+	var cfg encryptedConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithDecryptor(prefixDecryptor)); err != nil {
+		t.Fatal(err)
+	}
+	// defaults and fields without the option are not decrypted
+	want := encryptedConfig{Password: "hunter2", Token: "enc:not-decrypted", Host: "enc:db.internal"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestDecryptorErrors(t *testing.T) {
+	source := sources.Static(map[string]string{"DB_PASSWORD": "hunter2"})
+	for name, opts := range map[string][]envstruct.Option{
+		"no decryptor":    {envstruct.WithSources(source)},
+		"decryptor fails": {envstruct.WithSources(source), envstruct.WithDecryptor(prefixDecryptor)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var cfg encryptedConfig
+			_, err := envstruct.Parse(&cfg, opts...)
+			var errs envstruct.ParseErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Reason != envstruct.ReasonParse {
+				t.Fatalf("got error %v, want a parse error for DB_PASSWORD", err)
+			}
+			if strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("got error %q, which contains the value", err)
+			}
+		})
+	}
+}
+
+// ageFixture returns an age identity file and a recipient of the identity in it.
+func ageFixture(t *testing.T) (string, age.Recipient) {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(path, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, identity.Recipient()
+}
+
+// ageEncrypt encrypts `plaintext` to `recipient`, ASCII armored if `armored` is set and base64
+// encoded otherwise.
+func ageEncrypt(t *testing.T, recipient age.Recipient, plaintext string, armored bool) string {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopCloser{&buf}
+	if armored {
+		out = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(out, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if armored {
+		return buf.String()
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestAgeDecryptor(t *testing.T) {
+	identityFile, recipient := ageFixture(t)
+	decryptor := envstruct.AgeDecryptor(identityFile)
+	ctx := context.Background()
+	for _, armored := range []bool{true, false} {
+		got, err := decryptor.Decrypt(ctx, ageEncrypt(t, recipient, "hunter2", armored))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "hunter2" {
+			t.Errorf("got %q, want hunter2", got)
+		}
+	}
+
+	_, otherRecipient := ageFixture(t)
+	if _, err := decryptor.Decrypt(ctx, ageEncrypt(t, otherRecipient, "hunter2", true)); err == nil {
+		t.Error("expected an error for a value encrypted to another identity")
+	}
+	if _, err := decryptor.Decrypt(ctx, "not base64!"); err == nil {
+		t.Error("expected an error for a value that is not base64")
+	}
+}
+
+func TestAgeDecryptorMissingIdentity(t *testing.T) {
+	decryptor := envstruct.AgeDecryptor(filepath.Join(t.TempDir(), "missing.txt"))
+	if _, err := decryptor.Decrypt(context.Background(), "c2VjcmV0"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v, want the identity file to be missing", err)
+	}
+}
+
+// fakeKMS decrypts ciphertext blobs of the form `<key>:<plaintext>` for the key `key`.
+type fakeKMS struct {
+	inputs []*kms.DecryptInput
+}
+
+func (c *fakeKMS) Decrypt(_ context.Context, in *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	c.inputs = append(c.inputs, in)
+	plaintext, ok := bytes.CutPrefix(in.CiphertextBlob, []byte("key:"))
+	if !ok || in.EncryptionContext["service"] != "orders" {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+func TestAWSKMSDecryptor(t *testing.T) {
+	client := &fakeKMS{}
+	decryptor := envstruct.AWSKMSDecryptor(envstruct.AWSKMSDecryptorConfig{
+		KeyID:             "alias/orders",
+		EncryptionContext: map[string]string{"service": "orders"},
+		Client:            client,
+	})
+	ctx := context.Background()
+	got, err := decryptor.Decrypt(ctx, base64.StdEncoding.EncodeToString([]byte("key:hunter2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "hunter2" {
+		t.Errorf("got %q, want hunter2", got)
+	}
+	if keyID := client.inputs[0].KeyId; keyID == nil || *keyID != "alias/orders" {
+		t.Errorf("got key ID %v, want alias/orders", keyID)
+	}
+
+	if _, err := decryptor.Decrypt(ctx, "not base64!"); err == nil {
+		t.Error("expected an error for a value that is not base64")
+	}
+	if len(client.inputs) != 1 {
+		t.Error("want values that are not base64 to fail before calling KMS")
+	}
+}
+
+func TestGCPKMSDecryptor(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/"+keyName+":decrypt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ciphertext, _ := base64.StdEncoding.DecodeString(req.Ciphertext)
+		plaintext := bytes.TrimPrefix(ciphertext, []byte("key:"))
+		json.NewEncoder(w).Encode(map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+	}))
+	defer server.Close()
+
+	tokens := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+	decryptor := envstruct.GCPKMSDecryptor(envstruct.GCPKMSDecryptorConfig{KeyName: keyName, TokenSource: tokens, Endpoint: server.URL + "/"})
+	ctx := context.Background()
+	got, err := decryptor.Decrypt(ctx, base64.StdEncoding.EncodeToString([]byte("key:hunter2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "hunter2" {
+		t.Errorf("got %q, want hunter2", got)
+	}
+
+	missing := envstruct.GCPKMSDecryptor(envstruct.GCPKMSDecryptorConfig{KeyName: "missing", TokenSource: tokens, Endpoint: server.URL})
+	if _, err := missing.Decrypt(ctx, "a2V5"); err == nil || !strings.Contains(err.Error(), "key 'missing' not found") {
+		t.Errorf("got error %v, want the key to be missing", err)
+	}
+}
@@ -0,0 +1,64 @@
+package envstruct
+
+import (
+	"reflect"
+)
+
+// Change is a field whose value differs between two configs.
+type Change struct {
+	// Field is the dotted path of the field.
+	Field string `json:"field"`
+	Key   string `json:"key"`
+	// Old and New are the values of the field, redacted like Dump shows them.
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Diff returns the fields with an `env` tag whose values differ between the configs `old` and
+// `new`, in field order, for logging what changed on a reload or comparing the configs of two
+// environments:
+//
+//	for _, change := range Diff(&oldCfg, &newCfg) {
+//		logger.Info("config changed", "key", change.Key, "old", change.Old, "new", change.New)
+//	}
+//
+// Values of secret fields are redacted, so a change to a secret is reported with both values
+// shown as `[REDACTED]`, as are URLs of fields with the `parseurl` option. `old` and `new` are
+// usually of the same type; fields are matched by their dotted path, and a field only one of them
+// has is reported with a blank value on the other side.
+func Diff(old, new any) []Change {
+	return diffValues(structValue(old), structValue(new))
+}
+
+func diffValues(old, new reflect.Value) []Change {
+	oldFields := make(map[string]boundField)
+	walkFields(old, "", func(f boundField) {
+		oldFields[f.path] = f
+	})
+
+	var changes []Change
+	walkFields(new, "", func(f boundField) {
+		oldField, ok := oldFields[f.path]
+		delete(oldFields, f.path)
+		if !ok {
+			changes = append(changes, Change{Field: f.path, Key: f.tag.key, New: displayValue(f)})
+			return
+		}
+		if reflect.DeepEqual(oldField.value.Interface(), f.value.Interface()) {
+			return
+		}
+		changes = append(changes, Change{
+			Field: f.path,
+			Key:   f.tag.key,
+			Old:   displayValue(oldField),
+			New:   displayValue(f),
+		})
+	})
+	// fields only the old config has, in its field order
+	walkFields(old, "", func(f boundField) {
+		if _, ok := oldFields[f.path]; ok {
+			changes = append(changes, Change{Field: f.path, Key: f.tag.key, Old: displayValue(f)})
+		}
+	})
+	return changes
+}
@@ -0,0 +1,105 @@
+package envstruct_test
+
+import (
+	"slices"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type diffDB struct {
+	Host string `env:"DB_HOST"`
+	Port int    `env:"DB_PORT"`
+}
+
+type diffURL struct {
+	Scheme   string
+	Host     string
+	Password string
+}
+
+type diffConfig struct {
+	Level    string                   `env:"LOG_LEVEL"`
+	Hosts    []string                 `env:"HOSTS"`
+	Password envstruct.Secret[string] `env:"PASSWORD"`
+	Token    string                   `env:"TOKEN,secret"`
+	Cache    diffURL                  `env:"CACHE_URL,parseurl"`
+	DB       diffDB
+}
+
+func TestDiff(t *testing.T) {
+	old := diffConfig{
+		Level:    "info",
+		Hosts:    []string{"a.internal"},
+		Password: envstruct.NewSecret("hunter2"),
+		Token:    "t0ken",
+		Cache:    diffURL{Scheme: "redis", Host: "cache-1", Password: "pw"},
+		DB:       diffDB{Host: "db-1", Port: 5432},
+	}
+	if changes := envstruct.Diff(&old, &old); len(changes) != 0 {
+		t.Errorf("got changes %+v between a config and itself, want none", changes)
+	}
+
+	same := old
+	same.Hosts = []string{"a.internal"}
+	if changes := envstruct.Diff(&old, &same); len(changes) != 0 {
+		t.Errorf("got changes %+v between equal configs, want none", changes)
+	}
+
+	new := old
+	new.Hosts = []string{"a.internal", "b.internal"}
+	new.Password = envstruct.NewSecret("hunter3")
+	new.Token = "t0ken2"
+	new.Cache.Host = "cache-2"
+	new.DB.Port = 6543
+	want := []envstruct.Change{
+		{Field: "Hosts", Key: "HOSTS", Old: "a.internal", New: "a.internal,b.internal"},
+		{Field: "Password", Key: "PASSWORD", Old: "[REDACTED]", New: "[REDACTED]"},
+		{Field: "Token", Key: "TOKEN", Old: "[REDACTED]", New: "[REDACTED]"},
+		{Field: "Cache", Key: "CACHE_URL", Old: "[REDACTED]", New: "[REDACTED]"},
+		{Field: "DB.Port", Key: "DB_PORT", Old: "5432", New: "6543"},
+	}
+	if got := envstruct.Diff(&old, &new); !slices.Equal(got, want) {
+		t.Errorf("got changes %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffTypes(t *testing.T) {
+	type oldConfig struct {
+		Level string `env:"LOG_LEVEL"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+	type newConfig struct {
+		Region string `env:"REGION"`
+		Port   int    `env:"PORT"`
+		Level  string `env:"LOG_LEVEL"`
+	}
+	old := oldConfig{Level: "info", Port: 8080, Debug: true}
+	new := newConfig{Region: "eu-west-1", Port: 8080, Level: "debug"}
+	// fields are matched by path, in the field order of the new config, followed by the fields only
+	// the old config has
+	want := []envstruct.Change{
+		{Field: "Region", Key: "REGION", New: "eu-west-1"},
+		{Field: "Level", Key: "LOG_LEVEL", Old: "info", New: "debug"},
+		{Field: "Debug", Key: "DEBUG", Old: "true"},
+	}
+	if got := envstruct.Diff(&old, &new); !slices.Equal(got, want) {
+		t.Errorf("got changes %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffUnexportedStruct(t *testing.T) {
+	type config struct {
+		Port  int `env:"PORT"`
+		inner struct {
+			X string `env:"X"`
+		}
+	}
+	old, new := config{Port: 8080}, config{Port: 9090}
+	old.inner.X, new.inner.X = "a", "b"
+	want := []envstruct.Change{{Field: "Port", Key: "PORT", Old: "8080", New: "9090"}}
+	if got := envstruct.Diff(&old, &new); !slices.Equal(got, want) {
+		t.Errorf("got changes %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MountedDir returns a source that reads a directory where each file name is a key and the file
+// content is the value, the layout used when a Kubernetes ConfigMap or Secret is mounted as a
+// volume. File names are flattened like config file keys, so both `DB_PASSWORD` and
+// `db-password` are resolved as `DB_PASSWORD`. Trailing newlines are removed from values.
+//
+// The `..data` symlink and the timestamped directories Kubernetes uses for atomic updates are
+// skipped, as are any other subdirectories. The directory is read the first time a key is looked
+// up.
+func MountedDir(dir string) Source {
+	return newMapSource("dir:"+dir, func(ctx context.Context) (map[string]string, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]string)
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, "..") {
+				continue
+			}
+			// keys are symlinks into the current timestamped directory, so stat through the link
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			values[joinFlatKey("", name, defaultKeySeparator)] = strings.TrimRight(string(data), "\r\n")
+		}
+		return values, nil
+	})
+}
+
+// dockerSecretsDir is where Docker Swarm and Compose mount secrets.
+const dockerSecretsDir = "/run/secrets"
+
+// DockerSecrets returns a source that reads `/run/secrets/<lowercased key>`, the location Docker
+// Swarm and Compose mount secrets at, so `DB_PASSWORD` is read from `/run/secrets/db_password`. Put
+// it before Env() in the chain to check secrets before the environment:
+//
+//	res, err := Parse(&cfg, WithSources(DockerSecrets(), Env()))
+//
+// Trailing newlines are removed from values.
+func DockerSecrets() Source {
+	return dockerSecrets(dockerSecretsDir)
+}
+
+// dockerSecrets returns the source of DockerSecrets reading secrets from `dir`.
+func dockerSecrets(dir string) Source {
+	return newFileKeySource("docker-secrets", dir, func(key string) []string {
+		return []string{strings.ToLower(key)}
+	})
+}
+
+// SystemdCredentials returns a source that reads credentials passed to a systemd service with
+// `LoadCredential=` or `SetCredential=`, which systemd places in the directory named by the
+// `CREDENTIALS_DIRECTORY` environment variable. Unlike environment variables, credentials are not
+// visible to other processes of the same user.
+//
+// A key is read from the credential with the same name, or failing that its lowercased name or
+// its lowercased name with underscores replaced by dashes, so `DB_PASSWORD` can be read from a
+// credential named `DB_PASSWORD`, `db_password` or `db-password`. If `CREDENTIALS_DIRECTORY` is
+// not set the source has no values. Trailing newlines are removed from values.
+func SystemdCredentials() Source {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return &funcSource{
+			name: "systemd-credentials",
+			lookup: func(context.Context, string) (string, bool, error) {
+				return "", false, nil
+			},
+		}
+	}
+	return newFileKeySource("systemd-credentials", dir, func(key string) []string {
+		lower := strings.ToLower(key)
+		return []string{key, lower, strings.ReplaceAll(lower, "_", "-")}
+	})
+}
+
+// newFileKeySource returns a source that reads the first existing file in `dir` named by
+// `fileNames(key)` every time a key is looked up. If none of the files exist the key is missing.
+func newFileKeySource(name, dir string, fileNames func(key string) []string) Source {
+	return &funcSource{
+		name: name,
+		lookup: func(_ context.Context, key string) (string, bool, error) {
+			for _, fileName := range fileNames(key) {
+				data, err := os.ReadFile(filepath.Join(dir, fileName))
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return "", false, err
+				}
+				return strings.TrimRight(string(data), "\r\n"), true, nil
+			}
+			return "", false, nil
+		},
+	}
+}
+
+// EnvDir returns a source that reads a daemontools `envdir` directory, as used with runit's
+// `chpst -e` and s6's `s6-envdir`, where each file name is a key and the file holds its value.
+// The same rules as `envdir` apply: only the first line of a file is used, trailing spaces and
+// tabs are removed, NUL bytes are turned into newlines, and an empty file leaves the key unset.
+// File names are used as keys unchanged, and subdirectories and names containing `=` are skipped.
+//
+// The directory is read the first time a key is looked up.
+func EnvDir(dir string) Source {
+	return newMapSource("envdir:"+dir, func(ctx context.Context) (map[string]string, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]string)
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.Contains(name, "=") {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if len(data) == 0 {
+				continue
+			}
+			line, _, _ := strings.Cut(string(data), "\n")
+			line = strings.TrimRight(line, " \t")
+			values[name] = strings.ReplaceAll(line, "\x00", "\n")
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,134 @@
+package envstruct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles writes `files`, mapping names relative to `dir` to content.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMountedDir(t *testing.T) {
+	// the layout of a mounted ConfigMap: keys link through ..data to a timestamped directory
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"..2024_01_01_00_00_00.000000000/db-host":     "db.internal\n",
+		"..2024_01_01_00_00_00.000000000/DB_PASSWORD": "hunter2\r\n",
+		"nested/ignored": "x",
+	})
+	if err := os.Symlink("..2024_01_01_00_00_00.000000000", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"db-host", "DB_PASSWORD"} {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Password string `env:"DB_PASSWORD"`
+		Nested   string `env:"NESTED"`
+	}
+	if _, err := Parse(&cfg, WithSources(MountedDir(dir))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Password != "hunter2" || cfg.Nested != "" {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestDockerSecrets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"db_password": "hunter2\n"})
+	var cfg struct {
+		Password string `env:"DB_PASSWORD"`
+		Token    string `env:"API_TOKEN"`
+	}
+	res, err := Parse(&cfg, WithSources(dockerSecrets(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.Token != "" {
+		t.Errorf("got %+v", cfg)
+	}
+	if res.Sources["Password"] != "docker-secrets" {
+		t.Errorf("got source %q", res.Sources["Password"])
+	}
+}
+
+func TestSystemdCredentials(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"DB_HOST":     "db.internal",
+		"db_password": "hunter2\n",
+		"api-token":   "abc123",
+	})
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Password string `env:"DB_PASSWORD"`
+		Token    string `env:"API_TOKEN"`
+		Missing  string `env:"MISSING"`
+	}
+	if _, err := Parse(&cfg, WithSources(SystemdCredentials())); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Password != "hunter2" || cfg.Token != "abc123" || cfg.Missing != "" {
+		t.Errorf("got %+v", cfg)
+	}
+
+	// outside of a service with credentials the source has no values
+	t.Setenv("CREDENTIALS_DIRECTORY", "")
+	cfg.Host = ""
+	if _, err := Parse(&cfg, WithSources(SystemdCredentials())); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "" {
+		t.Errorf("got host %q without a credentials directory", cfg.Host)
+	}
+}
+
+func TestEnvDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"DB_HOST":       "db.internal \t\nignored second line\n",
+		"TLS_CERT":      "line one\x00line two",
+		"EMPTY":         "",
+		"A=B":           "skipped",
+		"nested/DB_URL": "skipped",
+		"db_password":   "hunter2",
+	})
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Cert     string `env:"TLS_CERT"`
+		Empty    string `env:"EMPTY" default:"unset"`
+		Password string `env:"DB_PASSWORD"`
+		Nested   string `env:"DB_URL"`
+	}
+	if _, err := Parse(&cfg, WithSources(EnvDir(dir))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Cert != "line one\nline two" || cfg.Nested != "" {
+		t.Errorf("got %+v", cfg)
+	}
+	if cfg.Empty != "unset" {
+		t.Errorf("got %q for an empty file, want the key to be unset", cfg.Empty)
+	}
+	if cfg.Password != "" {
+		t.Errorf("got %q, want file names to be used as keys unchanged", cfg.Password)
+	}
+
+}
@@ -0,0 +1,137 @@
+package envstruct
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/dotenv"
+)
+
+// DotEnvFiles returns the dotenv files for the environment `env` in the order they are loaded:
+//
+//	.env
+//	.env.local
+//	.env.<env>
+//	.env.<env>.local
+//
+// This matches the conventions used by popular frameworks such as Vite and dotenv-flow. If `env` is
+// blank only `.env` and `.env.local` are returned.
+func DotEnvFiles(env string) []string {
+	files := []string{".env", ".env.local"}
+	if env != "" {
+		files = append(files, ".env."+env, ".env."+env+".local")
+	}
+	return files
+}
+
+// LoadDotEnv reads the given dotenv files and sets the environment variables they define. Files
+// are read in order and values from later files override values from earlier ones. Files that do
+// not exist are skipped.
+//
+// Variables that are already set in the process environment are never overridden, so the real
+// environment always has the highest precedence.
+//
+// If no files are given, the files returned by DotEnvFiles for the value of the `ENV` environment
+// variable are loaded.
+func LoadDotEnv(files ...string) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in LoadDotEnv: %w", err)
+		}
+	}()
+	if len(files) == 0 {
+		files = DotEnvFiles(os.Getenv("ENV"))
+	}
+
+	values := make(map[string]string)
+	for _, file := range files {
+		fileValues, err := readDotEnvFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	return setEnvIfUnset(values)
+}
+
+// ReadDotEnv parses dotenv formatted content. Supported syntax:
+//
+//   - `KEY=value` pairs, optionally prefixed with `export `
+//   - blank lines and lines starting with `#`
+//   - inline comments after unquoted values (` # comment`)
+//   - single quoted values, which are taken literally
+//   - double quoted values, which support `\n`, `\r`, `\t`, `\"` and `\\` escapes
+func ReadDotEnv(r io.Reader) (map[string]string, error) {
+	return dotenv.Read(r)
+}
+
+func readDotEnvFile(name string) (map[string]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values, err := ReadDotEnv(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading '%s': %w", name, err)
+	}
+	return values, nil
+}
+
+// redacted is written in place of the value of fields tagged with the `secret` option.
+const redacted = "[REDACTED]"
+
+// WriteDotEnv writes the `env` mappings of `obj` and their current values to `w` in dotenv syntax,
+// one `KEY=value` line per field in field order. Values are quoted when needed so the output can
+// be read back with ReadDotEnv.
+//
+// Fields tagged with the `secret` option, e.g. `env:"DB_PASSWORD,secret"`, are written with a
+// redacted value, or left out entirely if WithOmitSecrets is used.
+func WriteDotEnv(w io.Writer, obj any, opts ...Option) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in WriteDotEnv: %w", err)
+		}
+	}()
+	o := newOptions(opts)
+
+	var sb strings.Builder
+	walkFields(structValue(obj), "", func(f boundField) {
+		value, ok := formatValue(f.value)
+		if !ok {
+			return
+		}
+		if f.tag.has("secret") {
+			if o.omitSecrets {
+				return
+			}
+			value = redacted
+		}
+		sb.WriteString(f.tag.key + "=" + dotenv.Quote(value) + "\n")
+	})
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// setEnvIfUnset sets each of the given environment variables that is not already set.
+func setEnvIfUnset(values map[string]string) error {
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package envstruct_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestDotEnvFiles(t *testing.T) {
+	if got, want := envstruct.DotEnvFiles(""), []string{".env", ".env.local"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want := []string{".env", ".env.local", ".env.production", ".env.production.local"}
+	if got := envstruct.DotEnvFiles("production"); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadDotEnv(t *testing.T) {
+	content := `# comment
+export HOST=db.internal
+PORT=5432 # inline comment
+
+SINGLE='literal \n # not a comment'
+DOUBLE="line\nbreak \"quoted\""
+EMPTY=
+`
+	got, err := envstruct.ReadDotEnv(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"HOST":   "db.internal",
+		"PORT":   "5432",
+		"SINGLE": `literal \n # not a comment`,
+		"DOUBLE": "line\nbreak \"quoted\"",
+		"EMPTY":  "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("got %s=%q, want %q", key, got[key], value)
+		}
+	}
+}
+
+// unsetenv unsets `key` until the test ends.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	env := write(".env", "HOST=localhost\nPORT=5432\nLEVEL=info\n")
+	local := write(".env.local", "PORT=6543\n")
+	unsetenv(t, "HOST")
+	unsetenv(t, "PORT")
+	t.Setenv("LEVEL", "debug")
+
+	if err := envstruct.LoadDotEnv(env, local, filepath.Join(dir, ".env.missing")); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"HOST":  "localhost",
+		"PORT":  "6543",  // later files override earlier ones
+		"LEVEL": "debug", // the environment is never overridden
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadDotEnvInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(file, []byte("HOST=\"unterminated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := envstruct.LoadDotEnv(file); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+type writtenConfig struct {
+	Host     string `env:"HOST"`
+	Greeting string `env:"GREETING"`
+	Port     int    `env:"PORT"`
+	Password string `env:"DB_PASSWORD,secret"`
+	Internal string
+}
+
+func TestWriteDotEnv(t *testing.T) {
+	cfg := writtenConfig{
+		Host:     "db.internal",
+		Greeting: "hello # world\n",
+		Port:     5432,
+		Password: "hunter2",
+		Internal: "not written",
+	}
+	var sb strings.Builder
+	if err := envstruct.WriteDotEnv(&sb, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i], _, _ = strings.Cut(line, "=")
+	}
+	if want := []string{"HOST", "GREETING", "PORT", "DB_PASSWORD"}; !slices.Equal(keys, want) {
+		t.Fatalf("got keys %q, want %q in field order", keys, want)
+	}
+
+	values, err := envstruct.ReadDotEnv(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"HOST":        "db.internal",
+		"GREETING":    "hello # world\n",
+		"PORT":        "5432",
+		"DB_PASSWORD": "[REDACTED]",
+	}
+	if !maps.Equal(values, want) {
+		t.Errorf("read back %q, want %q", values, want)
+	}
+}
+
+func TestWriteDotEnvOmitSecrets(t *testing.T) {
+	cfg := writtenConfig{Host: "db.internal", Password: "hunter2"}
+	var sb strings.Builder
+	if err := envstruct.WriteDotEnv(&sb, &cfg, envstruct.WithOmitSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "DB_PASSWORD") {
+		t.Errorf("got %q, want the secret left out", sb.String())
+	}
+}
@@ -0,0 +1,85 @@
+package envstruct
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WithResult passes the `Result` of a Parse call to Dump, so it can show the source each value was
+// resolved from.
+func WithResult(res Result) Option {
+	return func(o *options) {
+		o.result = &res
+	}
+}
+
+// Dump writes the effective configuration of `cfg` to `w` as a table with the key, value and
+// source of every field with an `env` tag, in field order. It is meant to be logged when a service
+// starts:
+//
+//	res, err := Parse(&cfg)
+//	...
+//	_ = Dump(os.Stderr, &cfg, WithResult(res))
+//
+// Fields tagged with the `secret` option and Secret fields are shown as `[REDACTED]`, or left out
+// if WithOmitSecrets is used. Fields with the `parseurl` option are always redacted since their
+// URL usually holds credentials. Sources are only shown if WithResult is used, and are `-` for
+// fields that were not set.
+func Dump(w io.Writer, cfg any, opts ...Option) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Dump: %w", err)
+		}
+	}()
+	o := newOptions(opts)
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	if o.result != nil {
+		fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	} else {
+		fmt.Fprintln(tw, "KEY\tVALUE")
+	}
+	walkFields(structValue(cfg), "", func(f boundField) {
+		if f.tag.has("secret") && o.omitSecrets {
+			return
+		}
+		value := displayValue(f)
+		if o.result == nil {
+			fmt.Fprintf(tw, "%s\t%s\n", f.tag.key, dumpValue(value))
+			return
+		}
+		source := o.result.Sources[f.path]
+		if source == "" {
+			source = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.tag.key, dumpValue(value), source)
+	})
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// displayValue returns the value of `f` as it is shown by Dump, with secrets and URLs redacted.
+func displayValue(f boundField) string {
+	if f.tag.has("secret") || f.tag.has("parseurl") {
+		return redacted
+	}
+	if value, ok := formatValue(f.value); ok {
+		return value
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// dumpValue quotes `value` if it is blank or would break the table, such as values with tabs or
+// newlines.
+func dumpValue(value string) string {
+	if value == "" || strings.ContainsAny(value, "\t\n\r") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
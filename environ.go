@@ -0,0 +1,51 @@
+package envstruct
+
+import (
+	"fmt"
+	"os"
+)
+
+// Setenv sets the environment variables of the fields of `obj` to their values as returned by
+// Marshal, so child processes started later inherit the config. Variables that are already set
+// are overwritten.
+func Setenv(obj any, opts ...Option) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Setenv: %w", err)
+		}
+	}()
+	values, err := Marshal(obj, opts...)
+	if err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(values) {
+		if err := os.Setenv(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Environ returns the values of the fields of `obj` as returned by Marshal as `KEY=value` strings
+// sorted by key, for the `Env` of an `exec.Cmd`. Add them to the environment of the current
+// process to pass it on as well, since later entries take precedence:
+//
+//	env, err := Environ(&childCfg)
+//	...
+//	cmd.Env = append(os.Environ(), env...)
+func Environ(obj any, opts ...Option) (env []string, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Environ: %w", err)
+		}
+	}()
+	values, err := Marshal(obj, opts...)
+	if err != nil {
+		return nil, err
+	}
+	env = make([]string, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		env = append(env, key+"="+values[key])
+	}
+	return env, nil
+}
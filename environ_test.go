@@ -0,0 +1,126 @@
+package envstruct_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type environConfig struct {
+	Port    int                      `env:"PORT"`
+	Hosts   []string                 `env:"HOSTS"`
+	Timeout time.Duration            `env:"TIMEOUT"`
+	Debug   bool                     `env:"DEBUG"`
+	Token   envstruct.Secret[string] `env:"TOKEN"`
+}
+
+func TestEnviron(t *testing.T) {
+	cfg := environConfig{
+		Port:    8080,
+		Hosts:   []string{"a.internal", "b.internal"},
+		Timeout: 90 * time.Second,
+		Token:   envstruct.NewSecret("hunter2"),
+	}
+	env, err := envstruct.Environ(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DEBUG=false", "HOSTS=a.internal,b.internal", "PORT=8080", "TIMEOUT=1m30s", "TOKEN=hunter2"}
+	if !slices.Equal(env, want) {
+		t.Errorf("got %q, want %q", env, want)
+	}
+
+	env, err = envstruct.Environ(&cfg, envstruct.WithOmitSecrets(), envstruct.WithOmitZero())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"HOSTS=a.internal,b.internal", "PORT=8080", "TIMEOUT=1m30s"}
+	if !slices.Equal(env, want) {
+		t.Errorf("got %q without secrets and zero values, want %q", env, want)
+	}
+}
+
+func TestEnvironError(t *testing.T) {
+	cfg := environConfig{Hosts: []string{"a,b"}}
+	_, err := envstruct.Environ(&cfg)
+	if err == nil || !strings.HasPrefix(err.Error(), "in Environ: ") {
+		t.Errorf("got error %v, want an error for the item with a comma", err)
+	}
+}
+
+func TestSetenv(t *testing.T) {
+	t.Setenv("PORT", "9090")
+	t.Setenv("HOSTS", "")
+	t.Setenv("TIMEOUT", "")
+	t.Setenv("DEBUG", "")
+	t.Setenv("TOKEN", "")
+	cfg := environConfig{
+		Port:    8080,
+		Hosts:   []string{"a.internal"},
+		Timeout: time.Second,
+		Debug:   true,
+		Token:   envstruct.NewSecret("hunter2"),
+	}
+	if err := envstruct.Setenv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"PORT":    "8080",
+		"HOSTS":   "a.internal",
+		"TIMEOUT": "1s",
+		"DEBUG":   "true",
+		"TOKEN":   "hunter2",
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+
+	// the variables parse back into the same config
+	var parsed environConfig
+	if _, err := envstruct.Parse(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Port != cfg.Port || !slices.Equal(parsed.Hosts, cfg.Hosts) || parsed.Timeout != cfg.Timeout ||
+		parsed.Debug != cfg.Debug || parsed.Token.Value() != "hunter2" {
+		t.Errorf("got %+v after parsing the set variables, want %+v", parsed, cfg)
+	}
+
+	cfg.Hosts = []string{"a,b"}
+	if err := envstruct.Setenv(&cfg); err == nil || !strings.HasPrefix(err.Error(), "in Setenv: ") {
+		t.Errorf("got error %v, want an error for the item with a comma", err)
+	}
+}
+
+func TestEnvironUnexportedStruct(t *testing.T) {
+	cfg := struct {
+		Port  int `env:"PORT"`
+		inner struct {
+			X string `env:"X"`
+		}
+	}{Port: 8080}
+	cfg.inner.X = "x"
+	env, err := envstruct.Environ(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"PORT=8080"}; !slices.Equal(env, want) {
+		t.Errorf("got %q, want %q", env, want)
+	}
+
+	t.Setenv("PORT", "")
+	t.Setenv("X", "")
+	if err := envstruct.Setenv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("PORT"); got != "8080" {
+		t.Errorf("got PORT=%q, want %q", got, "8080")
+	}
+	if got, ok := os.LookupEnv("X"); got != "" {
+		t.Errorf("got X=%q (set %t), want the field of the unexported struct left out", got, ok)
+	}
+}
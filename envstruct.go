@@ -0,0 +1,145 @@
+// Package envstruct binds environment variables, and values from other config sources, to the
+// fields of structs tagged with `env`:
+//
+//	type Config struct {
+//		Port     int    `env:"PORT" default:"8080"`
+//		Password string `env:"DB_PASSWORD,secret,required"`
+//	}
+//
+//	var cfg Config
+//	res, err := envstruct.Parse(&cfg)
+//
+// Parse resolves every key from a chain of sources, the process environment by default, and
+// reports every field that could not be set at once.
+//
+// Fields can be strings, ints, bools, `time.Duration` values such as `1m30s`, `[]byte`, types
+// implementing `encoding.TextUnmarshaler`, slices of those as comma separated items, e.g. `a,b,c`,
+// and maps with string keys as comma separated `key:value` pairs, e.g. `orders:5s,users:1s`.
+package envstruct
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ParseStructFromEnv takes a struct as an input and recursively loops tough all fields on the
+// struct. If a field is not another struct and has a `env` tag, the environment variable associated
+// with that tag will be retrieved and added to the struct.
+//
+// If the `errOnMissingValue` flag is set to `true`, any tag that is missing an environment variable
+// will result in an error being returned.
+//
+// All fields are processed before returning, so the returned error wraps a `ParseErrors` value
+// holding every failure that was found.
+func ParseStructFromEnv(obj any, errOnMissingValue bool) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in ParseStructFromEnv: %w", err)
+		}
+	}()
+	p := newParser(options{errOnMissingValue: errOnMissingValue})
+	p.parseStruct(structValue(obj), "")
+	p.validateStruct(structValue(obj), "")
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
+}
+
+func newEnvVarMissingErr[T any](key string) (T, error) {
+	var blank T
+	return blank, &envVarError{
+		reason:   ReasonMissing,
+		key:      key,
+		typeName: fmt.Sprintf("%T", blank),
+	}
+}
+
+func newEnvVarParsingErr[T any](key string, err error) (T, error) {
+	var blank T
+	return blank, &envVarError{
+		reason:   ReasonParse,
+		key:      key,
+		typeName: fmt.Sprintf("%T", blank),
+		err:      err,
+	}
+}
+
+func getEnvString(key, value string, errIfMissing bool) (string, error) {
+	if errIfMissing && value == "" {
+		return newEnvVarMissingErr[string](key)
+	}
+	return value, nil
+}
+
+func getEnvInt64(key, value string, errIfMissing bool) (int64, error) {
+	if errIfMissing && value == "" {
+		return newEnvVarMissingErr[int64](key)
+	}
+	convertedInt, err := strconv.Atoi(value)
+	if err != nil {
+		return newEnvVarParsingErr[int64](key, err)
+	}
+	return int64(convertedInt), nil
+}
+
+func getEnvBool(key, value string, errIfMissing bool) (bool, error) {
+	if errIfMissing && value == "" {
+		return newEnvVarMissingErr[bool](key)
+	}
+	convertedBool, err := strconv.ParseBool(value)
+	if err != nil {
+		return newEnvVarParsingErr[bool](key, err)
+	}
+	return convertedBool, nil
+}
+
+// durationType is the type of `time.Duration` fields, which are parsed with `time.ParseDuration`.
+var durationType = reflect.TypeFor[time.Duration]()
+
+func getEnvDuration(key, value string, errIfMissing bool) (time.Duration, error) {
+	if errIfMissing && value == "" {
+		return newEnvVarMissingErr[time.Duration](key)
+	}
+	convertedDuration, err := time.ParseDuration(value)
+	if err != nil {
+		return newEnvVarParsingErr[time.Duration](key, err)
+	}
+	return convertedDuration, nil
+}
+
+// formatValue converts a field value into its environment variable representation. It reports
+// false for kinds that are not supported by ParseStructFromEnv.
+func formatValue(val reflect.Value) (string, bool) {
+	if val.CanInterface() {
+		if marshaler, ok := val.Interface().(encoding.TextMarshaler); ok {
+			text, err := marshaler.MarshalText()
+			return string(text), err == nil
+		}
+	}
+	if val.Type() == durationType {
+		return time.Duration(val.Int()).String(), true
+	}
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), true
+	case reflect.Int:
+		return strconv.FormatInt(val.Int(), 10), true
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), true
+	case reflect.Slice:
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			value, err := formatList(val)
+			return value, err == nil
+		}
+		return string(val.Bytes()), true
+	case reflect.Map:
+		value, err := formatMap(val)
+		return value, err == nil
+	default:
+		return "", false
+	}
+}
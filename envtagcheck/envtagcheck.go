@@ -0,0 +1,246 @@
+// Package envtagcheck defines an analyzer that checks the `env` struct tags of config structs for
+// mistakes that would otherwise only show up when the config is parsed, or not at all:
+//
+//   - malformed tags, such as a blank key or an unknown option
+//   - tags on fields of types that Parse does not support
+//   - keys bound by more than one field of a struct
+//   - `default` tags that do not parse as the type of the field
+//   - tags on unexported fields, which are never set
+//
+// The analyzer can be run with `go vet` through the envtagcheck command:
+//
+//	go install github.com/jha-captech/env-vars-to-struct/cmd/envtagcheck@latest
+//	go vet -vettool=$(which envtagcheck) ./...
+package envtagcheck
+
+import (
+	"errors"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/jha-captech/env-vars-to-struct/internal/schema"
+)
+
+// Analyzer reports mistakes in `env` struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "envtag",
+	Doc:      "check env struct tags of config structs",
+	URL:      "https://pkg.go.dev/github.com/jha-captech/env-vars-to-struct/envtagcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// options are the `env` tag options and whether they take a value.
+var options = map[string]bool{
+	"secret":    false,
+	"required":  false,
+	"parseurl":  false,
+	"quantity":  true,
+	"source":    true,
+	"exec":      true,
+	"op":        true,
+	"validate":  true,
+	"severity":  true,
+	"encrypted": false,
+	"signed":    false,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.TypeSpec)(nil), (*ast.StructType)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.StructType:
+			for _, field := range n.Fields.List {
+				checkField(pass, field)
+			}
+		case *ast.TypeSpec:
+			checkDuplicates(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+// checkField reports mistakes in the tag of a single field.
+func checkField(pass *analysis.Pass, field *ast.Field) {
+	if field.Tag == nil {
+		return
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return
+	}
+	tag := reflect.StructTag(raw)
+	value, ok := tag.Lookup("env")
+	if !ok {
+		return
+	}
+
+	key, rest, _ := strings.Cut(value, ",")
+	key = strings.TrimSpace(key)
+	switch {
+	case key == "":
+		pass.Reportf(field.Tag.Pos(), "env tag has no key")
+		return
+	case strings.ContainsAny(key, " \t=$"):
+		pass.Reportf(field.Tag.Pos(), "env tag key %q is not a valid environment variable name", key)
+	}
+	opts := make(map[string]string)
+	if rest != "" {
+		for _, opt := range strings.Split(rest, ",") {
+			name, arg, hasArg := strings.Cut(opt, "=")
+			name = strings.TrimSpace(name)
+			takesArg, known := options[name]
+			switch {
+			case !known:
+				pass.Reportf(field.Tag.Pos(), "env tag of %s has unknown option %q", key, name)
+			case takesArg && name != "quantity" && strings.TrimSpace(arg) == "":
+				pass.Reportf(field.Tag.Pos(), "env tag option %q of %s needs a value", name, key)
+			case !takesArg && hasArg:
+				pass.Reportf(field.Tag.Pos(), "env tag option %q of %s does not take a value", name, key)
+			}
+			opts[name] = strings.TrimSpace(arg)
+		}
+	}
+
+	for _, name := range field.Names {
+		if !name.IsExported() {
+			pass.Reportf(name.Pos(), "env tag on unexported field %s is ignored", name.Name)
+		}
+	}
+
+	typ := pass.TypesInfo.TypeOf(field.Type)
+	if typ == nil {
+		return
+	}
+	_, parseURL := opts["parseurl"]
+	_, quantity := opts["quantity"]
+	if _, isStruct := typ.Underlying().(*types.Struct); isStruct && !parseURL && !schema.IsTextUnmarshaler(typ) {
+		pass.Reportf(field.Tag.Pos(), "env tag of %s on a struct field is ignored, tag its fields or use parseurl", key)
+		return
+	}
+	if !supported(typ, parseURL, quantity) {
+		pass.Reportf(field.Tag.Pos(), "env tag of %s is on a field of unsupported type %s", key,
+			types.TypeString(typ, types.RelativeTo(pass.Pkg)))
+		return
+	}
+	if def, ok := tag.Lookup("default"); ok && def != "" && !quantity {
+		if err := checkDefault(typ, def); err != nil {
+			pass.Reportf(field.Tag.Pos(), "default %q of %s does not parse as %s: %v", def, key,
+				types.TypeString(typ, types.RelativeTo(pass.Pkg)), err)
+		}
+	}
+}
+
+// supported reports whether Parse can set a field of type `typ` with the given tag options.
+func supported(typ types.Type, parseURL, quantity bool) bool {
+	if schema.IsTextUnmarshaler(typ) {
+		return true
+	}
+	if parseURL {
+		_, ok := typ.Underlying().(*types.Struct)
+		return ok
+	}
+	if quantity {
+		basic, ok := typ.Underlying().(*types.Basic)
+		return ok && basic.Info()&types.IsNumeric != 0 && basic.Info()&types.IsComplex == 0
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		if elem, ok := t.Elem().Underlying().(*types.Basic); ok && elem.Kind() == types.Byte {
+			return true
+		}
+		return supportedItem(t.Elem())
+	case *types.Map:
+		key, ok := t.Key().Underlying().(*types.Basic)
+		return ok && key.Kind() == types.String && supportedItem(t.Elem())
+	}
+	return supportedItem(typ)
+}
+
+// supportedItem reports whether Parse can set a value of type `typ` by itself, as a field or as an
+// item of a slice or map field. Secrets can only be fields.
+func supportedItem(typ types.Type) bool {
+	if isDuration(typ) {
+		return true
+	}
+	if schema.IsTextUnmarshaler(typ) {
+		return !schema.IsSecretType(typ)
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch basic.Kind() {
+	case types.String, types.Int, types.Bool:
+		return true
+	}
+	return false
+}
+
+// isDuration reports whether `typ` is `time.Duration`, which is parsed with `time.ParseDuration`.
+func isDuration(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Duration" && obj.Pkg() != nil && obj.Pkg().Path() == "time"
+}
+
+// checkDefault converts the default `def` the way Parse would for a field of type `typ`.
+func checkDefault(typ types.Type, def string) error {
+	if isDuration(typ) {
+		_, err := time.ParseDuration(def)
+		return err
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok || schema.IsTextUnmarshaler(typ) {
+		return nil
+	}
+	var err error
+	switch basic.Kind() {
+	case types.Int:
+		_, err = strconv.Atoi(def)
+	case types.Bool:
+		_, err = strconv.ParseBool(def)
+	}
+	if numErr := (*strconv.NumError)(nil); err != nil && errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// checkDuplicates reports keys that are bound by more than one field of the struct type `spec`,
+// including the fields of nested structs.
+func checkDuplicates(pass *analysis.Pass, spec *ast.TypeSpec) {
+	obj, ok := pass.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	seen := make(map[string]string)
+	for _, f := range schema.Fields(st) {
+		first, dup := seen[f.Key]
+		if !dup {
+			seen[f.Key] = f.Path
+			continue
+		}
+		// fields of named nested structs are reported at the type, since they may be shared
+		pos := f.Pos
+		if pos < spec.Pos() || pos > spec.End() {
+			pos = spec.Name.Pos()
+		}
+		pass.Reportf(pos, "env key %s of %s.%s is also bound by %s", f.Key, spec.Name.Name, f.Path, first)
+	}
+}
@@ -0,0 +1,13 @@
+package envtagcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/jha-captech/env-vars-to-struct/envtagcheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), envtagcheck.Analyzer, "a")
+}
@@ -0,0 +1,103 @@
+package a
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type Supported struct {
+	Host     string                   `env:"HOST" default:"localhost"`
+	Port     int                      `env:"PORT,required" default:"8080"`
+	Debug    bool                     `env:"DEBUG" default:"true"`
+	Timeout  time.Duration            `env:"TIMEOUT" default:"1m30s"`
+	Hosts    []string                 `env:"HOSTS"`
+	Ports    []int                    `env:"PORTS"`
+	Backoffs []time.Duration          `env:"BACKOFFS"`
+	Labels   map[string]string        `env:"LABELS"`
+	Limits   map[string]int           `env:"LIMITS"`
+	Timeouts map[string]time.Duration `env:"TIMEOUTS"`
+	Key      []byte                   `env:"KEY"`
+	IP       net.IP                   `env:"IP" default:"not parsed by the analyzer"`
+	Proxies  []net.IP                 `env:"PROXIES"`
+	Endpoint url.URL                  `env:"ENDPOINT,parseurl"`
+	Token    envstruct.Secret[string] `env:"TOKEN,secret,required"`
+	Memory   int64                    `env:"MEMORY,quantity" default:"512Mi"`
+	Level    string                   `env:"LEVEL,validate=oneof=debug info,severity=warn"`
+	Password string                   `env:" PASSWORD ,source=vault,encrypted"`
+	Untagged float64
+	Other    string `json:"other"`
+	Database struct {
+		Host string `env:"DB_HOST"`
+	}
+}
+
+type Malformed struct {
+	Blank     string `env:""`                      // want `env tag has no key`
+	NoKey     string `env:",required"`             // want `env tag has no key`
+	Spaces    string `env:"MY KEY"`                // want `env tag key "MY KEY" is not a valid environment variable name`
+	Assign    string `env:"KEY=VALUE"`             // want `env tag key "KEY=VALUE" is not a valid environment variable name`
+	Unknown   string `env:"UNKNOWN,requird"`       // want `env tag of UNKNOWN has unknown option "requird"`
+	NoValue   string `env:"NO_VALUE,validate"`     // want `env tag option "validate" of NO_VALUE needs a value`
+	EmptyArg  string `env:"EMPTY_ARG,source="`     // want `env tag option "source" of EMPTY_ARG needs a value`
+	WithValue string `env:"WITH_VALUE,secret=yes"` // want `env tag option "secret" of WITH_VALUE does not take a value`
+}
+
+type Unsupported struct {
+	Ratio    float64                    `env:"RATIO"`             // want `env tag of RATIO is on a field of unsupported type float64`
+	Count    int64                      `env:"COUNT"`             // want `env tag of COUNT is on a field of unsupported type int64`
+	Ch       chan string                `env:"CH"`                // want `env tag of CH is on a field of unsupported type chan string`
+	Ptr      *string                    `env:"PTR"`               // want `env tag of PTR is on a field of unsupported type \*string`
+	Ratios   []float64                  `env:"RATIOS"`            // want `env tag of RATIOS is on a field of unsupported type \[\]float64`
+	Nested   [][]string                 `env:"NESTED"`            // want `env tag of NESTED is on a field of unsupported type \[\]\[\]string`
+	IntKeys  map[int]string             `env:"INT_KEYS"`          // want `env tag of INT_KEYS is on a field of unsupported type map\[int\]string`
+	Secrets  []envstruct.Secret[string] `env:"SECRETS"`           // want `env tag of SECRETS is on a field of unsupported type \[\]github.com/jha-captech/env-vars-to-struct.Secret\[string\]`
+	Size     string                     `env:"SIZE,quantity"`     // want `env tag of SIZE is on a field of unsupported type string`
+	Endpoint string                     `env:"ENDPOINT,parseurl"` // want `env tag of ENDPOINT is on a field of unsupported type string`
+	Database struct {
+		Host string `env:"DB_HOST"`
+	} `env:"DATABASE"` // want `env tag of DATABASE on a struct field is ignored, tag its fields or use parseurl`
+}
+
+type Defaults struct {
+	Port     int           `env:"PORT" default:"80a"`                 // want `default "80a" of PORT does not parse as int: invalid syntax`
+	Big      int           `env:"BIG" default:"99999999999999999999"` // want `default "99999999999999999999" of BIG does not parse as int: value out of range`
+	Debug    bool          `env:"DEBUG" default:"yes"`                // want `default "yes" of DEBUG does not parse as bool: invalid syntax`
+	Timeout  time.Duration `env:"TIMEOUT" default:"30"`               // want `default "30" of TIMEOUT does not parse as time.Duration: time: missing unit in duration "30"`
+	Interval time.Duration `env:"INTERVAL" default:"1 h"`             // want `default "1 h" of INTERVAL does not parse as time.Duration: time: unknown unit " h" in duration "1 h"`
+	Empty    int           `env:"EMPTY" default:""`
+}
+
+type Unexported struct {
+	host     string `env:"HOST"`   // want `env tag on unexported field host is ignored`
+	a, B     string `env:"A_OR_B"` // want `env tag on unexported field a is ignored`
+	internal struct {
+		Port int `env:"INTERNAL_PORT"`
+	}
+}
+
+type Duplicates struct {
+	Host     string `env:"HOST"`
+	Other    string `env:"HOST"` // want `env key HOST of Duplicates.Other is also bound by Host`
+	Database struct {
+		Host string `env:"HOST"` // want `env key HOST of Duplicates.Database.Host is also bound by Host`
+	}
+}
+
+type Server struct {
+	Host string `env:"HOST"`
+}
+
+type SharedDuplicates struct { // want `env key HOST of SharedDuplicates.Server.Host is also bound by Host`
+	Host   string `env:"HOST"`
+	Server Server
+}
+
+func local() {
+	var cfg struct {
+		Port float64 `env:"PORT"` // want `env tag of PORT is on a field of unsupported type float64`
+	}
+	_ = cfg
+}
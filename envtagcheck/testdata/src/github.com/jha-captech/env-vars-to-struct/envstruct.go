@@ -0,0 +1,11 @@
+// Package envstruct is a stub of the root package with the types the analyzer knows about.
+package envstruct
+
+// Secret is a value that is never printed.
+type Secret[T any] struct {
+	value T
+}
+
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+// Package envtest sets environment variables for tests of config structs, restoring them when the
+// test finishes:
+//
+//	func TestConfig(t *testing.T) {
+//		envtest.Set(t, map[string]string{"PORT": "8080", "DEBUG": "true"})
+//		envtest.SetFromStruct(t, Config{Host: "db"}, envtest.WithPrefix("APP_"))
+//		...
+//	}
+//
+// Variables are set with `t.Setenv`, so they are restored after the test and its subtests, and the
+// helpers can not be used in parallel tests or tests with parallel ancestors.
+//
+// Golden compares the effective config of a test with a checked in golden file.
+package envtest
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// Option configures Set, SetFromStruct and Unset.
+type Option func(*options)
+
+type options struct {
+	prefix string
+}
+
+// WithPrefix prepends `prefix` to every key, e.g. `APP_` sets `PORT` as `APP_PORT`.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Set sets every key of `values` to its value for the rest of the test.
+func Set(t testing.TB, values map[string]string, opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+	for key, value := range values {
+		t.Setenv(o.prefix+key, value)
+	}
+}
+
+// Unset unsets `keys` for the rest of the test, for testing missing variables regardless of the
+// environment the tests run in.
+func Unset(t testing.TB, keys []string, opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+	for _, key := range keys {
+		// Setenv registers the restore of the current value
+		t.Setenv(o.prefix+key, "")
+		if err := os.Unsetenv(o.prefix + key); err != nil {
+			t.Fatalf("envtest: unsetting %s: %v", o.prefix+key, err)
+		}
+	}
+}
+
+// SetFromStruct sets the key of every field of `cfg` with an `env` tag to the value of the field as
+// returned by `envstruct.Marshal`, so that parsing it gives the same value. Fields with the zero
+// value are not set, so their defaults apply when the config is parsed; use Set or Unset for blank
+// or missing variables. Secret fields are set to their actual value.
+//
+// Fields of types that can not be formatted fail the test.
+func SetFromStruct(t testing.TB, cfg any, opts ...Option) {
+	t.Helper()
+	val := reflect.ValueOf(cfg)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		t.Fatalf("envtest: SetFromStruct needs a struct or pointer to a struct, got %T", cfg)
+	}
+	values, err := envstruct.Marshal(cfg, envstruct.WithOmitZero())
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+	Set(t, values, opts...)
+}
@@ -0,0 +1,95 @@
+package envtest_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/envtest"
+)
+
+type database struct {
+	Scheme string
+	Host   string
+	Port   int
+	Path   string
+}
+
+type config struct {
+	Port     int                      `env:"ENVTEST_PORT" default:"8080"`
+	Debug    bool                     `env:"ENVTEST_DEBUG"`
+	Timeout  time.Duration            `env:"ENVTEST_TIMEOUT"`
+	Hosts    []string                 `env:"ENVTEST_HOSTS"`
+	Password envstruct.Secret[string] `env:"ENVTEST_PASSWORD"`
+	DB       database                 `env:"ENVTEST_DB_URL,parseurl"`
+	Nested   struct {
+		Name string `env:"ENVTEST_NAME"`
+	}
+}
+
+func TestSet(t *testing.T) {
+	envtest.Set(t, map[string]string{"PORT": "9090", "DEBUG": "true"}, envtest.WithPrefix("ENVTEST_"))
+	var cfg config
+	if _, err := envstruct.Parse(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 9090 || !cfg.Debug {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestUnset(t *testing.T) {
+	t.Setenv("ENVTEST_PORT", "9090")
+	t.Run("unset", func(t *testing.T) {
+		envtest.Unset(t, []string{"ENVTEST_PORT"})
+		if _, ok := os.LookupEnv("ENVTEST_PORT"); ok {
+			t.Error("ENVTEST_PORT is still set")
+		}
+	})
+	if got := os.Getenv("ENVTEST_PORT"); got != "9090" {
+		t.Errorf("got ENVTEST_PORT %q after the subtest, want it restored", got)
+	}
+}
+
+func TestSetFromStruct(t *testing.T) {
+	want := config{
+		Debug:    true,
+		Timeout:  1500 * time.Millisecond,
+		Hosts:    []string{"a", "b"},
+		Password: envstruct.NewSecret("hunter2"),
+		DB:       database{Scheme: "postgres", Host: "db.internal", Port: 5432, Path: "orders"},
+	}
+	want.Nested.Name = "orders"
+	envtest.Unset(t, []string{"ENVTEST_PORT"})
+	envtest.SetFromStruct(t, want)
+
+	if _, ok := os.LookupEnv("ENVTEST_PORT"); ok {
+		t.Error("a zero field was set")
+	}
+	var got config
+	if _, err := envstruct.Parse(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Password.Value() != "hunter2" {
+		t.Errorf("got password %q, want the actual value", got.Password.Value())
+	}
+	// the zero port is not set, so its default applies
+	want.Port = 8080
+	got.Password, want.Password = envstruct.Secret[string]{}, envstruct.Secret[string]{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSetFromStructPrefix(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	cfg.Port = 9090
+	envtest.SetFromStruct(t, &cfg, envtest.WithPrefix("ENVTEST_"))
+	if got := os.Getenv("ENVTEST_PORT"); got != "9090" {
+		t.Errorf("got ENVTEST_PORT %q, want %q", got, "9090")
+	}
+}
@@ -0,0 +1,84 @@
+package envtest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// Golden compares the `envstruct.Snapshot` of `cfg` with the golden file `name`, and fails the test
+// with the lines that differ if they are not equal:
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+//	func TestConfig(t *testing.T) {
+//		var cfg Config
+//		if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+//			t.Fatal(err)
+//		}
+//		envtest.Golden(t, "testdata/config.golden", &cfg)
+//	}
+//
+// Run the tests with `-update` to write the current snapshot to the file instead, then review the
+// change like any other diff. Golden looks the `update` flag up when it is called rather than
+// defining it, so it does not clash with the golden files of other libraries; the test package
+// defines it, as above.
+func Golden(t testing.TB, name string, cfg any, opts ...envstruct.Option) {
+	t.Helper()
+	got := envstruct.Snapshot(cfg, opts...)
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+		if err := os.WriteFile(name, got, 0o644); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("envtest: %v (run with -update to create it)", err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	t.Errorf("config does not match golden file %s (run with -update to accept it):\n%s", name,
+		goldenDiff(string(want), string(got)))
+}
+
+// updateGolden reports whether the `update` flag is defined and set.
+func updateGolden() bool {
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	update, err := strconv.ParseBool(f.Value.String())
+	return err == nil && update
+}
+
+// goldenDiff returns the lines of `want` missing from `got` prefixed with `-` and the lines of
+// `got` missing from `want` prefixed with `+`. Snapshot lines are sorted and keys are unique, so
+// this is the same as a line diff.
+func goldenDiff(want, got string) string {
+	wantLines := strings.SplitAfter(want, "\n")
+	gotLines := strings.SplitAfter(got, "\n")
+	var sb strings.Builder
+	for _, line := range wantLines {
+		if line != "" && !slices.Contains(gotLines, line) {
+			sb.WriteString("- " + strings.TrimSuffix(line, "\n") + "\n")
+		}
+	}
+	for _, line := range gotLines {
+		if line != "" && !slices.Contains(wantLines, line) {
+			sb.WriteString("+ " + strings.TrimSuffix(line, "\n") + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package envtest_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/envtest"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type goldenConfig struct {
+	Port     int                      `env:"PORT"`
+	Host     string                   `env:"HOST"`
+	Password envstruct.Secret[string] `env:"DB_PASSWORD"`
+}
+
+func TestGolden(t *testing.T) {
+	cfg := goldenConfig{Port: 8080, Host: "db.internal", Password: envstruct.NewSecret("hunter2")}
+	envtest.Golden(t, "testdata/config.golden", &cfg)
+}
+
+// recorder records the failures of a test instead of failing it.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	defer func(value bool) {
+		*update = value
+	}(*update)
+	*update = false
+	cfg := goldenConfig{Port: 9090, Host: "db.internal", Password: envstruct.NewSecret("hunter2")}
+	r := &recorder{TB: t}
+	envtest.Golden(r, "testdata/config.golden", &cfg)
+	if len(r.failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(r.failures))
+	}
+	for _, line := range []string{"- PORT=8080", "+ PORT=9090"} {
+		if !strings.Contains(r.failures[0], line) {
+			t.Errorf("failure %q does not contain %q", r.failures[0], line)
+		}
+	}
+	if strings.Contains(r.failures[0], "hunter2") {
+		t.Error("the failure shows the secret")
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	defer func(value bool) {
+		*update = value
+	}(*update)
+	name := filepath.Join(t.TempDir(), "nested", "config.golden")
+	cfg := goldenConfig{Port: 9090}
+
+	*update = true
+	envtest.Golden(t, name, &cfg)
+	if _, err := os.Stat(name); err != nil {
+		t.Fatal(err)
+	}
+	*update = false
+	envtest.Golden(t, name, &cfg)
+}
@@ -0,0 +1,144 @@
+package envstruct
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reasons used by `FieldError` to describe why a field could not be set.
+const (
+	ReasonMissing = "missing"
+	ReasonParse   = "parse"
+	ReasonSource  = "source"
+	// ReasonValidation is used for values that were set but failed a `validate` rule.
+	ReasonValidation = "validation"
+	// ReasonTag is used by Compile for fields whose tags can never be satisfied.
+	ReasonTag = "tag"
+)
+
+// FieldError describes a single field that could not be set from its environment variable.
+type FieldError struct {
+	// Field is the dotted path of the field within the top level struct, e.g. `Text.IntValue`.
+	Field string `json:"field"`
+	// Key is the environment variable the field is bound to.
+	Key string `json:"key"`
+	// Reason is one of the `Reason` constants.
+	Reason string `json:"reason"`
+	// Hint is a short, human-readable suggestion for fixing the problem.
+	Hint string `json:"hint,omitempty"`
+	// Err is the underlying error.
+	Err error `json:"-"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON renders the error with its message included under `message`.
+func (e *FieldError) MarshalJSON() ([]byte, error) {
+	type fieldError FieldError
+	return json.Marshal(struct {
+		*fieldError
+		Message string `json:"message"`
+	}{
+		fieldError: (*fieldError)(e),
+		Message:    e.Error(),
+	})
+}
+
+// ParseErrors holds every field that failed while parsing a struct.
+type ParseErrors []*FieldError
+
+func (errs ParseErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs ParseErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+// MarshalJSON renders the errors as `{"errors": [...]}` so that CI pipelines and orchestrators can
+// consume validation results programmatically.
+func (errs ParseErrors) MarshalJSON() ([]byte, error) {
+	fieldErrs := []*FieldError(errs)
+	if fieldErrs == nil {
+		fieldErrs = []*FieldError{}
+	}
+	return json.Marshal(struct {
+		Errors []*FieldError `json:"errors"`
+	}{
+		Errors: fieldErrs,
+	})
+}
+
+// ErrorJSON renders an error returned by ParseStructFromEnv as JSON. If `err` does not wrap a
+// `ParseErrors` value it is reported as a single error with only a message.
+func ErrorJSON(err error) ([]byte, error) {
+	var errs ParseErrors
+	if errors.As(err, &errs) {
+		return json.Marshal(errs)
+	}
+	return json.Marshal(struct {
+		Errors []map[string]string `json:"errors"`
+	}{
+		Errors: []map[string]string{{"message": err.Error()}},
+	})
+}
+
+// envVarError is the error returned by the getEnv helpers. The parts of the message are kept
+// separate so the reason can be reported in structured output.
+type envVarError struct {
+	reason   string
+	key      string
+	typeName string
+	err      error
+	// templates are the templates of the message, or nil for the default ones.
+	templates *ErrorTemplates
+}
+
+func (e *envVarError) Error() string {
+	return formatMessage(e.templates, e.reason, MessageData{Key: e.key, Type: e.typeName, Err: e.err})
+}
+
+func (e *envVarError) Unwrap() error {
+	return e.err
+}
+
+// newFieldError returns the error of the field at `field`. The message of `err` is rendered with
+// `templates` if it is an error of the getEnv helpers.
+func newFieldError(field, key string, err error, templates *ErrorTemplates) *FieldError {
+	fieldErr := &FieldError{
+		Field:  field,
+		Key:    key,
+		Reason: ReasonParse,
+		Err:    err,
+	}
+	var envErr *envVarError
+	if errors.As(err, &envErr) {
+		envErr.templates = templates
+		fieldErr.Reason = envErr.reason
+		switch envErr.reason {
+		case ReasonMissing:
+			fieldErr.Hint = fmt.Sprintf("set '%s' to a non-empty value", key)
+		case ReasonParse:
+			fieldErr.Hint = fmt.Sprintf("'%s' must be a valid %s", key, envErr.typeName)
+		case ReasonTag:
+			fieldErr.Hint = fmt.Sprintf("fix the tags of field '%s'", field)
+		}
+	}
+	return fieldErr
+}
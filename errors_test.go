@@ -0,0 +1,81 @@
+package envstruct_test
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestParseErrors(t *testing.T) {
+	t.Setenv("PORT", "abc")
+	var cfg struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}
+	err := envstruct.ParseStructFromEnv(&cfg, true)
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want ParseErrors", err)
+	}
+	want := []envstruct.FieldError{
+		{Field: "Port", Key: "PORT", Reason: envstruct.ReasonParse, Hint: "'PORT' must be a valid int64"},
+		{Field: "Host", Key: "HOST", Reason: envstruct.ReasonMissing, Hint: "set 'HOST' to a non-empty value"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want every failed field: %v", len(errs), errs)
+	}
+	for i, fieldErr := range errs {
+		got := envstruct.FieldError{Field: fieldErr.Field, Key: fieldErr.Key, Reason: fieldErr.Reason, Hint: fieldErr.Hint}
+		if got != want[i] {
+			t.Errorf("got error %+v, want %+v", got, want[i])
+		}
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	var cfg struct {
+		Host string `env:"ENVSTRUCT_TEST_UNSET_HOST"`
+	}
+	err := envstruct.ParseStructFromEnv(&cfg, true)
+	data, jsonErr := envstruct.ErrorJSON(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	var got struct {
+		Errors []map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"field":   "Host",
+		"key":     "ENVSTRUCT_TEST_UNSET_HOST",
+		"reason":  "missing",
+		"hint":    "set 'ENVSTRUCT_TEST_UNSET_HOST' to a non-empty value",
+		"message": "environment variable 'ENVSTRUCT_TEST_UNSET_HOST' is missing or blank",
+	}
+	if len(got.Errors) != 1 || !maps.Equal(got.Errors[0], want) {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestErrorJSONOtherErrors(t *testing.T) {
+	data, err := envstruct.ErrorJSON(errors.New("no config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"errors":[{"message":"no config"}]}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	data, err = json.Marshal(envstruct.ParseErrors(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"errors":[]}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,171 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// EtcdConfig configures the Etcd source.
+type EtcdConfig struct {
+	// Endpoint is the base URL of an etcd member, e.g. `https://etcd-0:2379`.
+	Endpoint string
+	// Prefix scopes the keys that are read. Keys are made relative to it before being flattened.
+	// It is treated as a directory, so `/my-service` does not match keys under `/my-service2/`.
+	Prefix string
+	// Username and Password are used to authenticate when etcd auth is enabled.
+	Username string
+	Password string
+	// CAFile, CertFile and KeyFile configure TLS. CertFile and KeyFile enable client certificate
+	// authentication.
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// TLSConfig is used as the base TLS configuration if set.
+	TLSConfig *tls.Config
+}
+
+// Etcd returns a source that reads every key under a prefix from etcd v3, using the JSON gateway
+// that etcd serves on its client port. Keys are made relative to the prefix and flattened like
+// config files, so `/my-service/db/host` under the prefix `/my-service/` is resolved as `DB_HOST`.
+//
+// All keys are fetched with a single range request the first time a key is looked up.
+func Etcd(cfg EtcdConfig) Source {
+	name := "etcd:" + strings.TrimSuffix(cfg.Endpoint, "/") + "/" + strings.TrimPrefix(cfg.Prefix, "/")
+	// a range request matches any key starting with the prefix, so end it with a separator to
+	// leave out sibling keys
+	prefix := cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return newMapSource(name, func(ctx context.Context) (map[string]string, error) {
+		tlsConfig, err := cfg.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		client := newHTTPClient(tlsConfig)
+
+		token, err := cfg.authenticate(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			KVs []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			} `json:"kvs"`
+		}
+		if err := cfg.post(ctx, client, "/v3/kv/range", token, map[string]string{
+			"key":       base64.StdEncoding.EncodeToString([]byte(prefix)),
+			"range_end": base64.StdEncoding.EncodeToString(prefixRangeEnd(prefix)),
+		}, &resp); err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]string)
+		for _, kv := range resp.KVs {
+			key, err := base64.StdEncoding.DecodeString(kv.Key)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding key: %w", err)
+			}
+			value, err := base64.StdEncoding.DecodeString(kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding value of '%s': %w", key, err)
+			}
+			relKey := strings.TrimPrefix(string(key), prefix)
+			if flatKey := flattenPath(relKey, "/", defaultKeySeparator); flatKey != "" {
+				values[flatKey] = string(value)
+			}
+		}
+		return values, nil
+	})
+}
+
+// authenticate returns an auth token if a username is configured.
+func (cfg EtcdConfig) authenticate(ctx context.Context, client *http.Client) (string, error) {
+	if cfg.Username == "" {
+		return "", nil
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := cfg.post(ctx, client, "/v3/auth/authenticate", "", map[string]string{
+		"name":     cfg.Username,
+		"password": cfg.Password,
+	}, &resp); err != nil {
+		return "", fmt.Errorf("error authenticating with etcd: %w", err)
+	}
+	return resp.Token, nil
+}
+
+func (cfg EtcdConfig) post(ctx context.Context, client *http.Client, path, token string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	found, err := doJSONRequest(client, req, out)
+	if err == nil && !found {
+		err = fmt.Errorf("etcd endpoint '%s' not found", req.URL.Redacted())
+	}
+	return err
+}
+
+func (cfg EtcdConfig) tlsConfig() (*tls.Config, error) {
+	if cfg.TLSConfig == nil && cfg.CAFile == "" && cfg.CertFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in '%s'", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// prefixRangeEnd returns the end of the etcd key range that covers every key starting with
+// `prefix`.
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// the prefix is empty or all 0xff bytes, so the range covers every key
+	return []byte{0}
+}
@@ -0,0 +1,124 @@
+package envstruct_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestEtcd(t *testing.T) {
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			if body["name"] != "app" || body["password"] != "hunter2" {
+				http.Error(w, "invalid credentials", http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "auth-token"})
+		case "/v3/kv/range":
+			if r.Header.Get("Authorization") != "auth-token" {
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+			// the range covers every key starting with the prefix
+			if body["key"] != b64("/my-service/") || body["range_end"] != b64("/my-service0") {
+				t.Errorf("got range %q", body)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"kvs": []map[string]string{
+				{"key": b64("/my-service/db/host"), "value": b64("db.internal")},
+				{"key": b64("/my-service/db/port"), "value": b64("5432")},
+				{"key": b64("/my-service/log-level"), "value": b64("debug")},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	source := envstruct.Etcd(envstruct.EtcdConfig{
+		Endpoint:  server.URL,
+		Prefix:    "/my-service/",
+		Username:  "app",
+		Password:  "hunter2",
+		TLSConfig: &tls.Config{RootCAs: pool},
+	})
+	var cfg remoteConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432, Level: "debug"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	source = envstruct.Etcd(envstruct.EtcdConfig{
+		Endpoint:  server.URL,
+		Prefix:    "/my-service/",
+		Username:  "app",
+		Password:  "wrong",
+		TLSConfig: &tls.Config{RootCAs: pool},
+	})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err == nil {
+		t.Error("expected an error for invalid credentials")
+	}
+}
+
+func TestEtcdPrefixSiblings(t *testing.T) {
+	kvs := map[string]string{
+		"/app/db/host":  "db.internal",
+		"/app2/db/host": "db2.internal",
+		"/app2/region":  "eu-west-1",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		start, _ := base64.StdEncoding.DecodeString(body["key"])
+		end, _ := base64.StdEncoding.DecodeString(body["range_end"])
+		// serve the keys in the range like etcd does
+		var resp []map[string]string
+		for key, value := range kvs {
+			if key >= string(start) && key < string(end) {
+				resp = append(resp, map[string]string{
+					"key":   base64.StdEncoding.EncodeToString([]byte(key)),
+					"value": base64.StdEncoding.EncodeToString([]byte(value)),
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"kvs": resp})
+	}))
+	defer server.Close()
+
+	var cfg struct {
+		Host   string `env:"DB_HOST"`
+		Region string `env:"REGION"`
+	}
+	source := envstruct.Etcd(envstruct.EtcdConfig{Endpoint: server.URL, Prefix: "/app"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Region != "" {
+		t.Errorf("got %+v, want only the keys under /app/", cfg)
+	}
+	// the keys of /app2 would otherwise be made relative to /app
+	for _, key := range []string{"2_DB_HOST", "2_REGION"} {
+		if value, ok := lookup(t, source, key); ok {
+			t.Errorf("got %s=%q from a sibling of the prefix", key, value)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Command example parses a config struct from the environment with ParseStructFromEnv.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type ConfigCustom struct {
+	Env  string `env:"ENV"`
+	Text struct {
+		TextValue string `env:"TEXT_VALUE"`
+		BoolValue bool   `env:"BOOL_VALUE"`
+		IntValue  int    `env:"INT_VALUE"`
+	}
+}
+
+func main() {
+	_ = os.Setenv("ENV", "dev")
+	_ = os.Setenv("TEXT_VALUE", "this is text")
+	_ = os.Setenv("BOOL_VALUE", strconv.FormatBool(true))
+	_ = os.Setenv("INT_VALUE", strconv.FormatInt(50, 10))
+
+	config := ConfigCustom{}
+	if err := envstruct.ParseStructFromEnv(&config, true); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(fmt.Sprintf("%+v", config))
+}
@@ -0,0 +1,110 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultExecTimeout bounds commands run for the `exec` tag option when WithExec is given no
+// timeout.
+const defaultExecTimeout = 10 * time.Second
+
+// WithExec enables the `exec` tag option, which sets a field to the output of a command instead of
+// resolving it from the source chain:
+//
+//	Token string `env:"TOKEN,exec=op read op://vault/item/token"`
+//
+// This supports secret brokers such as the 1Password CLI or `gcloud auth print-access-token`.
+// The command is split into arguments like a shell would split it, honoring single and double
+// quotes, but is not run by a shell. Its standard output, with trailing newlines removed, becomes
+// the value. Commands that exit with an error or run longer than `timeout` fail the field. A
+// timeout of zero uses a default of 10 seconds.
+//
+// Running commands from struct tags is disabled unless this option is given, and fields with the
+// `exec` option fail without it. Commands can not contain commas, since commas separate tag
+// options.
+func WithExec(timeout time.Duration) Option {
+	return func(o *options) {
+		o.execEnabled = true
+		o.execTimeout = timeout
+	}
+}
+
+// execValue runs `command` and returns its output.
+func (o options) execValue(command string) (string, error) {
+	if !o.execEnabled {
+		return "", errors.New("the exec tag option is disabled, use WithExec to enable it")
+	}
+	args, err := splitCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", errors.New("the exec tag option has no command")
+	}
+
+	timeout := o.execTimeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(o.context(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("error running '%s': %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("error running '%s': %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// splitCommand splits `command` into arguments on whitespace, treating text in single or double
+// quotes as part of a single argument.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+	)
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in exec command")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package envstruct_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestExec(t *testing.T) {
+	var cfg struct {
+		Token  string `env:"TOKEN,exec=printf 'tok en\n\n'"`
+		Region string `env:"REGION,exec=echo \"eu west\""`
+	}
+	// fields with the exec option are not read from the source chain
+	source := sources.Static(map[string]string{"TOKEN": "from-env"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithExec(0)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token != "tok en" || cfg.Region != "eu west" {
+		t.Errorf("got %+v, want the output of the commands without trailing newlines", cfg)
+	}
+}
+
+func TestExecFails(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  any
+		opts []envstruct.Option
+	}{
+		{
+			name: "disabled",
+			cfg: &struct {
+				Token string `env:"TOKEN,exec=echo token"`
+			}{},
+		},
+		{
+			name: "exit status",
+			cfg: &struct {
+				Token string `env:"TOKEN,exec=false"`
+			}{},
+			opts: []envstruct.Option{envstruct.WithExec(0)},
+		},
+		{
+			name: "unterminated quote",
+			cfg: &struct {
+				Token string `env:"TOKEN,exec=echo 'token"`
+			}{},
+			opts: []envstruct.Option{envstruct.WithExec(0)},
+		},
+		{
+			name: "timeout",
+			cfg: &struct {
+				Token string `env:"TOKEN,exec=sleep 5"`
+			}{},
+			opts: []envstruct.Option{envstruct.WithExec(50 * time.Millisecond)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]envstruct.Option{envstruct.WithSources(sources.Static(nil))}, tt.opts...)
+			_, err := envstruct.Parse(tt.cfg, opts...)
+			var fieldErr *envstruct.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("got error %v, want a field error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !envstruct_unsafe
+
+package envstruct
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// fastSetAvailable is false unless the `envstruct_unsafe` build tag is set, so fields are always
+// set through `reflect.Value`.
+const fastSetAvailable = false
+
+// setFieldFast sets the field of type `t` at `ptr` with setField. It is only called when built with
+// the `envstruct_unsafe` build tag.
+func setFieldFast(ptr unsafe.Pointer, t reflect.Type, key, value string, errIfMissing bool) (bool, error) {
+	return setField(reflect.NewAt(t, ptr).Elem(), key, value, errIfMissing)
+}
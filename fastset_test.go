@@ -0,0 +1,164 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fastPort int
+
+type fastFlag bool
+
+type fastName string
+
+type fastInner struct {
+	Host string   `env:"FAST_INNER_HOST"`
+	Port fastPort `env:"FAST_INNER_PORT"`
+	Deep struct {
+		Enabled fastFlag `env:"FAST_DEEP_ENABLED"`
+		Count   int      `env:"FAST_DEEP_COUNT"`
+	}
+}
+
+type fastConfig struct {
+	Name     fastName      `env:"FAST_NAME"`
+	Port     int           `env:"FAST_PORT"`
+	Debug    bool          `env:"FAST_DEBUG"`
+	Timeout  time.Duration `env:"FAST_TIMEOUT"`
+	Hosts    []string      `env:"FAST_HOSTS"`
+	Memory   int64         `env:"FAST_MEMORY,quantity"`
+	Schedule Schedule      `env:"FAST_SCHEDULE"`
+	// skipped and Padding move the offsets of the fields after them
+	skipped int
+	Inner   fastInner
+	Padding [3]byte
+	Last    int `env:"FAST_LAST"`
+}
+
+func fastValues(i int) map[string]string {
+	return map[string]string{
+		"FAST_NAME":         fmt.Sprintf("service-%d", i),
+		"FAST_PORT":         fmt.Sprint(8000 + i),
+		"FAST_DEBUG":        fmt.Sprint(i%2 == 0),
+		"FAST_TIMEOUT":      fmt.Sprintf("%ds", i),
+		"FAST_HOSTS":        fmt.Sprintf("a%d,b%d", i, i),
+		"FAST_MEMORY":       fmt.Sprintf("%dKi", i),
+		"FAST_SCHEDULE":     "*/5 * * * *",
+		"FAST_INNER_HOST":   fmt.Sprintf("db-%d.internal", i),
+		"FAST_INNER_PORT":   fmt.Sprint(5432 + i),
+		"FAST_DEEP_ENABLED": "true",
+		"FAST_DEEP_COUNT":   fmt.Sprint(-i),
+		"FAST_LAST":         fmt.Sprint(i * 3),
+	}
+}
+
+func TestFastKind(t *testing.T) {
+	fast := make(map[string]bool)
+	for _, pf := range typeFields(reflect.TypeFor[fastConfig]()) {
+		fast[pf.path] = pf.fast
+	}
+	want := map[string]bool{
+		"Name":               true,
+		"Port":               true,
+		"Debug":              true,
+		"Timeout":            false,
+		"Hosts":              false,
+		"Memory":             false,
+		"Schedule":           false,
+		"Inner.Host":         true,
+		"Inner.Port":         true,
+		"Inner.Deep.Enabled": true,
+		"Inner.Deep.Count":   true,
+		"Last":               true,
+	}
+	if !reflect.DeepEqual(fast, want) {
+		t.Errorf("got fast fields %v, want %v", fast, want)
+	}
+}
+
+// TestBindMatchesParse checks that Bind, which sets fields at their offsets when built with the
+// `envstruct_unsafe` build tag, sets the same values as Parse, which always uses reflection. Run
+// the tests with and without the tag to cover both.
+func TestBindMatchesParse(t *testing.T) {
+	t.Logf("fast set available: %v", fastSetAvailable)
+	binder, err := Compile[fastConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		values := fastValues(i)
+		lookup := func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		}
+		bound, err := binder.Bind(lookup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var parsed fastConfig
+		source := newMapSource("values", func(context.Context) (map[string]string, error) {
+			return values, nil
+		})
+		if _, err := Parse(&parsed, WithSources(source)); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(bound, parsed) {
+			t.Errorf("Bind set %+v, Parse set %+v", bound, parsed)
+		}
+		if bound.Inner.Port != fastPort(5432+i) || bound.Inner.Deep.Count != -i || bound.Last != i*3 {
+			t.Errorf("nested or trailing fields were not set: %+v", bound)
+		}
+	}
+}
+
+func TestBindConcurrently(t *testing.T) {
+	binder, err := Compile[fastConfig](WithConcurrency(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values := fastValues(i)
+			for range 20 {
+				cfg, err := binder.Bind(func(key string) (string, bool) {
+					value, ok := values[key]
+					return value, ok
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if cfg.Port != 8000+i || cfg.Inner.Host != fmt.Sprintf("db-%d.internal", i) || !cfg.Inner.Deep.Enabled {
+					t.Errorf("goroutine %d got %+v", i, cfg)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBindFastErrors(t *testing.T) {
+	binder, err := Compile[fastConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := fastValues(1)
+	values["FAST_INNER_PORT"] = "not a port"
+	_, err = binder.Bind(func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	})
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "Inner.Port" || errs[0].Reason != ReasonParse {
+		t.Errorf("got error %v, want a parse error for Inner.Port", err)
+	}
+}
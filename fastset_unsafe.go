@@ -0,0 +1,42 @@
+//go:build envstruct_unsafe
+
+package envstruct
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// fastSetAvailable reports whether binders created with Compile set string, int and bool fields
+// through pointers at precomputed offsets rather than through `reflect.Value`, which saves the
+// reflection overhead in reload heavy workloads. It is enabled with the `envstruct_unsafe` build
+// tag.
+const fastSetAvailable = true
+
+// setFieldFast works like setField for a field of type `t` at `ptr`, which must be a string, int or
+// bool kind that is not set with UnmarshalText.
+func setFieldFast(ptr unsafe.Pointer, t reflect.Type, key, value string, errIfMissing bool) (bool, error) {
+	switch t.Kind() {
+	case reflect.String:
+		converted, err := getEnvString(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		*(*string)(ptr) = converted
+	case reflect.Int:
+		converted, err := getEnvInt64(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		*(*int)(ptr) = int(converted)
+	case reflect.Bool:
+		converted, err := getEnvBool(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		*(*bool)(ptr) = converted
+	default:
+		return setField(reflect.NewAt(t, ptr).Elem(), key, value, errIfMissing)
+	}
+	return true, nil
+}
@@ -0,0 +1,164 @@
+package envstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultKeySeparator joins nested keys when config files are flattened.
+const defaultKeySeparator = "_"
+
+// JSONFile returns a source that reads a JSON object from the file `name` and flattens it into
+// environment variable style keys. Put it after Env in the chain to let a `config.json` file
+// provide defaults that environment variables override:
+//
+//	res, err := Parse(&cfg, WithSources(Env(), JSONFile("config.json")))
+//
+// Nested keys are upper cased and joined with a separator, which defaults to `_` and can be changed
+// with WithKeySeparator, so `{"database": {"host": "db"}}` is resolved as `DATABASE_HOST`. Arrays of
+// scalar values are joined with commas.
+//
+// The file is read as JSON whatever its extension, the first time a key is looked up. A missing
+// file is an error.
+func JSONFile(name string, opts ...Option) Source {
+	return configFileSource(name, "json", newOptions(opts))
+}
+
+func decodeJSON(data []byte, sep string) (map[string]string, error) {
+	// decode numbers as json.Number so large integers are not formatted as floats
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var obj map[string]any
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return flattenMap(obj, sep), nil
+}
+
+// YAMLFile returns a source that reads a YAML document from the file `name` and flattens it into
+// environment variable style keys the same way as JSONFile, so `database.host` is resolved as
+// `DATABASE_HOST`. Teams migrating from YAML config can put it after Env in the chain and move
+// values to the environment one at a time.
+//
+// The file is read as YAML whatever its extension, the first time a key is looked up. A missing
+// file is an error.
+func YAMLFile(name string, opts ...Option) Source {
+	return configFileSource(name, "yaml", newOptions(opts))
+}
+
+func decodeYAML(data []byte, sep string) (map[string]string, error) {
+	var obj map[string]any
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return flattenMap(obj, sep), nil
+}
+
+// TOMLFile returns a source that reads a TOML document from the file `name` and flattens it into
+// environment variable style keys. Tables are flattened the same way as nested objects in JSONFile,
+// so `host` in the `[database]` table is resolved as `DATABASE_HOST`.
+//
+// The file is read as TOML whatever its extension, the first time a key is looked up. A missing
+// file is an error.
+func TOMLFile(name string, opts ...Option) Source {
+	return configFileSource(name, "toml", newOptions(opts))
+}
+
+func decodeTOML(data []byte, sep string) (map[string]string, error) {
+	var obj map[string]any
+	if err := toml.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return flattenMap(obj, sep), nil
+}
+
+// configFormat returns the format of the config file `name` based on its extension: `json`,
+// `yaml`, `toml` or `dotenv` for any other extension.
+func configFormat(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return "dotenv"
+	}
+}
+
+// decodeConfig decodes config file content in one of the formats returned by configFormat.
+func decodeConfig(format string, data []byte, sep string) (map[string]string, error) {
+	switch format {
+	case "json":
+		return decodeJSON(data, sep)
+	case "yaml":
+		return decodeYAML(data, sep)
+	case "toml":
+		return decodeTOML(data, sep)
+	default:
+		return ReadDotEnv(bytes.NewReader(data))
+	}
+}
+
+// flattenMap flattens a decoded config document into environment variable style keys.
+func flattenMap(obj map[string]any, sep string) map[string]string {
+	values := make(map[string]string)
+	flattenValue(values, "", obj, sep)
+	return values
+}
+
+func flattenValue(values map[string]string, key string, value any, sep string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]any:
+		for childKey, child := range v {
+			flattenValue(values, joinFlatKey(key, childKey, sep), child, sep)
+		}
+	case []map[string]any:
+		for i, child := range v {
+			flattenValue(values, joinFlatKey(key, fmt.Sprint(i), sep), child, sep)
+		}
+	case []any:
+		if scalars, ok := joinScalars(v); ok {
+			values[key] = scalars
+			return
+		}
+		for i, child := range v {
+			flattenValue(values, joinFlatKey(key, fmt.Sprint(i), sep), child, sep)
+		}
+	default:
+		values[key] = fmt.Sprint(v)
+	}
+}
+
+// joinFlatKey appends `key` to `prefix`. Dots and dashes within `key` are treated as nesting, so
+// a `database.host` key is flattened the same way as a nested `host` key under `database`.
+func joinFlatKey(prefix, key, sep string) string {
+	key = strings.ToUpper(strings.NewReplacer(".", sep, "-", sep).Replace(key))
+	if prefix == "" {
+		return key
+	}
+	return prefix + sep + key
+}
+
+// joinScalars joins a list of scalar values with commas. It reports false if any item is a map or
+// a list.
+func joinScalars(list []any) (string, bool) {
+	items := make([]string, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case map[string]any, []any:
+			return "", false
+		}
+		items[i] = fmt.Sprint(item)
+	}
+	return strings.Join(items, ","), true
+}
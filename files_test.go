@@ -0,0 +1,164 @@
+package envstruct_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// writeFile writes `content` to the file `name` in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestJSONFile(t *testing.T) {
+	file := writeFile(t, "config", `{
+		"database": {"host": "db.internal", "port": 5432},
+		"log-level": "info",
+		"hosts": ["a", "b"],
+		"servers": [{"name": "primary"}],
+		"big": 12345678901234567890,
+		"unset": null
+	}`)
+	source := envstruct.JSONFile(file)
+	for key, want := range map[string]string{
+		"DATABASE_HOST":  "db.internal",
+		"DATABASE_PORT":  "5432",
+		"LOG_LEVEL":      "info",
+		"HOSTS":          "a,b",
+		"SERVERS_0_NAME": "primary",
+		"BIG":            "12345678901234567890",
+	} {
+		if got, _ := lookup(t, source, key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+	if _, ok := lookup(t, source, "UNSET"); ok {
+		t.Error("got a value for a null value")
+	}
+}
+
+func TestJSONFileFallback(t *testing.T) {
+	file := writeFile(t, "config.json", `{"database": {"host": "db.internal"}, "log-level": "info"}`)
+	unsetenv(t, "DATABASE_HOST")
+	t.Setenv("LOG_LEVEL", "debug")
+	var cfg struct {
+		Host  string `env:"DATABASE_HOST"`
+		Level string `env:"LOG_LEVEL"`
+	}
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Env(), envstruct.JSONFile(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Level != "debug" {
+		t.Errorf("got %+v, want the environment to override the file", cfg)
+	}
+	if got := res.Sources["Host"]; got != "file:"+file {
+		t.Errorf("got source %q for the value of the file, want %q", got, "file:"+file)
+	}
+	// the file is read as a source, not into the environment
+	if _, ok := os.LookupEnv("DATABASE_HOST"); ok {
+		t.Error("the value of the file was set in the environment")
+	}
+}
+
+func TestJSONFileKeySeparator(t *testing.T) {
+	file := writeFile(t, "config.json", `{"database": {"host": "db.internal"}}`)
+	source := envstruct.JSONFile(file, envstruct.WithKeySeparator("__"))
+	if got, _ := lookup(t, source, "DATABASE__HOST"); got != "db.internal" {
+		t.Errorf("got %q, want db.internal", got)
+	}
+}
+
+func TestJSONFileErrors(t *testing.T) {
+	for name, file := range map[string]string{
+		"missing file":  filepath.Join(t.TempDir(), "missing.json"),
+		"not an object": writeFile(t, "config.json", `["not", "an", "object"]`),
+	} {
+		if _, _, err := envstruct.JSONFile(file).Lookup(context.Background(), "KEY"); err == nil {
+			t.Errorf("expected an error for a %s", name)
+		}
+	}
+}
+
+func TestYAMLFile(t *testing.T) {
+	file := writeFile(t, "config", `
+database:
+  host: db.internal
+  port: 5432
+log.level: info
+hosts: [a, b]
+servers:
+  - name: primary
+    weights: [1, 2]
+`)
+	unsetenv(t, "DATABASE_HOST")
+	source := envstruct.YAMLFile(file)
+	for key, want := range map[string]string{
+		"DATABASE_HOST":     "db.internal",
+		"DATABASE_PORT":     "5432",
+		"LOG_LEVEL":         "info",
+		"HOSTS":             "a,b",
+		"SERVERS_0_NAME":    "primary",
+		"SERVERS_0_WEIGHTS": "1,2",
+	} {
+		if got, _ := lookup(t, source, key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+	if _, ok := os.LookupEnv("DATABASE_HOST"); ok {
+		t.Error("the value of the file was set in the environment")
+	}
+}
+
+func TestYAMLFileErrors(t *testing.T) {
+	source := envstruct.YAMLFile(writeFile(t, "config.yaml", "database: [unclosed"))
+	if _, _, err := source.Lookup(context.Background(), "DATABASE"); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestTOMLFile(t *testing.T) {
+	file := writeFile(t, "config", `
+log-level = "info"
+hosts = ["a", "b"]
+
+[database]
+host = "db.internal"
+port = 5432
+
+[[servers]]
+name = "primary"
+`)
+	unsetenv(t, "DATABASE_HOST")
+	source := envstruct.TOMLFile(file)
+	for key, want := range map[string]string{
+		"DATABASE_HOST":  "db.internal",
+		"DATABASE_PORT":  "5432",
+		"LOG_LEVEL":      "info",
+		"HOSTS":          "a,b",
+		"SERVERS_0_NAME": "primary",
+	} {
+		if got, _ := lookup(t, source, key); got != want {
+			t.Errorf("got %s=%q, want %q", key, got, want)
+		}
+	}
+	if _, ok := os.LookupEnv("DATABASE_HOST"); ok {
+		t.Error("the value of the file was set in the environment")
+	}
+}
+
+func TestTOMLFileErrors(t *testing.T) {
+	source := envstruct.TOMLFile(writeFile(t, "config.toml", "[database\nhost = 1"))
+	if _, _, err := source.Lookup(context.Background(), "DATABASE_HOST"); err == nil {
+		t.Error("expected an error for invalid TOML")
+	}
+}
@@ -0,0 +1,87 @@
+package envstruct
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Flags registers a flag on `fs` for every field of `obj` that has an `env` tag and returns a
+// source that yields the values of the flags that were set on the command line. Put the source
+// first in the chain so flags take precedence over the environment:
+//
+//	flags := Flags(flag.CommandLine, &cfg)
+//	flag.Parse()
+//	res, err := Parse(&cfg, WithSources(flags, Env()))
+//
+// Flag names are derived from the key by lower casing it and replacing underscores with dashes,
+// so `DB_HOST` becomes `-db-host`. The usage text comes from the field's `desc` tag and the
+// default shown is the field's current value. Flags for bool fields can be given without a value.
+// Fields that share a key share one flag, which is described by the first of them.
+func Flags(fs *flag.FlagSet, obj any) Source {
+	keys := make(map[string]string)
+	walkFields(structValue(obj), "", func(f boundField) {
+		if _, ok := keys[f.tag.key]; ok {
+			return
+		}
+		current, ok := formatValue(f.value)
+		if !ok {
+			return
+		}
+		name := FlagName(f.tag.key)
+		usage := strings.TrimSpace(fmt.Sprintf("%s (env %s)", f.field.Tag.Get("desc"), f.tag.key))
+		fs.Var(&flagValue{
+			value:  current,
+			isBool: f.value.Kind() == reflect.Bool,
+		}, name, usage)
+		keys[f.tag.key] = name
+	})
+
+	return &funcSource{
+		name: "flags",
+		lookup: func(_ context.Context, key string) (string, bool, error) {
+			name, ok := keys[key]
+			if !ok {
+				return "", false, nil
+			}
+			var value string
+			var set bool
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name == name {
+					value, set = f.Value.String(), true
+				}
+			})
+			return value, set, nil
+		},
+	}
+}
+
+// FlagName returns the flag name derived from the environment variable `key`.
+func FlagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// flagValue is a `flag.Value` that stores the raw flag text so it can be converted the same way
+// as values from any other source.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
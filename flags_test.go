@@ -0,0 +1,78 @@
+package envstruct_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type flagConfig struct {
+	Host  string `env:"DB_HOST" desc:"database host"`
+	Port  int    `env:"DB_PORT"`
+	Debug bool   `env:"DEBUG"`
+	Level string `env:"LOG_LEVEL"`
+}
+
+func TestFlagName(t *testing.T) {
+	if got := envstruct.FlagName("DB_HOST"); got != "db-host" {
+		t.Errorf("got %q, want %q", got, "db-host")
+	}
+}
+
+func TestFlags(t *testing.T) {
+	cfg := flagConfig{Port: 5432}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags := envstruct.Flags(fs, &cfg)
+
+	host := fs.Lookup("db-host")
+	if host == nil || host.Usage != "database host (env DB_HOST)" {
+		t.Fatalf("got flag %+v, want db-host with the desc tag as usage", host)
+	}
+	if port := fs.Lookup("db-port"); port == nil || port.DefValue != "5432" {
+		t.Errorf("got flag %+v, want the current value as default", port)
+	}
+
+	if err := fs.Parse([]string{"-db-host", "flag.internal", "-debug"}); err != nil {
+		t.Fatal(err)
+	}
+	env := sources.Static(map[string]string{"DB_HOST": "env.internal", "LOG_LEVEL": "info"})
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(flags, env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (flagConfig{Host: "flag.internal", Port: 5432, Debug: true, Level: "info"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if res.Sources["Host"] != "flags" || res.Sources["Level"] != "static" {
+		t.Errorf("got sources %v, want flags to take precedence only when set", res.Sources)
+	}
+}
+
+func TestFlagsSharedKey(t *testing.T) {
+	var cfg struct {
+		Host    string `env:"DB_HOST" desc:"database host"`
+		Replica struct {
+			Host string `env:"DB_HOST"`
+		}
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags := envstruct.Flags(fs, &cfg)
+	if host := fs.Lookup("db-host"); host == nil || host.Usage != "database host (env DB_HOST)" {
+		t.Fatalf("got flag %+v, want one db-host flag described by the first field", host)
+	}
+
+	if err := fs.Parse([]string{"-db-host", "flag.internal"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(flags)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "flag.internal" || cfg.Replica.Host != "flag.internal" {
+		t.Errorf("got %+v, want the flag to set both fields", cfg)
+	}
+}
@@ -0,0 +1,146 @@
+package envstruct
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// gcpSecretManagerEndpoint is the default Secret Manager API endpoint.
+const gcpSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+
+// GCPSecretManagerConfig configures the GCPSecretManager source. Secrets can be mapped to keys
+// individually with Secrets, by prefix with Project and Prefix, or both.
+type GCPSecretManagerConfig struct {
+	// Secrets maps keys to secret version resource names, e.g.
+	// `"DB_PASSWORD": "projects/x/secrets/db-password/versions/latest"`.
+	Secrets map[string]string
+	// Project enables prefix mode: the latest version of every secret in the project whose ID
+	// starts with Prefix is read. The rest of the secret ID is flattened into the key, so the
+	// secret `my-service-db-password` with the prefix `my-service-` is resolved as `DB_PASSWORD`.
+	Project string
+	Prefix  string
+	// TokenSource authenticates requests. If nil, Application Default Credentials are used.
+	TokenSource oauth2.TokenSource
+	// Endpoint overrides the Secret Manager API endpoint, e.g. for a regional endpoint.
+	Endpoint string
+}
+
+// GCPSecretManager returns a source that reads secrets from Google Cloud Secret Manager using its
+// REST API. Secrets are read the first time a key is looked up.
+func GCPSecretManager(cfg GCPSecretManagerConfig) Source {
+	name := "gcpsecretmanager"
+	if cfg.Project != "" {
+		name += ":projects/" + cfg.Project + "/secrets/" + cfg.Prefix
+	}
+	return newMapSource(name, func(ctx context.Context) (map[string]string, error) {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		tokenSource := cfg.TokenSource
+		if tokenSource == nil {
+			var err error
+			tokenSource, err = google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+			if err != nil {
+				return nil, fmt.Errorf("error finding default credentials: %w", err)
+			}
+		}
+		client := oauth2.NewClient(ctx, tokenSource)
+		client.Timeout = remoteTimeout
+
+		secrets := make(map[string]string)
+		if cfg.Project != "" {
+			prefixed, err := cfg.listSecrets(ctx, client)
+			if err != nil {
+				return nil, err
+			}
+			for key, version := range prefixed {
+				secrets[key] = version
+			}
+		}
+		for key, version := range cfg.Secrets {
+			secrets[key] = version
+		}
+
+		values := make(map[string]string)
+		for key, version := range secrets {
+			var resp struct {
+				Payload struct {
+					Data string `json:"data"`
+				} `json:"payload"`
+			}
+			found, err := cfg.get(ctx, client, "/v1/"+version+":access", &resp)
+			if err != nil {
+				return nil, fmt.Errorf("error accessing secret '%s': %w", version, err)
+			}
+			if !found {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding secret '%s': %w", version, err)
+			}
+			values[key] = string(data)
+		}
+		return values, nil
+	})
+}
+
+// listSecrets returns the latest version resource name of every secret in the project that
+// matches the prefix, keyed by the flattened remainder of the secret ID.
+func (cfg GCPSecretManagerConfig) listSecrets(ctx context.Context, client *http.Client) (map[string]string, error) {
+	secrets := make(map[string]string)
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {"250"}}
+		if cfg.Prefix != "" {
+			query.Set("filter", "name:"+cfg.Prefix)
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var resp struct {
+			Secrets []struct {
+				Name string `json:"name"`
+			} `json:"secrets"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		path := "/v1/projects/" + cfg.Project + "/secrets?" + query.Encode()
+		if _, err := cfg.get(ctx, client, path, &resp); err != nil {
+			return nil, fmt.Errorf("error listing secrets: %w", err)
+		}
+
+		for _, secret := range resp.Secrets {
+			// the filter matches anywhere in the name, so check the prefix of the ID
+			id := secret.Name[strings.LastIndex(secret.Name, "/")+1:]
+			rest, ok := strings.CutPrefix(id, cfg.Prefix)
+			if !ok || rest == "" {
+				continue
+			}
+			secrets[joinFlatKey("", rest, defaultKeySeparator)] = secret.Name + "/versions/latest"
+		}
+
+		if resp.NextPageToken == "" {
+			return secrets, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func (cfg GCPSecretManagerConfig) get(ctx context.Context, client *http.Client, path string, out any) (bool, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = gcpSecretManagerEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+path, nil)
+	if err != nil {
+		return false, err
+	}
+	return doJSONRequest(client, req, out)
+}
@@ -0,0 +1,84 @@
+package envstruct_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestGCPSecretManager(t *testing.T) {
+	secrets := map[string]string{
+		"projects/p/secrets/my-service-db-password": "hunter2",
+		"projects/p/secrets/my-service-api-key":     "abc123",
+		"projects/p/secrets/other-my-service-key":   "not matched",
+		"projects/p/secrets/shared-host":            "db.internal",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/v1/projects/p/secrets" {
+			if r.URL.Query().Get("filter") != "name:my-service-" {
+				t.Errorf("got filter %q", r.URL.Query().Get("filter"))
+			}
+			// one secret per page
+			names := []string{
+				"projects/p/secrets/my-service-db-password",
+				"projects/p/secrets/other-my-service-key",
+				"projects/p/secrets/my-service-api-key",
+			}
+			page := 0
+			if token := r.URL.Query().Get("pageToken"); token != "" {
+				page = int(token[0] - '0')
+			}
+			resp := map[string]any{"secrets": []map[string]string{{"name": names[page]}}}
+			if page+1 < len(names) {
+				resp["nextPageToken"] = string(rune('0' + page + 1))
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), "/versions/latest:access")
+		secret, found := secrets[name]
+		if !ok || !found {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(secret))},
+		})
+	}))
+	defer server.Close()
+
+	source := envstruct.GCPSecretManager(envstruct.GCPSecretManagerConfig{
+		Project: "p",
+		Prefix:  "my-service-",
+		Secrets: map[string]string{
+			"DB_HOST":    "projects/p/secrets/shared-host/versions/latest",
+			"DB_MISSING": "projects/p/secrets/missing/versions/latest",
+		},
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"}),
+		Endpoint:    server.URL,
+	})
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Password string `env:"DB_PASSWORD"`
+		APIKey   string `env:"API_KEY"`
+		Key      string `env:"KEY"`
+		Missing  string `env:"DB_MISSING"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Password != "hunter2" || cfg.APIKey != "abc123" || cfg.Key != "" || cfg.Missing != "" {
+		t.Errorf("got %+v", cfg)
+	}
+}
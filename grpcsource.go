@@ -0,0 +1,158 @@
+package envstruct
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+
+	"github.com/jha-captech/env-vars-to-struct/configpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GRPCConfig configures the GRPC source.
+type GRPCConfig struct {
+	// Target is the address of the config service, e.g. `dns:///config.internal:443`.
+	Target string
+	// DialOptions are used to connect to Target. If empty, TLS with the system roots is used.
+	DialOptions []grpc.DialOption
+	// Conn is used instead of connecting to Target if set.
+	Conn grpc.ClientConnInterface
+	// Namespace selects the values to read.
+	Namespace string
+	// Watch streams updates with WatchValues instead of reading the values once with GetValues.
+	Watch bool
+}
+
+// GRPC returns a source that reads values from a service implementing the ConfigService protocol
+// defined in the `configpb` package, for organizations that serve configuration from an internal
+// config plane. Keys are used as the service returns them.
+//
+// The values are read the first time a key is looked up. If Watch is set the source keeps a
+// WatchValues stream open from then on and uses every set of values the service sends, and the
+// source, which implements Notifier, signals each update. If the stream ends the values are read
+// again on the next lookup.
+func GRPC(cfg GRPCConfig) Source {
+	var (
+		// loadMu serializes loading, mu guards values, which the stream updates
+		loadMu sync.Mutex
+		mu     sync.Mutex
+		client configpb.ConfigServiceClient
+		values map[string]string
+	)
+	source := newNotifySource(&funcSource{name: "grpc:" + cfg.Target + "/" + cfg.Namespace})
+
+	connect := func() error {
+		if client != nil {
+			return nil
+		}
+		conn := cfg.Conn
+		if conn == nil {
+			opts := cfg.DialOptions
+			if len(opts) == 0 {
+				opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))}
+			}
+			var err error
+			conn, err = grpc.NewClient(cfg.Target, opts...)
+			if err != nil {
+				return err
+			}
+		}
+		client = configpb.NewConfigServiceClient(conn)
+		return nil
+	}
+
+	// watch opens a stream and waits for the first set of values.
+	watch := func(ctx context.Context) error {
+		streamCtx, cancel := context.WithCancel(context.Background())
+		stream, err := client.WatchValues(streamCtx, &configpb.WatchValuesRequest{Namespace: cfg.Namespace})
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		first := make(chan error, 1)
+		go func() {
+			defer cancel()
+			received := false
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					if !received {
+						first <- err
+					} else {
+						mu.Lock()
+						values = nil
+						mu.Unlock()
+					}
+					return
+				}
+				mu.Lock()
+				values = resp.GetValues()
+				if values == nil {
+					values = make(map[string]string)
+				}
+				mu.Unlock()
+				if received {
+					source.notify()
+				} else {
+					received = true
+					first <- nil
+				}
+			}
+		}()
+
+		select {
+		case err := <-first:
+			return err
+		case <-ctx.Done():
+			cancel()
+			return ctx.Err()
+		}
+	}
+
+	load := func(ctx context.Context) error {
+		loadMu.Lock()
+		defer loadMu.Unlock()
+
+		mu.Lock()
+		loaded := values != nil
+		mu.Unlock()
+		if loaded {
+			return nil
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		if cfg.Watch {
+			return watch(ctx)
+		}
+		resp, err := client.GetValues(ctx, &configpb.GetValuesRequest{Namespace: cfg.Namespace})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		values = resp.GetValues()
+		if values == nil {
+			values = make(map[string]string)
+		}
+		return nil
+	}
+
+	source.prefetch = load
+	source.lookup = func(ctx context.Context, key string) (string, bool, error) {
+		if err := load(ctx); err != nil {
+			return "", false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value, ok := values[key]
+		return value, ok, nil
+	}
+	return source
+}
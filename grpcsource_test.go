@@ -0,0 +1,124 @@
+package envstruct_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/configpb"
+)
+
+// fakeConfigService serves `values` for the namespace `my-service`. WatchValues sends them and
+// then every set of values sent on `updates`.
+type fakeConfigService struct {
+	configpb.UnimplementedConfigServiceServer
+	values  map[string]string
+	updates chan map[string]string
+}
+
+func (s *fakeConfigService) GetValues(_ context.Context, req *configpb.GetValuesRequest) (*configpb.GetValuesResponse, error) {
+	if req.GetNamespace() != "my-service" {
+		return &configpb.GetValuesResponse{}, nil
+	}
+	return &configpb.GetValuesResponse{Values: s.values}, nil
+}
+
+func (s *fakeConfigService) WatchValues(req *configpb.WatchValuesRequest, stream grpc.ServerStreamingServer[configpb.GetValuesResponse]) error {
+	if err := stream.Send(&configpb.GetValuesResponse{Values: s.values}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case values := <-s.updates:
+			if err := stream.Send(&configpb.GetValuesResponse{Values: values}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// dialConfigService serves `service` in memory and returns a connection to it.
+func dialConfigService(t *testing.T, service *fakeConfigService) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	configpb.RegisterConfigServiceServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPC(t *testing.T) {
+	conn := dialConfigService(t, &fakeConfigService{
+		values: map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"},
+	})
+	var cfg remoteConfig
+	source := envstruct.GRPC(envstruct.GRPCConfig{Conn: conn, Namespace: "my-service"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	// a namespace without values is not an error
+	cfg = remoteConfig{}
+	source = envstruct.GRPC(envstruct.GRPCConfig{Conn: conn, Namespace: "other-service"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg != (remoteConfig{}) {
+		t.Errorf("got %+v, want no values", cfg)
+	}
+}
+
+func TestGRPCWatch(t *testing.T) {
+	service := &fakeConfigService{
+		values:  map[string]string{"LOG_LEVEL": "info"},
+		updates: make(chan map[string]string),
+	}
+	conn := dialConfigService(t, service)
+	source := envstruct.GRPC(envstruct.GRPCConfig{Conn: conn, Namespace: "my-service", Watch: true})
+	store, err := envstruct.NewStore(remoteConfig{}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Load().Level; got != "info" {
+		t.Fatalf("got level %q, want the first values of the stream", got)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	service.updates <- map[string]string{"LOG_LEVEL": "debug", "DB_PORT": "6543"}
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Load() != (remoteConfig{Level: "debug", Port: 6543}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want the values of the update", store.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,90 @@
+package envstruct
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrNotLoaded is returned by `Health.Ready` until the first successful parse.
+	ErrNotLoaded = errors.New("config has not been loaded")
+	// ErrReloadFailed is returned by `Health.Ready` while the most recent parse has failed.
+	ErrReloadFailed = errors.New("config reload failed")
+)
+
+// Health tracks whether a config has been loaded and whether its most recent reload succeeded,
+// for Kubernetes readiness probes of services that reload their config. Pass it to every Parse call
+// with WithHealth and serve it on the probe path:
+//
+//	health := NewHealth()
+//	http.Handle("/readyz", health)
+//	res, err := Parse(&cfg, WithHealth(health))
+//
+// A service is only ready after a successful parse, and stops being ready while the most recent
+// parse has failed, so a bad reload takes it out of rotation instead of leaving it serving with a
+// config it could not apply.
+type Health struct {
+	mu      sync.RWMutex
+	loaded  bool
+	lastErr error
+}
+
+// NewHealth returns a Health that is not ready until a parse succeeds.
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// WithHealth makes Parse report its outcome to `health`.
+func WithHealth(health *Health) Option {
+	return func(o *options) {
+		o.health = health
+	}
+}
+
+// Ready returns nil if a parse has succeeded and the most recent parse succeeded. Otherwise it
+// returns ErrNotLoaded or ErrReloadFailed, wrapping the error of the most recent parse if any.
+func (h *Health) Ready() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	switch {
+	case !h.loaded && h.lastErr != nil:
+		return errors.Join(ErrNotLoaded, h.lastErr)
+	case !h.loaded:
+		return ErrNotLoaded
+	case h.lastErr != nil:
+		return errors.Join(ErrReloadFailed, h.lastErr)
+	}
+	return nil
+}
+
+// ServeHTTP responds with 200 if the config is ready and 503 otherwise. The body only says why the
+// config is not ready, not the error of the parse, since parse errors may contain config values.
+func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	err := h.Ready()
+	switch {
+	case errors.Is(err, ErrNotLoaded):
+		http.Error(w, ErrNotLoaded.Error(), http.StatusServiceUnavailable)
+	case err != nil:
+		http.Error(w, ErrReloadFailed.Error(), http.StatusServiceUnavailable)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
+
+// observe records the outcome of a parse.
+func (h *Health) observe(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastErr = err
+	if err == nil {
+		h.loaded = true
+	}
+}
@@ -0,0 +1,113 @@
+package envstruct_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type healthConfig struct {
+	Password envstruct.Secret[int] `env:"PASSWORD"`
+}
+
+// probe serves a request to `health` and returns the status and body of the response.
+func probe(health *envstruct.Health, method string) (int, string) {
+	rec := httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(method, "/readyz", nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestHealth(t *testing.T) {
+	health := envstruct.NewHealth()
+	source := sources.Static(map[string]string{"PASSWORD": "hunter2"})
+	parse := func() error {
+		var cfg healthConfig
+		_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithHealth(health))
+		return err
+	}
+
+	if err := health.Ready(); !errors.Is(err, envstruct.ErrNotLoaded) {
+		t.Errorf("got %v before a parse, want ErrNotLoaded", err)
+	}
+	if code, _ := probe(health, http.MethodGet); code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d before a parse, want 503", code)
+	}
+
+	// a failed first parse is still not loaded, and its error is kept
+	parseErr := parse()
+	if parseErr == nil {
+		t.Fatal("expected an error")
+	}
+	err := health.Ready()
+	if !errors.Is(err, envstruct.ErrNotLoaded) {
+		t.Errorf("got %v after a failed parse, want ErrNotLoaded", err)
+	}
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Errorf("got %v, want it to wrap the error of the parse", err)
+	}
+	code, body := probe(health, http.MethodGet)
+	if code != http.StatusServiceUnavailable || strings.Contains(body, "hunter2") {
+		t.Errorf("got status %d and body %q, want 503 without the value", code, body)
+	}
+
+	source.Set("PASSWORD", "1234")
+	if err := parse(); err != nil {
+		t.Fatal(err)
+	}
+	if err := health.Ready(); err != nil {
+		t.Errorf("got %v after a successful parse, want nil", err)
+	}
+	if code, body := probe(health, http.MethodGet); code != http.StatusOK || body != "ok\n" {
+		t.Errorf("got status %d and body %q, want 200 and %q", code, body, "ok\n")
+	}
+
+	// a failed reload makes the config not ready until a parse succeeds again
+	source.Set("PASSWORD", "hunter2")
+	if err := parse(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := health.Ready(); !errors.Is(err, envstruct.ErrReloadFailed) {
+		t.Errorf("got %v after a failed reload, want ErrReloadFailed", err)
+	}
+	code, body = probe(health, http.MethodGet)
+	if code != http.StatusServiceUnavailable || body != envstruct.ErrReloadFailed.Error()+"\n" {
+		t.Errorf("got status %d and body %q, want 503 and %q", code, body, envstruct.ErrReloadFailed.Error()+"\n")
+	}
+	source.Set("PASSWORD", "5678")
+	if err := parse(); err != nil {
+		t.Fatal(err)
+	}
+	if err := health.Ready(); err != nil {
+		t.Errorf("got %v after recovering, want nil", err)
+	}
+}
+
+func TestHealthServeHTTP(t *testing.T) {
+	health := envstruct.NewHealth()
+	if _, err := envstruct.Parse(&healthConfig{}, envstruct.WithSources(sources.Static(nil)), envstruct.WithHealth(health)); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("got Cache-Control %q, want no-store", got)
+	}
+	if code, _ := probe(health, http.MethodHead); code != http.StatusOK {
+		t.Errorf("got status %d for HEAD, want 200", code)
+	}
+
+	rec = httptest.NewRecorder()
+	health.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d for POST, want 405", rec.Code)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD" {
+		t.Errorf("got Allow %q, want %q", got, "GET, HEAD")
+	}
+}
@@ -0,0 +1,102 @@
+package envstruct
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPConfig configures the HTTP source.
+type HTTPConfig struct {
+	// URL is the address of the JSON document.
+	URL string
+	// Header is added to every request, e.g. `{"Authorization": {"Bearer ..."}}`.
+	Header http.Header
+	// Timeout bounds each request. Defaults to 10 seconds.
+	Timeout time.Duration
+	// RefreshInterval is how long a fetched document is used before it is revalidated. If zero,
+	// the document is only fetched once.
+	RefreshInterval time.Duration
+	// TLSConfig is used for requests if set.
+	TLSConfig *tls.Config
+}
+
+// HTTP returns a source that fetches a JSON object from a URL, such as a central config service,
+// and flattens it into keys like JSONFile.
+//
+// The document is fetched the first time a key is looked up. If RefreshInterval is set, the
+// document is revalidated once it is older than the interval. Revalidation sends the ETag of the
+// cached document in an `If-None-Match` header, so an unchanged document is not downloaded again.
+func HTTP(cfg HTTPConfig) Source {
+	client := newHTTPClient(cfg.TLSConfig)
+	if cfg.Timeout > 0 {
+		client.Timeout = cfg.Timeout
+	}
+
+	var (
+		mu        sync.Mutex
+		values    map[string]string
+		etag      string
+		fetchedAt time.Time
+	)
+	fetch := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+		if err != nil {
+			return err
+		}
+		for name, headerValues := range cfg.Header {
+			req.Header[name] = headerValues
+		}
+		req.Header.Set("Accept", "application/json")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusNotModified:
+			fetchedAt = time.Now()
+			return nil
+		case http.StatusOK:
+		default:
+			return &statusError{status: resp.Status, code: resp.StatusCode, url: req.URL.Redacted()}
+		}
+
+		decoder := json.NewDecoder(resp.Body)
+		decoder.UseNumber()
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			return fmt.Errorf("error decoding response from %s: %w", req.URL.Redacted(), err)
+		}
+		values = flattenMap(obj, defaultKeySeparator)
+		etag = resp.Header.Get("ETag")
+		fetchedAt = time.Now()
+		return nil
+	}
+
+	return &funcSource{
+		name: "http:" + stripQuery(cfg.URL),
+		lookup: func(ctx context.Context, key string) (string, bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			stale := cfg.RefreshInterval > 0 && time.Since(fetchedAt) >= cfg.RefreshInterval
+			if values == nil || stale {
+				if err := fetch(ctx); err != nil {
+					return "", false, err
+				}
+			}
+			value, ok := values[key]
+			return value, ok, nil
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package envstruct_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestHTTP(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		document    = `{"db": {"host": "db.internal", "port": 5432}}`
+		etag        = `"v1"`
+		downloads   int
+		revalidated int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	source := envstruct.HTTP(envstruct.HTTPConfig{
+		URL:             server.URL + "/config?token=secret",
+		Header:          http.Header{"Authorization": {"Bearer token"}},
+		RefreshInterval: time.Millisecond,
+	})
+	parse := func() remoteConfig {
+		t.Helper()
+		var cfg remoteConfig
+		res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Sources["Host"] != "http:"+server.URL+"/config" {
+			t.Errorf("got source %q, want the URL without its query", res.Sources["Host"])
+		}
+		return cfg
+	}
+
+	if got := parse(); got != (remoteConfig{Host: "db.internal", Port: 5432}) {
+		t.Errorf("got %+v", got)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if got := parse(); got != (remoteConfig{Host: "db.internal", Port: 5432}) {
+		t.Errorf("got %+v after revalidating", got)
+	}
+
+	mu.Lock()
+	document, etag = `{"db": {"host": "db2.internal"}}`, `"v2"`
+	mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	if got := parse(); got.Host != "db2.internal" {
+		t.Errorf("got %+v after the document changed", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if downloads != 2 || revalidated == 0 {
+		t.Errorf("got %d downloads and %d revalidations, want 2 downloads", downloads, revalidated)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	var cfg remoteConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.HTTP(envstruct.HTTPConfig{URL: server.URL})))
+	if err == nil || !envstruct.IsRetryable(err) {
+		t.Errorf("got error %v, want a retryable error", err)
+	}
+}
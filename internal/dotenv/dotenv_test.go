@@ -0,0 +1,67 @@
+package dotenv
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	content := `# comment
+DB_HOST=db.internal
+export DB_PORT = 5432
+EMPTY=
+UNQUOTED=value with spaces # comment
+HASH=a#b
+SINGLE='keep \n and $HOME' # comment
+DOUBLE="line one\nline \"two\"\t\\"
+`
+	values, err := Read(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"DB_HOST":  "db.internal",
+		"DB_PORT":  "5432",
+		"EMPTY":    "",
+		"UNQUOTED": "value with spaces",
+		"HASH":     "a#b",
+		"SINGLE":   `keep \n and $HOME`,
+		"DOUBLE":   "line one\nline \"two\"\t\\",
+	}
+	if !maps.Equal(values, want) {
+		t.Errorf("got %q, want %q", values, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, content := range []string{
+		"NO_VALUE\n",
+		"=value\n",
+		"TWO WORDS=value\n",
+		"KEY=\"unterminated\n",
+		"KEY='value' trailing\n",
+	} {
+		if _, err := Read(strings.NewReader(content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, value := range []string{
+		"", "plain", "with space", " padded ", "a#b", "it's", `say "hi"`, `back\slash`, "multi\nline\r\n", "tab\t",
+	} {
+		quoted := Quote(value)
+		values, err := Read(strings.NewReader("KEY=" + quoted + "\n"))
+		if err != nil {
+			t.Fatalf("%q quoted as %q: %v", value, quoted, err)
+		}
+		if values["KEY"] != value {
+			t.Errorf("%q quoted as %q reads as %q", value, quoted, values["KEY"])
+		}
+	}
+	if got := Quote("plain"); got != "plain" {
+		t.Errorf("got %q, want values that do not need quotes unquoted", got)
+	}
+}
@@ -0,0 +1,251 @@
+// Package schema loads the fields of config structs from Go source for the command line tools.
+// It mirrors how the root package walks struct fields at runtime, using type information instead
+// of reflection.
+package schema
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Field is a struct field with an `env` tag.
+type Field struct {
+	// Path is the dotted path of the field from the top level struct, e.g. `Text.IntValue`.
+	Path string
+	// Key is the environment variable the field is bound to.
+	Key string
+	// Options are the options of the `env` tag, e.g. `secret`.
+	Options map[string]string
+	// Type is the type of the field.
+	Type types.Type
+	// Tag is the full struct tag of the field.
+	Tag reflect.StructTag
+	// Pos is the position of the field in the source.
+	Pos token.Pos
+}
+
+// Has reports whether the option `name` is present on the `env` tag.
+func (f Field) Has(name string) bool {
+	_, ok := f.Options[name]
+	return ok
+}
+
+// Get returns the value of the option `name`, or a blank string if it is not present.
+func (f Field) Get(name string) string {
+	return f.Options[name]
+}
+
+// Default returns the value of the `default` tag and whether the field has one.
+func (f Field) Default() (string, bool) {
+	return f.Tag.Lookup("default")
+}
+
+// Required reports whether the field has the `required` option.
+func (f Field) Required() bool {
+	return f.Has("required")
+}
+
+// Secret reports whether the field has the `secret` option or is of type `Secret[T]`.
+func (f Field) Secret() bool {
+	return f.Has("secret")
+}
+
+// Desc returns the description from the `desc` tag.
+func (f Field) Desc() string {
+	return f.Tag.Get("desc")
+}
+
+// Deprecated returns the message of the `deprecated` tag and whether the field has one, e.g.
+// `deprecated:"use DATABASE_URL"` for a variable that is still read but should no longer be set.
+func (f Field) Deprecated() (string, bool) {
+	return f.Tag.Lookup("deprecated")
+}
+
+// Rules returns the validation rules of the field from the `validate` tag option and the
+// `validate` struct tag, e.g. `oneof=debug info`, in the order they are checked.
+func (f Field) Rules() []string {
+	var rules []string
+	if rule := f.Get("validate"); rule != "" {
+		rules = append(rules, rule)
+	}
+	if tag := f.Tag.Get("validate"); tag != "" {
+		for _, rule := range strings.Split(tag, ",") {
+			rules = append(rules, strings.TrimSpace(rule))
+		}
+	}
+	return rules
+}
+
+// TypeString returns the type of the field qualified by package name, e.g. `time.Duration`.
+func (f Field) TypeString() string {
+	return types.TypeString(f.Type, func(pkg *types.Package) string { return pkg.Name() })
+}
+
+// Struct is a config struct type and its fields.
+type Struct struct {
+	// Name is the name of the type.
+	Name string
+	// Package is the package the type is declared in.
+	Package *packages.Package
+	// Fields are the fields with an `env` tag in declaration order, including those of nested
+	// structs.
+	Fields []Field
+}
+
+// loadMode is the information loaded for packages.
+const loadMode = packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// LoadPackages loads the packages matching `patterns` in `dir`. Type errors are ignored, since
+// packages often do not compile until generated code is written, as are build errors of packages
+// that could still be parsed.
+func LoadPackages(dir string, patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: loadMode, Dir: dir}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			if err.Kind == packages.ParseError || (err.Kind != packages.TypeError && len(pkg.Syntax) == 0) {
+				errs = append(errs, err.Error())
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error loading packages: %s", strings.Join(errs, "; "))
+	}
+	return pkgs, nil
+}
+
+// Lookup returns the struct type named `name` in `pkgs`.
+func Lookup(pkgs []*packages.Package, name string) (*Struct, error) {
+	for _, pkg := range pkgs {
+		obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("type '%s' in %s is not a struct", name, pkg.PkgPath)
+		}
+		return &Struct{Name: name, Package: pkg, Fields: Fields(st)}, nil
+	}
+	return nil, fmt.Errorf("no type named '%s' found", name)
+}
+
+// All returns every struct type declared at the top level of `pkgs` that has at least one field
+// with an `env` tag, in the order of the packages and their sorted scope.
+func All(pkgs []*packages.Package) []*Struct {
+	var structs []*Struct
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			if fields := Fields(st); len(fields) > 0 {
+				structs = append(structs, &Struct{Name: name, Package: pkg, Fields: fields})
+			}
+		}
+	}
+	return structs
+}
+
+// Fields returns the fields of `st` that have an `env` tag, recursing into nested structs the same
+// way the root package does.
+func Fields(st *types.Struct) []Field {
+	var fields []Field
+	walk(st, "", &fields)
+	return fields
+}
+
+func walk(st *types.Struct, path string, fields *[]Field) {
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		fieldPath := v.Name()
+		if path != "" {
+			fieldPath = path + "." + v.Name()
+		}
+		key, options := parseEnvTag(tag.Get("env"))
+
+		nested, isStruct := v.Type().Underlying().(*types.Struct)
+		if isStruct && !hasOption(options, "parseurl") && !IsTextUnmarshaler(v.Type()) {
+			// fields of unexported structs can not be set
+			if v.Exported() {
+				walk(nested, fieldPath, fields)
+			}
+			continue
+		}
+		if v.Exported() && key != "" {
+			// Secret fields are always treated as secret, like the root package does
+			if IsSecretType(v.Type()) {
+				options["secret"] = ""
+			}
+			*fields = append(*fields, Field{
+				Path:    fieldPath,
+				Key:     key,
+				Options: options,
+				Type:    v.Type(),
+				Tag:     tag,
+				Pos:     v.Pos(),
+			})
+		}
+	}
+}
+
+// parseEnvTag splits an `env` tag into its key and options, like the root package.
+func parseEnvTag(tag string) (string, map[string]string) {
+	key, rest, _ := strings.Cut(tag, ",")
+	options := make(map[string]string)
+	if rest != "" {
+		for _, opt := range strings.Split(rest, ",") {
+			name, value, _ := strings.Cut(opt, "=")
+			options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return strings.TrimSpace(key), options
+}
+
+// rootPackage is the import path of the package that defines Secret.
+const rootPackage = "github.com/jha-captech/env-vars-to-struct"
+
+// IsSecretType reports whether `t` is an instance of the Secret type of the root package.
+func IsSecretType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Origin().Obj()
+	return obj.Name() == "Secret" && obj.Pkg() != nil && obj.Pkg().Path() == rootPackage
+}
+
+func hasOption(options map[string]string, name string) bool {
+	_, ok := options[name]
+	return ok
+}
+
+// textUnmarshaler is the `encoding.TextUnmarshaler` interface.
+var textUnmarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "UnmarshalText", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())),
+		false,
+	)),
+}, nil).Complete()
+
+// IsTextUnmarshaler reports whether a pointer to `t` implements `encoding.TextUnmarshaler`, in
+// which case values are set with UnmarshalText.
+func IsTextUnmarshaler(t types.Type) bool {
+	return types.Implements(types.NewPointer(t), textUnmarshaler)
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/config", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := Lookup(pkgs, "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	fields := make(map[string]Field)
+	for _, f := range st.Fields {
+		paths = append(paths, f.Path)
+		fields[f.Path] = f
+	}
+	want := []string{
+		"Host", "Port", "Password", "Token", "Timeout", "Callback", "Legacy", "Level",
+		"Server.Level", "Server.Addr.Host",
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("got fields %q, want %q", paths, want)
+	}
+
+	host := fields["Host"]
+	if host.Key != "DB_HOST" || !host.Required() || host.Desc() != "database host" || host.Secret() {
+		t.Errorf("got %+v", host)
+	}
+	if def, ok := fields["Port"].Default(); !ok || def != "5432" {
+		t.Errorf("got default %q, want 5432", def)
+	}
+	if rules := fields["Port"].Rules(); !slices.Equal(rules, []string{"port", "max=6000"}) {
+		t.Errorf("got rules %q", rules)
+	}
+	if rules := fields["Timeout"].Rules(); !slices.Equal(rules, []string{"min=1s"}) {
+		t.Errorf("got rules %q", rules)
+	}
+	if !fields["Password"].Secret() || !fields["Token"].Secret() {
+		t.Error("got fields that are not secret, want the secret option and Secret fields to be")
+	}
+	if msg, ok := fields["Legacy"].Deprecated(); !ok || msg != "use DB_HOST" {
+		t.Errorf("got deprecation %q", msg)
+	}
+	if got := fields["Timeout"].TypeString(); got != "time.Duration" {
+		t.Errorf("got type %q, want time.Duration", got)
+	}
+	if !IsTextUnmarshaler(fields["Level"].Type) || IsTextUnmarshaler(fields["Host"].Type) {
+		t.Error("got the wrong text unmarshalers")
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/config", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lookup(pkgs, "Missing"); err == nil {
+		t.Error("expected an error for a missing type")
+	}
+	if _, err := Lookup(pkgs, "Level"); err == nil {
+		t.Error("expected an error for a type that is not a struct")
+	}
+	if _, err := LoadPackages("testdata/missing", "."); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestAll(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/config", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, st := range All(pkgs) {
+		names = append(names, st.Name)
+	}
+	if want := []string{"Config", "netAddr"}; !slices.Equal(names, want) {
+		t.Errorf("got structs %q, want %q", names, want)
+	}
+}
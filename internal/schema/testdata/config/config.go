@@ -0,0 +1,43 @@
+package config
+
+import (
+	"net/url"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type Config struct {
+	Host     string                   `env:"DB_HOST,required" desc:"database host"`
+	Port     int                      `env:"DB_PORT" default:"5432" validate:"port, max=6000"`
+	Password string                   `env:"DB_PASSWORD,secret"`
+	Token    envstruct.Secret[string] `env:"API_TOKEN"`
+	Timeout  time.Duration            `env:"TIMEOUT,validate=min=1s"`
+	Callback url.URL                  `env:"CALLBACK_URL,parseurl"`
+	Legacy   string                   `env:"LEGACY_HOST" deprecated:"use DB_HOST"`
+	Level    Level                    `env:"LEVEL"`
+	Server   struct {
+		Level string `env:"LOG_LEVEL"`
+		Addr  netAddr
+	}
+	internal string `env:"INTERNAL"`
+	Untagged string
+	hidden   struct {
+		Key string `env:"HIDDEN_KEY"`
+	}
+}
+
+type netAddr struct {
+	Host string `env:"ADDR_HOST"`
+}
+
+type Untagged struct {
+	Name string
+}
+
+type Level string
+
+func (l *Level) UnmarshalText(text []byte) error {
+	*l = Level(text)
+	return nil
+}
@@ -0,0 +1,149 @@
+package envstruct
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Marshal reverses Parse: it returns the value of every field of `obj` with an `env` tag formatted
+// the way Parse reads it, by key, so the config can be exported or passed to a child process and
+// parsed back into the same values:
+//
+//	env, err := Marshal(&cfg)
+//	// env["PORT"] == "8080"
+//
+// Durations are formatted like `1m30s`, slices are joined with commas and maps are written as
+// `key:value` pairs sorted by key, as Parse splits them. An error is returned for items that
+// contain a comma or keys that contain a colon, since they would not parse back into the same
+// value.
+//
+// Secret values are included unless WithOmitSecrets is used, and fields with the zero value unless
+// WithOmitZero is used. Fields tagged with the `parseurl` option are joined back into a URL, and
+// fields with the `quantity` option are formatted as plain numbers, or thousandths with the `m`
+// suffix for `quantity=milli`. Fields with the `exec`, `op` or `encrypted` options are left out,
+// since their variables hold commands, references or ciphertext rather than values.
+//
+// An error is returned for fields whose type can not be formatted.
+func Marshal(obj any, opts ...Option) (values map[string]string, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Marshal: %w", err)
+		}
+	}()
+	o := newOptions(opts)
+	values = make(map[string]string)
+	walkFields(structValue(obj), "", func(f boundField) {
+		if err != nil || f.tag.has("exec") || f.tag.has("op") || f.tag.has("encrypted") {
+			return
+		}
+		if (f.tag.has("secret") && o.omitSecrets) || (o.omitZero && f.value.IsZero()) {
+			return
+		}
+		var value string
+		value, err = marshalValue(f)
+		if err != nil {
+			err = fmt.Errorf("field '%s' bound to '%s': %w", f.path, f.tag.key, err)
+			return
+		}
+		values[f.tag.key] = value
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// marshalValue formats the value of `f` the way it is parsed.
+func marshalValue(f boundField) (string, error) {
+	val := f.value
+	if secret, ok := val.Interface().(interface{ secretValue() any }); ok {
+		// Secret values are redacted by MarshalText
+		val = reflect.ValueOf(secret.secretValue())
+	}
+	switch {
+	case f.tag.has("parseurl"):
+		return marshalURL(val), nil
+	case f.tag.has("quantity"):
+		return marshalQuantity(val, f.tag.get("quantity"))
+	}
+	value, ok := formatValue(val)
+	if !ok {
+		return "", formatError(val)
+	}
+	return value, nil
+}
+
+// marshalURL joins the fields of a struct set by setURLFields back into a URL.
+func marshalURL(val reflect.Value) string {
+	part := func(name string) string {
+		field := val.FieldByName(name)
+		switch {
+		case !field.IsValid():
+			return ""
+		case field.Kind() == reflect.String:
+			return field.String()
+		case field.Kind() == reflect.Int && field.Int() != 0:
+			return strconv.FormatInt(field.Int(), 10)
+		}
+		return ""
+	}
+
+	u := url.URL{
+		Scheme:   part("Scheme"),
+		Host:     part("Host"),
+		Path:     part("Path"),
+		Fragment: part("Fragment"),
+	}
+	if port := part("Port"); port != "" {
+		u.Host = net.JoinHostPort(u.Host, port)
+	}
+	if u.Path != "" && u.Host != "" {
+		u.Path = "/" + u.Path
+	}
+	user := part("User")
+	if user == "" {
+		user = part("Username")
+	}
+	if password := part("Password"); password != "" {
+		u.User = url.UserPassword(user, password)
+	} else if user != "" {
+		u.User = url.User(user)
+	}
+	if query := val.FieldByName("Query"); query.IsValid() {
+		switch q := query.Interface().(type) {
+		case string:
+			u.RawQuery = q
+		case url.Values:
+			u.RawQuery = q.Encode()
+		case map[string]string:
+			params := make(url.Values, len(q))
+			for name, value := range q {
+				params.Set(name, value)
+			}
+			u.RawQuery = params.Encode()
+		}
+	}
+	return u.String()
+}
+
+// marshalQuantity formats the number `val` as a quantity with the scale `scale`.
+func marshalQuantity(val reflect.Value, scale string) (string, error) {
+	var value string
+	switch val.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value = strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = strconv.FormatUint(val.Uint(), 10)
+	default:
+		return "", fmt.Errorf("the quantity option requires a number field, not %s", val.Type())
+	}
+	if scale == "milli" {
+		value += "m"
+	}
+	return value, nil
+}
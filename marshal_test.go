@@ -0,0 +1,196 @@
+package envstruct_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type marshalDB struct {
+	Scheme   string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Path     string
+}
+
+type marshalConfig struct {
+	Name     string                   `env:"NAME"`
+	Port     int                      `env:"PORT"`
+	Debug    bool                     `env:"DEBUG"`
+	Key      []byte                   `env:"KEY"`
+	Timeout  time.Duration            `env:"TIMEOUT"`
+	Hosts    []string                 `env:"HOSTS"`
+	Ports    []int                    `env:"PORTS"`
+	Backoff  []time.Duration          `env:"BACKOFF"`
+	Limits   map[string]int           `env:"LIMITS"`
+	Timeouts map[string]time.Duration `env:"TIMEOUTS"`
+	Token    envstruct.Secret[string] `env:"TOKEN"`
+	DB       marshalDB                `env:"DB_URL,parseurl"`
+	Memory   int64                    `env:"MEMORY,quantity"`
+	CPU      int                      `env:"CPU,quantity=milli"`
+	Schedule envstruct.Schedule       `env:"SCHEDULE"`
+}
+
+func TestMarshal(t *testing.T) {
+	schedule, err := envstruct.ParseSchedule("*/5 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := marshalConfig{
+		Name:     "orders",
+		Port:     8080,
+		Debug:    true,
+		Key:      []byte("k3y"),
+		Timeout:  90 * time.Second,
+		Hosts:    []string{"a.internal", "b.internal"},
+		Ports:    []int{80, 443},
+		Backoff:  []time.Duration{100 * time.Millisecond, 2 * time.Second},
+		Limits:   map[string]int{"users": 10, "orders": 5},
+		Timeouts: map[string]time.Duration{"orders": 5 * time.Second},
+		Token:    envstruct.NewSecret("hunter2"),
+		DB:       marshalDB{Scheme: "postgres", Host: "db.internal", Port: 5432, User: "app", Password: "pw", Path: "orders"},
+		Memory:   2048,
+		CPU:      500,
+		Schedule: schedule,
+	}
+	values, err := envstruct.Marshal(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"NAME":     "orders",
+		"PORT":     "8080",
+		"DEBUG":    "true",
+		"KEY":      "k3y",
+		"TIMEOUT":  "1m30s",
+		"HOSTS":    "a.internal,b.internal",
+		"PORTS":    "80,443",
+		"BACKOFF":  "100ms,2s",
+		"LIMITS":   "orders:5,users:10",
+		"TIMEOUTS": "orders:5s",
+		"TOKEN":    "hunter2",
+		"DB_URL":   "postgres://app:pw@db.internal:5432/orders",
+		"MEMORY":   "2048",
+		"CPU":      "500m",
+		"SCHEDULE": "*/5 * * * *",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got %v\nwant %v", values, want)
+	}
+
+	values, err = envstruct.Marshal(&cfg, envstruct.WithOmitSecrets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := values["TOKEN"]; ok {
+		t.Error("secret was not omitted")
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	schedule, err := envstruct.ParseSchedule("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := marshalConfig{
+		Name:     "orders",
+		Port:     8080,
+		Debug:    true,
+		Key:      []byte("k3y"),
+		Timeout:  1500 * time.Millisecond,
+		Hosts:    []string{"a.internal", "b.internal"},
+		Ports:    []int{80, 443},
+		Backoff:  []time.Duration{time.Second},
+		Limits:   map[string]int{"users": 10, "orders": 5},
+		Timeouts: map[string]time.Duration{"orders": 5 * time.Second, "users": time.Minute},
+		Token:    envstruct.NewSecret("hunter2"),
+		DB:       marshalDB{Scheme: "postgres", Host: "db.internal", Port: 5432, User: "app", Password: "pw", Path: "orders"},
+		Memory:   1 << 30,
+		CPU:      250,
+		Schedule: schedule,
+	}
+	values, err := envstruct.Marshal(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got marshalConfig
+	if _, err := envstruct.Parse(&got, envstruct.WithSources(sources.Static(values))); err != nil {
+		t.Fatal(err)
+	}
+	if got.Token.Value() != want.Token.Value() {
+		t.Errorf("got token %q, want %q", got.Token.Value(), want.Token.Value())
+	}
+	got.Token, want.Token = envstruct.Secret[string]{}, envstruct.Secret[string]{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the config:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+type marshalRegion struct {
+	Region string `env:"REGION"`
+}
+
+func TestMarshalUnexportedStruct(t *testing.T) {
+	cfg := struct {
+		Port  int `env:"PORT"`
+		inner struct {
+			X string `env:"X"`
+		}
+		marshalRegion
+	}{Port: 8080, marshalRegion: marshalRegion{Region: "eu-west-1"}}
+	cfg.inner.X = "x"
+	values, err := envstruct.Marshal(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fields of embedded structs are promoted even if the struct is unexported
+	if want := map[string]string{"PORT": "8080", "REGION": "eu-west-1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("got %q, want the fields of the unexported struct left out", values)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := map[string]struct {
+		cfg     any
+		wantErr string
+	}{
+		"item with comma": {
+			cfg: &struct {
+				Hosts []string `env:"HOSTS"`
+			}{Hosts: []string{"a,b"}},
+			wantErr: "contains ','",
+		},
+		"item with spaces": {
+			cfg: &struct {
+				Hosts []string `env:"HOSTS"`
+			}{Hosts: []string{" a"}},
+			wantErr: "leading or trailing spaces",
+		},
+		"key with colon": {
+			cfg: &struct {
+				Labels map[string]string `env:"LABELS"`
+			}{Labels: map[string]string{"a:b": "c"}},
+			wantErr: "contains ':'",
+		},
+		"unsupported type": {
+			cfg: &struct {
+				Ratio float64 `env:"RATIO"`
+			}{Ratio: 0.5},
+			wantErr: "type float64 is not supported",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := envstruct.Marshal(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package envstruct
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Default templates used for error messages. Templates are executed with a `MessageData` value.
+const (
+	DefaultMissingTemplate = "environment variable '{{.Key}}' is missing or blank"
+	DefaultParseTemplate   = "error parsing environment variable '{{.Key}}' to type '{{.Type}}': {{.Err}}"
+	DefaultTagTemplate     = "invalid tag for environment variable '{{.Key}}' of type '{{.Type}}': {{.Err}}"
+)
+
+// MessageData is the value error message templates are executed with.
+type MessageData struct {
+	// Key is the environment variable name.
+	Key string
+	// Type is the Go type the value was being parsed into.
+	Type string
+	// Err is the underlying parsing error. It is nil for missing values.
+	Err error
+}
+
+// ErrorTemplates holds the `text/template` templates used for the messages of missing values,
+// values that do not parse and tags that can not be satisfied. Create it with NewErrorTemplates
+// and pass it to Parse with WithErrorTemplates.
+type ErrorTemplates struct {
+	missing *template.Template
+	parse   *template.Template
+	tag     *template.Template
+}
+
+// defaultTemplates are used for the messages of errors without templates of their own.
+var defaultTemplates = &ErrorTemplates{
+	missing: template.Must(template.New(ReasonMissing).Parse(DefaultMissingTemplate)),
+	parse:   template.Must(template.New(ReasonParse).Parse(DefaultParseTemplate)),
+	tag:     template.Must(template.New(ReasonTag).Parse(DefaultTagTemplate)),
+}
+
+// NewErrorTemplates parses templates for the messages of missing values, values that do not
+// parse and tags that can not be satisfied, allowing products to keep their own wording or to
+// localize messages. Passing an empty string keeps the default for that template.
+func NewErrorTemplates(missing, parse, tag string) (templates *ErrorTemplates, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in NewErrorTemplates: %w", err)
+		}
+	}()
+	templates = &ErrorTemplates{}
+	if templates.missing, err = parseTemplate(ReasonMissing, missing, DefaultMissingTemplate); err != nil {
+		return nil, err
+	}
+	if templates.parse, err = parseTemplate(ReasonParse, parse, DefaultParseTemplate); err != nil {
+		return nil, err
+	}
+	if templates.tag, err = parseTemplate(ReasonTag, tag, DefaultTagTemplate); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// parseTemplate parses the template `text` for `reason`, or `fallback` if `text` is empty.
+func parseTemplate(reason, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(reason).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", reason, err)
+	}
+	return tmpl, nil
+}
+
+// WithErrorTemplates makes the errors returned by Parse use `templates` for their messages.
+// Errors of other calls keep the default messages.
+func WithErrorTemplates(templates *ErrorTemplates) Option {
+	return func(o *options) {
+		o.errorTemplates = templates
+	}
+}
+
+// formatMessage renders the template of `templates` for `reason`, or the default template if
+// `templates` is nil. If the template fails to execute, the default template is used so that an
+// error message is never lost.
+func formatMessage(templates *ErrorTemplates, reason string, data MessageData) string {
+	if templates == nil {
+		templates = defaultTemplates
+	}
+	tmpl, fallback := templates.parse, defaultTemplates.parse
+	switch reason {
+	case ReasonMissing:
+		tmpl, fallback = templates.missing, defaultTemplates.missing
+	case ReasonTag:
+		tmpl, fallback = templates.tag, defaultTemplates.tag
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		sb.Reset()
+		_ = fallback.Execute(&sb, data)
+	}
+	return sb.String()
+}
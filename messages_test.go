@@ -0,0 +1,106 @@
+package envstruct_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// parseError returns the message of the error of parsing `value` into an int with `opts`.
+func parseError(t *testing.T, value string, opts ...envstruct.Option) string {
+	t.Helper()
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	opts = append(opts, envstruct.WithSources(sources.Static(map[string]string{"PORT": value})),
+		envstruct.WithErrOnMissingValue())
+	_, err := envstruct.Parse(&cfg, opts...)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	return err.Error()
+}
+
+// errorTemplates returns the templates parsed by NewErrorTemplates, failing the test on an error.
+func errorTemplates(t *testing.T, missing, parse, tag string) *envstruct.ErrorTemplates {
+	t.Helper()
+	templates, err := envstruct.NewErrorTemplates(missing, parse, tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+func TestErrorTemplates(t *testing.T) {
+	templates := envstruct.WithErrorTemplates(errorTemplates(t, "{{.Key}} fehlt", "{{.Key}} ist kein {{.Type}}", ""))
+	if got, want := parseError(t, "", templates), "in Parse: PORT fehlt"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := parseError(t, "abc", templates), "in Parse: PORT ist kein int64"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// the templates only apply to the parses they are passed to
+	if got, want := parseError(t, ""), "in Parse: environment variable 'PORT' is missing or blank"; got != want {
+		t.Errorf("got %q without the templates, want %q", got, want)
+	}
+
+	// an empty template keeps the default
+	templates = envstruct.WithErrorTemplates(errorTemplates(t, "", "{{.Key}} ist kein {{.Type}}", ""))
+	if got, want := parseError(t, "", templates), "in Parse: environment variable 'PORT' is missing or blank"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestErrorTemplatesConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			templates := envstruct.WithErrorTemplates(errorTemplates(t, "{{.Key}} missing "+strconv.Itoa(i), "", ""))
+			for range 20 {
+				if got, want := parseError(t, "", templates), "in Parse: PORT missing "+strconv.Itoa(i); got != want {
+					t.Errorf("got %q, want %q", got, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestErrorTemplatesTag(t *testing.T) {
+	var cfg struct {
+		URL string `env:"URL,parseurl"`
+	}
+	source := envstruct.WithSources(sources.Static(map[string]string{"URL": "https://api.internal"}))
+	_, err := envstruct.Parse(&cfg, source)
+	want := "in Parse: invalid tag for environment variable 'URL' of type 'string': the parseurl option requires a struct field"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+
+	templates := envstruct.WithErrorTemplates(errorTemplates(t, "", "", "{{.Key}}: Tag ungültig"))
+	_, err = envstruct.Parse(&cfg, source, templates)
+	if want := "in Parse: URL: Tag ungültig"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestErrorTemplatesInvalid(t *testing.T) {
+	if _, err := envstruct.NewErrorTemplates("{{.Key}} fehlt", "{{.Key", ""); err == nil {
+		t.Fatal("expected an error for the invalid template")
+	}
+}
+
+func TestErrorTemplatesExecutionFails(t *testing.T) {
+	templates := envstruct.WithErrorTemplates(errorTemplates(t, "{{.Unknown}}", "", ""))
+	// the default is used rather than losing the message
+	if got, want := parseError(t, "", templates), "in Parse: environment variable 'PORT' is missing or blank"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
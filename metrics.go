@@ -0,0 +1,135 @@
+package envstruct
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a Prometheus collector for the health of config loads and reloads, so dashboards can
+// alert on stale or failing config. Pass it to every Parse call with WithMetrics and register it:
+//
+//	metrics := NewMetrics()
+//	prometheus.MustRegister(metrics)
+//	res, err := Parse(&cfg, WithSources(vault, Env()), WithMetrics(metrics))
+//
+// It exposes:
+//
+//   - `envstruct_loads_total` and `envstruct_load_failures_total`, the number of parses and of
+//     failed parses
+//   - `envstruct_last_success_timestamp_seconds`, the time of the last successful parse
+//   - `envstruct_source_lookup_duration_seconds`, a histogram of lookup and prefetch latency by
+//     source
+//   - `envstruct_config_info`, which is 1 with a `hash` label identifying the values of the last
+//     successfully parsed config, so changes and drift between replicas can be seen
+type Metrics struct {
+	loads       prometheus.Counter
+	failures    prometheus.Counter
+	lastSuccess prometheus.Gauge
+	latency     *prometheus.HistogramVec
+	info        *prometheus.GaugeVec
+
+	mu   sync.Mutex
+	hash string
+}
+
+// NewMetrics returns a new, unregistered Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		loads: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "envstruct_loads_total",
+			Help: "Number of config loads, including reloads.",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "envstruct_load_failures_total",
+			Help: "Number of config loads that failed.",
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "envstruct_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful config load.",
+		}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "envstruct_source_lookup_duration_seconds",
+			Help:    "Latency of config source lookups and prefetches.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"source"}),
+		info: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "envstruct_config_info",
+			Help: "Always 1, with a hash of the values of the last successfully loaded config.",
+		}, []string{"hash"}),
+	}
+}
+
+// WithMetrics makes Parse record its outcome and the latency of its sources in `metrics`.
+func WithMetrics(metrics *Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// Describe implements `prometheus.Collector`.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.loads.Describe(ch)
+	m.failures.Describe(ch)
+	m.lastSuccess.Describe(ch)
+	m.latency.Describe(ch)
+	m.info.Describe(ch)
+}
+
+// Collect implements `prometheus.Collector`.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.loads.Collect(ch)
+	m.failures.Collect(ch)
+	m.lastSuccess.Collect(ch)
+	m.latency.Collect(ch)
+	m.info.Collect(ch)
+}
+
+// observeLookup records the latency of a call to the source `name` that started at `start`.
+func (m *Metrics) observeLookup(name string, start time.Time) {
+	m.latency.WithLabelValues(name).Observe(time.Since(start).Seconds())
+}
+
+// observeLoad records the outcome of a parse of `val`.
+func (m *Metrics) observeLoad(val reflect.Value, err error) {
+	m.loads.Inc()
+	if err != nil {
+		m.failures.Inc()
+		return
+	}
+	m.lastSuccess.SetToCurrentTime()
+
+	hash := configHash(val)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hash != m.hash {
+		m.info.DeleteLabelValues(m.hash)
+		m.info.WithLabelValues(hash).Set(1)
+		m.hash = hash
+	}
+}
+
+// configHash returns a short hash of the keys and values of the fields of `val`. Secrets are
+// included, so changing only a secret changes the hash, but the hash is of all values together
+// and truncated, so it does not reveal them.
+func configHash(val reflect.Value) string {
+	h := sha256.New()
+	walkFields(val, "", func(f boundField) {
+		var value string
+		if secret, ok := f.value.Interface().(interface{ secretValue() any }); ok {
+			// Secret values are redacted when formatted
+			value = fmt.Sprint(secret.secretValue())
+		} else if formatted, ok := formatValue(f.value); ok {
+			value = formatted
+		} else {
+			value = fmt.Sprint(f.value.Interface())
+		}
+		h.Write([]byte(f.tag.key + "=" + value + "\n"))
+	})
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,123 @@
+package envstruct_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type metricsConfig struct {
+	Port     int                      `env:"PORT"`
+	Password envstruct.Secret[string] `env:"PASSWORD"`
+}
+
+// gathered maps the names of the metrics of a registry to their values. Counters and gauges have
+// a single value, the sample count of a histogram is used, and label values are joined to the
+// name as `name{value}`.
+func gathered(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			name := family.GetName()
+			for _, label := range metric.GetLabel() {
+				name += "{" + label.GetValue() + "}"
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				values[name] = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				values[name] = metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				values[name] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return values
+}
+
+// configInfo returns the labeled names of the envstruct_config_info series in `values`.
+func configInfo(values map[string]float64) []string {
+	var names []string
+	for name := range values {
+		if strings.HasPrefix(name, "envstruct_config_info{") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := envstruct.NewMetrics()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metrics)
+	source := sources.Static(map[string]string{"PORT": "8080", "PASSWORD": "hunter2"}).Named("static")
+	parse := func() error {
+		var cfg metricsConfig
+		_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithMetrics(metrics))
+		return err
+	}
+
+	if err := parse(); err != nil {
+		t.Fatal(err)
+	}
+	values := gathered(t, registry)
+	if got := values["envstruct_loads_total"]; got != 1 {
+		t.Errorf("got %v loads, want 1", got)
+	}
+	if got := values["envstruct_load_failures_total"]; got != 0 {
+		t.Errorf("got %v failures, want 0", got)
+	}
+	if values["envstruct_last_success_timestamp_seconds"] == 0 {
+		t.Error("the time of the last success was not set")
+	}
+	if got := values["envstruct_source_lookup_duration_seconds{static}"]; got != 2 {
+		t.Errorf("got %v lookups of the static source, want 2", got)
+	}
+	info := configInfo(values)
+	if len(info) != 1 {
+		t.Fatalf("got config info %q, want one series", info)
+	}
+
+	// parsing the same values again keeps the hash
+	if err := parse(); err != nil {
+		t.Fatal(err)
+	}
+	if got := configInfo(gathered(t, registry)); len(got) != 1 || got[0] != info[0] {
+		t.Errorf("got config info %q after parsing the same values, want %q", got, info)
+	}
+
+	// changing only a secret changes the hash, which does not reveal it
+	source.Set("PASSWORD", "hunter3")
+	if err := parse(); err != nil {
+		t.Fatal(err)
+	}
+	changed := configInfo(gathered(t, registry))
+	if len(changed) != 1 || changed[0] == info[0] {
+		t.Errorf("got config info %q after a secret changed, want one series other than %q", changed, info)
+	}
+
+	// a failed parse is counted and leaves the config info alone
+	source.Set("PORT", "abc")
+	if err := parse(); err == nil {
+		t.Fatal("expected an error")
+	}
+	values = gathered(t, registry)
+	if got := values["envstruct_loads_total"]; got != 4 {
+		t.Errorf("got %v loads, want 4", got)
+	}
+	if got := values["envstruct_load_failures_total"]; got != 1 {
+		t.Errorf("got %v failures, want 1", got)
+	}
+	if got := configInfo(values); len(got) != 1 || got[0] != changed[0] {
+		t.Errorf("got config info %q after a failed parse, want %q", got, changed)
+	}
+}
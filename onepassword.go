@@ -0,0 +1,171 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// OnePasswordConfig configures how `op` tag options are resolved. Either a 1Password Connect
+// server or a service account is used.
+type OnePasswordConfig struct {
+	// ConnectHost and ConnectToken select a 1Password Connect server. They default to the
+	// `OP_CONNECT_HOST` and `OP_CONNECT_TOKEN` environment variables.
+	ConnectHost  string
+	ConnectToken string
+	// ServiceAccountToken selects a service account, used when no Connect server is configured.
+	// Defaults to the `OP_SERVICE_ACCOUNT_TOKEN` environment variable. References are read with
+	// the 1Password CLI, `op`, which must be on the PATH.
+	ServiceAccountToken string
+	// TLSConfig is used for requests to the Connect server if set.
+	TLSConfig *tls.Config
+}
+
+// WithOnePassword enables the `op` tag option, which sets a field to the value of a 1Password
+// secret reference instead of resolving it from the source chain:
+//
+//	DBPassword string `env:"DB_PASSWORD,secret,op=op://prod/postgres/password"`
+//
+// References have the form `op://<vault>/<item>/[<section>/]<field>`, where vaults and items
+// can be given by name or ID. Fields with the `op` option fail without this option.
+func WithOnePassword(cfg OnePasswordConfig) Option {
+	return func(o *options) {
+		o.onePassword = &cfg
+	}
+}
+
+// onePasswordValue reads the secret reference `ref`.
+func (o options) onePasswordValue(ref string) (string, error) {
+	if o.onePassword == nil {
+		return "", errors.New("the op tag option is not configured, use WithOnePassword to configure it")
+	}
+	cfg := *o.onePassword
+	if cfg.ConnectHost == "" {
+		cfg.ConnectHost = os.Getenv("OP_CONNECT_HOST")
+	}
+	if cfg.ConnectToken == "" {
+		cfg.ConnectToken = os.Getenv("OP_CONNECT_TOKEN")
+	}
+	if cfg.ServiceAccountToken == "" {
+		cfg.ServiceAccountToken = os.Getenv("OP_SERVICE_ACCOUNT_TOKEN")
+	}
+
+	ctx, cancel := context.WithTimeout(o.context(), remoteTimeout)
+	defer cancel()
+
+	switch {
+	case cfg.ConnectHost != "":
+		return cfg.readConnect(ctx, ref)
+	case cfg.ServiceAccountToken != "":
+		return cfg.readServiceAccount(ctx, ref)
+	default:
+		return "", errors.New("no 1Password Connect server or service account is configured")
+	}
+}
+
+// readServiceAccount reads `ref` with the 1Password CLI.
+func (cfg OnePasswordConfig) readServiceAccount(ctx context.Context, ref string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "op", "read", "--no-newline", ref)
+	cmd.Env = append(os.Environ(), "OP_SERVICE_ACCOUNT_TOKEN="+cfg.ServiceAccountToken)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("error reading '%s': %w: %s", ref, err, msg)
+		}
+		return "", fmt.Errorf("error reading '%s': %w", ref, err)
+	}
+	return stdout.String(), nil
+}
+
+// readConnect reads `ref` from the Connect server.
+func (cfg OnePasswordConfig) readConnect(ctx context.Context, ref string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(ref, "op://"), "/")
+	if !strings.HasPrefix(ref, "op://") || len(parts) < 3 || len(parts) > 4 {
+		return "", fmt.Errorf("invalid secret reference '%s'", ref)
+	}
+	vaultName, itemName, fieldName := parts[0], parts[1], parts[len(parts)-1]
+	var sectionName string
+	if len(parts) == 4 {
+		sectionName = parts[2]
+	}
+
+	client := newHTTPClient(cfg.TLSConfig)
+	get := func(path string, query url.Values, out any) (bool, error) {
+		endpoint := strings.TrimSuffix(cfg.ConnectHost, "/") + path
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Authorization", "Bearer "+cfg.ConnectToken)
+		return doJSONRequest(client, req, out)
+	}
+
+	type object struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	}
+	// find returns the ID of the object named `name` in the list at `path`, which may be an ID
+	find := func(path, nameAttr, name string) (string, error) {
+		var found []object
+		query := url.Values{"filter": {fmt.Sprintf("%s eq %q", nameAttr, name)}}
+		if _, err := get(path, query, &found); err != nil {
+			return "", err
+		}
+		if len(found) > 0 {
+			return found[0].ID, nil
+		}
+		return name, nil
+	}
+
+	vaultID, err := find("/v1/vaults", "name", vaultName)
+	if err != nil {
+		return "", err
+	}
+	itemID, err := find("/v1/vaults/"+url.PathEscape(vaultID)+"/items", "title", itemName)
+	if err != nil {
+		return "", err
+	}
+
+	var item struct {
+		Fields []struct {
+			ID      string `json:"id"`
+			Label   string `json:"label"`
+			Value   string `json:"value"`
+			Section *struct {
+				ID    string `json:"id"`
+				Label string `json:"label"`
+			} `json:"section"`
+		} `json:"fields"`
+	}
+	found, err := get("/v1/vaults/"+url.PathEscape(vaultID)+"/items/"+url.PathEscape(itemID), nil, &item)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("no item found for '%s'", ref)
+	}
+	for _, field := range item.Fields {
+		if field.ID != fieldName && field.Label != fieldName {
+			continue
+		}
+		if sectionName != "" &&
+			(field.Section == nil || (field.Section.ID != sectionName && field.Section.Label != sectionName)) {
+			continue
+		}
+		return field.Value, nil
+	}
+	return "", fmt.Errorf("no field found for '%s'", ref)
+}
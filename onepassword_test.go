@@ -0,0 +1,143 @@
+package envstruct_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// fakeConnect serves the vault `prod` with the ID `v1` and the item `postgres` with the ID `i1`.
+func fakeConnect(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer connect-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		filter := r.URL.Query().Get("filter")
+		var resp any
+		switch r.URL.Path {
+		case "/v1/vaults":
+			resp = []map[string]string{}
+			if filter == `name eq "prod"` {
+				resp = []map[string]string{{"id": "v1", "name": "prod"}}
+			}
+		case "/v1/vaults/v1/items":
+			resp = []map[string]string{}
+			if filter == `title eq "postgres"` {
+				resp = []map[string]string{{"id": "i1", "title": "postgres"}}
+			}
+		case "/v1/vaults/v1/items/i1":
+			resp = map[string]any{"fields": []map[string]any{
+				{"id": "password", "label": "password", "value": "hunter2"},
+				{"id": "f2", "label": "password", "value": "replica-secret", "section": map[string]string{"id": "s1", "label": "replica"}},
+			}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOnePasswordConnect(t *testing.T) {
+	server := fakeConnect(t)
+	var cfg struct {
+		Password        string `env:"DB_PASSWORD,secret,op=op://prod/postgres/password"`
+		ReplicaPassword string `env:"REPLICA_PASSWORD,secret,op=op://v1/i1/replica/password"`
+	}
+	// fields with the op option are not read from the source chain
+	source := sources.Static(map[string]string{"DB_PASSWORD": "from-env"})
+	_, err := envstruct.Parse(&cfg,
+		envstruct.WithSources(source),
+		envstruct.WithOnePassword(envstruct.OnePasswordConfig{ConnectHost: server.URL, ConnectToken: "connect-token"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.ReplicaPassword != "replica-secret" {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestOnePasswordFails(t *testing.T) {
+	server := fakeConnect(t)
+	t.Setenv("OP_CONNECT_HOST", "")
+	t.Setenv("OP_SERVICE_ACCOUNT_TOKEN", "")
+	connect := envstruct.WithOnePassword(envstruct.OnePasswordConfig{ConnectHost: server.URL, ConnectToken: "connect-token"})
+	type config struct {
+		Password string `env:"DB_PASSWORD,op=op://prod/postgres/password"`
+	}
+	tests := []struct {
+		name string
+		cfg  any
+		opts []envstruct.Option
+	}{
+		{name: "not configured", cfg: &config{}},
+		{name: "no server", cfg: &config{}, opts: []envstruct.Option{envstruct.WithOnePassword(envstruct.OnePasswordConfig{})}},
+		{
+			name: "invalid reference",
+			cfg: &struct {
+				Password string `env:"DB_PASSWORD,op=op://prod/password"`
+			}{},
+			opts: []envstruct.Option{connect},
+		},
+		{
+			name: "missing item",
+			cfg: &struct {
+				Password string `env:"DB_PASSWORD,op=op://prod/mysql/password"`
+			}{},
+			opts: []envstruct.Option{connect},
+		},
+		{
+			name: "missing field",
+			cfg: &struct {
+				Password string `env:"DB_PASSWORD,op=op://prod/postgres/username"`
+			}{},
+			opts: []envstruct.Option{connect},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]envstruct.Option{envstruct.WithSources(sources.Static(nil))}, tt.opts...)
+			if _, err := envstruct.Parse(tt.cfg, opts...); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestOnePasswordServiceAccount(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake op CLI is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$OP_SERVICE_ACCOUNT_TOKEN\" = sa-token ] || exit 1\n" +
+		"[ \"$1 $2 $3\" = 'read --no-newline op://prod/postgres/password' ] || exit 1\nprintf hunter2\n"
+	if err := os.WriteFile(filepath.Join(dir, "op"), []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("OP_CONNECT_HOST", "")
+	t.Setenv("OP_SERVICE_ACCOUNT_TOKEN", "sa-token")
+
+	var cfg struct {
+		Password string `env:"DB_PASSWORD,secret,op=op://prod/postgres/password"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(nil)), envstruct.WithOnePassword(envstruct.OnePasswordConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" {
+		t.Errorf("got password %q", cfg.Password)
+	}
+}
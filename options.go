@@ -0,0 +1,143 @@
+package envstruct
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Option configures optional behavior of the functions in this package.
+type Option func(*options)
+
+type options struct {
+	omitSecrets       bool
+	omitZero          bool
+	separator         string
+	sources           []Source
+	errOnMissingValue bool
+	errorTemplates    *ErrorTemplates
+	ctx               context.Context
+	namedSources      map[string]Source
+	execEnabled       bool
+	execTimeout       time.Duration
+	onePassword       *OnePasswordConfig
+	validator         *validator.Validate
+	crossValidations  []func(cfg any) error
+	profileSelector   string
+	// profile is the profile selected by profileSelector while parsing.
+	profile      string
+	concurrency  int
+	scrubSecrets bool
+	decryptor    Decryptor
+	signature    *SignatureConfig
+	byteSecrets  bool
+	audit        AuditSink
+	metrics      *Metrics
+	health       *Health
+	// pollInterval, reloadErrorHandler and reloadRetry configure Watch.
+	pollInterval       time.Duration
+	reloadErrorHandler func(err error)
+	reloadRetry        RetryPolicy
+	// result is the parse result shown by Dump.
+	result *Result
+	// fastSet is set by Compile to let Parse set fields with setFieldFast.
+	fastSet bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSources sets the chain of sources Parse resolves values from. Each key is resolved from the
+// first source that has a value for it, so sources should be given from highest to lowest
+// precedence. If no sources are given the environment is used.
+func WithSources(sources ...Source) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, sources...)
+	}
+}
+
+// WithNamedSource registers `source` under `name` without adding it to the source chain. Fields
+// can select it with the `source` tag or tag option, e.g. `env:"DB_PASSWORD" source:"vault"` or
+// `env:"DB_PASSWORD,source=vault"`, and are then resolved only from that source, which keeps
+// secrets out of the environment entirely.
+//
+// Fields can also select a source from the chain by its name, or by the part of its name before
+// the first `:`, such as `ssm` for a source named `ssm:/my-service`.
+func WithNamedSource(name string, source Source) Option {
+	return func(o *options) {
+		if o.namedSources == nil {
+			o.namedSources = make(map[string]Source)
+		}
+		o.namedSources[name] = source
+	}
+}
+
+// WithContext sets the context passed to sources when values are looked up.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+func (o options) context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+// WithConcurrency makes Parse resolve the values of up to `n` fields at a time, so loading from
+// slow sources, such as sources that make a request per key, `exec` commands or `op` references,
+// takes about as long as the slowest lookup rather than the sum of all of them. Fields are still
+// set and errors reported in field order. All sources must be safe for concurrent use, which the
+// sources in this package are. Values of `n` below 2 resolve fields one at a time, the default.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithErrOnMissingValue makes Parse return an error for any field whose key has no value in any
+// source, like the `errOnMissingValue` flag of ParseStructFromEnv.
+func WithErrOnMissingValue() Option {
+	return func(o *options) {
+		o.errOnMissingValue = true
+	}
+}
+
+// WithOmitSecrets leaves fields tagged with the `secret` option out of generated output entirely
+// instead of writing them with a redacted value.
+func WithOmitSecrets() Option {
+	return func(o *options) {
+		o.omitSecrets = true
+	}
+}
+
+// WithOmitZero makes Marshal, and Setenv and Environ which use it, leave out fields with the zero
+// value, so their defaults apply when the values are parsed again.
+func WithOmitZero() Option {
+	return func(o *options) {
+		o.omitZero = true
+	}
+}
+
+// WithKeySeparator sets the separator used to join nested keys when config files are flattened
+// into environment variable style keys. The default is `_`.
+func WithKeySeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+func (o options) keySeparator() string {
+	if o.separator == "" {
+		return defaultKeySeparator
+	}
+	return o.separator
+}
@@ -0,0 +1,63 @@
+package envstruct
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OTelResource returns an OpenTelemetry resource with an attribute for every field of `cfg` that
+// has an `otel` tag naming the attribute, so telemetry is described by the same config that is
+// loaded from the environment:
+//
+//	type Config struct {
+//		Version string `env:"VERSION" otel:"service.version"`
+//		Env     string `env:"ENV" otel:"deployment.environment.name"`
+//	}
+//
+//	configRes, err := OTelResource(&cfg)
+//	...
+//	res, err := resource.Merge(resource.Default(), configRes)
+//
+// Strings are added as string attributes, ints and bools as int and bool attributes, and other
+// types as their text form. Blank strings are left out. It is an error to tag a secret field, so
+// secrets never end up in telemetry.
+func OTelResource(cfg any) (res *resource.Resource, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in OTelResource: %w", err)
+		}
+	}()
+	var attrs []attribute.KeyValue
+	walkFields(structValue(cfg), "", func(f boundField) {
+		name := f.field.Tag.Get("otel")
+		if name == "" || err != nil {
+			return
+		}
+		if f.tag.has("secret") {
+			err = fmt.Errorf("secret field '%s' can not be an OpenTelemetry attribute", f.path)
+			return
+		}
+		switch f.value.Kind() {
+		case reflect.Int:
+			attrs = append(attrs, attribute.Int64(name, f.value.Int()))
+			return
+		case reflect.Bool:
+			attrs = append(attrs, attribute.Bool(name, f.value.Bool()))
+			return
+		}
+		value, ok := formatValue(f.value)
+		if !ok {
+			value = fmt.Sprint(f.value.Interface())
+		}
+		if value != "" {
+			attrs = append(attrs, attribute.String(name, value))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewSchemaless(attrs...), nil
+}
@@ -0,0 +1,51 @@
+package envstruct_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestOTelResource(t *testing.T) {
+	cfg := struct {
+		Service  string        `env:"SERVICE_NAME" otel:"service.name"`
+		Version  string        `env:"VERSION" otel:"service.version"`
+		Env      string        `env:"ENV" otel:"deployment.environment.name"`
+		Replicas int           `env:"REPLICAS" otel:"service.replicas"`
+		Canary   bool          `env:"CANARY" otel:"service.canary"`
+		Timeout  time.Duration `env:"TIMEOUT" otel:"service.timeout"`
+		Host     string        `env:"DB_HOST"`
+	}{Service: "orders", Version: "1.2.3", Replicas: 3, Timeout: 30 * time.Second, Host: "db.internal"}
+
+	res, err := envstruct.OTelResource(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blank strings and fields without an otel tag are left out, and attributes are sorted by key
+	want := []attribute.KeyValue{
+		attribute.Bool("service.canary", false),
+		attribute.String("service.name", "orders"),
+		attribute.Int64("service.replicas", 3),
+		attribute.String("service.timeout", "30s"),
+		attribute.String("service.version", "1.2.3"),
+	}
+	if got := res.Attributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if res.SchemaURL() != "" {
+		t.Errorf("got schema URL %q, want a schemaless resource", res.SchemaURL())
+	}
+}
+
+func TestOTelResourceSecret(t *testing.T) {
+	cfg := struct {
+		Token envstruct.Secret[string] `env:"API_TOKEN" otel:"service.token"`
+	}{Token: envstruct.NewSecret("token")}
+	if _, err := envstruct.OTelResource(&cfg); err == nil {
+		t.Fatal("expected an error for a secret attribute")
+	}
+}
@@ -0,0 +1,367 @@
+package envstruct
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Result holds information about a successful or partially successful call to Parse.
+type Result struct {
+	// Sources maps the dotted path of every field that was set to the name of the source its
+	// value was resolved from.
+	Sources map[string]string
+	// Warnings holds the validation failures of fields tagged with the `severity=warn` option,
+	// which do not fail the parse.
+	Warnings ParseErrors
+	// Fields describes where the value of every field that was set came from, in field order.
+	Fields []Resolution
+	// Degraded holds the names of the sources that were skipped, at least in part, because their
+	// circuit breaker was open. See WithSourceBreaker.
+	Degraded []string
+}
+
+// Resolution describes where the value of a field came from, to answer "where did this value come
+// from" without guesswork.
+type Resolution struct {
+	// Field is the dotted path of the field within the top level struct.
+	Field string `json:"field"`
+	// Key is the key the value was found under. It differs from the key of the field when a
+	// profile specific key such as `DB_HOST_PROD` was used, and is blank for defaults.
+	Key string `json:"key,omitempty"`
+	// Source is the name of the source the value came from, as in `Result.Sources`.
+	Source string `json:"source"`
+	// Origin is where within the source the key was found, if the source reports it, such as the
+	// file of a DotEnv source the value was read from.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Parse works like ParseStructFromEnv but resolves values from the sources given with
+// WithSources, or from the environment if no sources are given:
+//
+//	res, err := Parse(&cfg, WithSources(Env(), DotEnv(".env"), File("config.yaml")))
+//
+// Each key is resolved from the first source in the chain that has a non-blank value for it, and
+// the winning source is recorded per field in the returned `Result`. Missing values only result in
+// an error when WithErrOnMissingValue is used.
+func Parse(obj any, opts ...Option) (res Result, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Parse: %w", err)
+		}
+	}()
+	p := newParser(newOptions(opts))
+	if p.opts.metrics != nil {
+		defer func() {
+			p.opts.metrics.observeLoad(structValue(obj), err)
+		}()
+	}
+	if p.opts.health != nil {
+		defer func() {
+			p.opts.health.observe(err)
+		}()
+	}
+	if p.opts.audit != nil {
+		before := auditSnapshot(structValue(obj))
+		defer func() {
+			p.audit(structValue(obj), before, err)
+		}()
+	}
+	if p.opts.fastSet && fastSetAvailable {
+		if val := reflect.ValueOf(obj); val.Kind() == reflect.Ptr {
+			p.base = val.UnsafePointer()
+		}
+	}
+	if err := p.opts.applyProfile(); err != nil {
+		return p.res, err
+	}
+	if err := p.opts.prefetch(); err != nil {
+		return p.res, err
+	}
+	if err := p.opts.prefetchKeys(structValue(obj)); err != nil {
+		return p.res, err
+	}
+	if p.opts.signature != nil {
+		signed, err := p.opts.verifySignature(structValue(obj))
+		if err != nil {
+			return p.res, err
+		}
+		p.signed = signed
+	}
+	p.parseStruct(structValue(obj), "")
+	p.res.Degraded = p.opts.degradedSources()
+	p.validateStruct(structValue(obj), "")
+	if err := p.runValidator(obj); err != nil {
+		return p.res, err
+	}
+	p.runCrossValidations(obj)
+	if len(p.errs) > 0 {
+		return p.res, p.errs
+	}
+	if p.opts.scrubSecrets {
+		if err := p.opts.scrubEnv(structValue(obj)); err != nil {
+			return p.res, err
+		}
+	}
+	return p.res, nil
+}
+
+// parser holds the state of a single parse.
+type parser struct {
+	opts options
+	res  Result
+	errs ParseErrors
+	// base points to the struct being parsed if fields may be set with setFieldFast.
+	base unsafe.Pointer
+	// signed holds the values of the fields tagged with the `signed` option by path, as resolved
+	// when their signature was verified.
+	signed map[string]resolved
+}
+
+func newParser(opts options) *parser {
+	return &parser{
+		opts: opts,
+		res:  Result{Sources: make(map[string]string)},
+	}
+}
+
+// parseStruct sets every field of `val` that has an `env` tag. `path` is the dotted field path of
+// `val` from the top level struct and is used to identify fields in errors.
+//
+// Fields whose key has no value are set from their `default` tag if they have one, e.g.
+// `env:"PORT" default:"8080"`, which is recorded as the source `default`. Fields with the
+// `required` tag option fail if they have no value and no default, even without
+// WithErrOnMissingValue.
+func (p *parser) parseStruct(val reflect.Value, path string) {
+	if p.opts.concurrency > 1 {
+		p.parseStructConcurrently(val, path)
+		return
+	}
+	walkFields(val, path, func(f boundField) {
+		if !f.value.CanSet() {
+			return
+		}
+		res, err := p.resolveValue(f)
+		p.bindField(f, res, err)
+	})
+}
+
+// resolveValue resolves the value of `f`. Signed fields are bound to the values their signature
+// was verified against rather than looked up again, so a source can not return a different value
+// after the signature was checked.
+func (p *parser) resolveValue(f boundField) (resolved, error) {
+	if res, ok := p.signed[f.path]; ok {
+		return p.opts.decryptValue(f, res)
+	}
+	return p.opts.resolveValue(f)
+}
+
+// parseStructConcurrently works like parseStruct but resolves the values of up to
+// `p.opts.concurrency` fields at a time. Fields are still set, and errors reported, in field order.
+func (p *parser) parseStructConcurrently(val reflect.Value, path string) {
+	var fields []boundField
+	walkFields(val, path, func(f boundField) {
+		if f.value.CanSet() {
+			fields = append(fields, f)
+		}
+	})
+
+	type result struct {
+		res resolved
+		err error
+	}
+	results := make([]result, len(fields))
+	sem := make(chan struct{}, p.opts.concurrency)
+	var wg sync.WaitGroup
+	for i, f := range fields {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res, err := p.resolveValue(f)
+			results[i] = result{res: res, err: err}
+		}()
+	}
+	wg.Wait()
+
+	for i, f := range fields {
+		p.bindField(f, results[i].res, results[i].err)
+	}
+}
+
+// bindField sets `f` from the resolved value, recording where it came from or the error.
+func (p *parser) bindField(f boundField, res resolved, err error) {
+	key := f.tag.key
+	value, source := res.value, res.source
+	if p.opts.byteSecrets {
+		if fieldErr := checkByteSecret(f); fieldErr != nil {
+			p.errs = append(p.errs, fieldErr)
+			return
+		}
+	}
+	if err != nil {
+		// declared here so it only escapes to the heap when there is an error
+		var decryptErr *decryptError
+		if errors.As(err, &decryptErr) {
+			p.errs = append(p.errs, &FieldError{
+				Field:  f.path,
+				Key:    key,
+				Reason: ReasonParse,
+				Hint:   fmt.Sprintf("check that '%s' is encrypted for the configured decryptor", key),
+				Err:    fmt.Errorf("error reading '%s' from source '%s': %w", key, source, err),
+			})
+			return
+		}
+		p.errs = append(p.errs, &FieldError{
+			Field:  f.path,
+			Key:    key,
+			Reason: ReasonSource,
+			Hint:   fmt.Sprintf("check that source '%s' is available", source),
+			Err:    err,
+		})
+		return
+	}
+	if value == "" {
+		if def, ok := f.field.Tag.Lookup("default"); ok {
+			value, res = def, resolved{source: "default"}
+		}
+	}
+	if p.opts.scrubSecrets && f.tag.has("secret") {
+		// the value may share memory with the environment, which is overwritten when scrubbing
+		value = strings.Clone(value)
+	}
+	errIfMissing := p.opts.errOnMissingValue || f.tag.has("required")
+	if value == "" && !errIfMissing {
+		return
+	}
+	var ok bool
+	if p.base != nil && f.fast {
+		ok, err = setFieldFast(unsafe.Add(p.base, f.offset), f.field.Type, key, value, errIfMissing)
+	} else {
+		ok, err = setTaggedField(f.value, f.tag, key, value, errIfMissing)
+	}
+	if err != nil {
+		p.errs = append(p.errs, newFieldError(f.path, key, err, p.opts.errorTemplates))
+		return
+	}
+	if ok {
+		p.record(f, res)
+	}
+}
+
+// record records that `f` was set from `res`.
+func (p *parser) record(f boundField, res resolved) {
+	p.res.Sources[f.path] = res.source
+	p.res.Fields = append(p.res.Fields, Resolution{
+		Field:  f.path,
+		Key:    res.key,
+		Source: res.source,
+		Origin: res.origin,
+	})
+}
+
+// setTaggedField converts `value` and sets it on `field` according to the options of `tag`, such
+// as `parseurl`. It reports false for types that are not supported.
+func setTaggedField(field reflect.Value, tag envTag, key, value string, errIfMissing bool) (bool, error) {
+	switch {
+	case tag.has("parseurl") && field.Kind() != reflect.Struct:
+		return false, &envVarError{
+			reason:   ReasonTag,
+			key:      key,
+			typeName: field.Type().String(),
+			err:      errors.New("the parseurl option requires a struct field"),
+		}
+	case tag.has("parseurl"):
+		return true, setURLFields(field, key, value, errIfMissing)
+	case tag.has("quantity"):
+		return true, setQuantityField(field, key, value, tag.get("quantity"), errIfMissing)
+	}
+	return setField(field, key, value, errIfMissing)
+}
+
+// setField converts `value` and sets it on `field`. Types implementing
+// `encoding.TextUnmarshaler`, such as Schedule, are set with UnmarshalText, `time.Duration` fields
+// with `time.ParseDuration`, and slices and maps as described by setListField and setMapField. It
+// reports false for kinds that are not supported.
+func setField(field reflect.Value, key, value string, errIfMissing bool) (bool, error) {
+	if unmarshaler, ok := textUnmarshaler(field); ok {
+		if errIfMissing && value == "" {
+			return false, &envVarError{reason: ReasonMissing, key: key, typeName: field.Type().String()}
+		}
+		text := []byte(value)
+		err := unmarshaler.UnmarshalText(text)
+		if isSecretType(field.Type()) {
+			// UnmarshalText copies what it keeps, so this copy of the secret can be cleared
+			clear(text)
+		}
+		if err != nil {
+			return false, &envVarError{reason: ReasonParse, key: key, typeName: field.Type().String(), err: err}
+		}
+		return true, nil
+	}
+
+	if field.Type() == durationType {
+		converted, err := getEnvDuration(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		field.SetInt(int64(converted))
+		return true, nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		converted, err := getEnvString(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		field.SetString(converted)
+	case reflect.Int:
+		converted, err := getEnvInt64(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		field.SetInt(converted)
+	case reflect.Bool:
+		converted, err := getEnvBool(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		field.SetBool(converted)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return setListField(field, key, value, errIfMissing)
+		}
+		converted, err := getEnvString(key, value, errIfMissing)
+		if err != nil {
+			return false, err
+		}
+		field.SetBytes([]byte(converted))
+	case reflect.Map:
+		return setMapField(field, key, value, errIfMissing)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// textUnmarshaler returns `field` as an `encoding.TextUnmarshaler` if its pointer implements it.
+func textUnmarshaler(field reflect.Value) (encoding.TextUnmarshaler, bool) {
+	// predeclared types such as int have no methods, which saves the interface check for most fields
+	if t := field.Type(); t.PkgPath() == "" && t.Name() != "" {
+		return nil, false
+	}
+	if !field.CanAddr() || !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return nil, false
+	}
+	return field.Addr().Interface().(encoding.TextUnmarshaler), true
+}
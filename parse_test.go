@@ -0,0 +1,55 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// wideStruct returns a struct type with `n` string, int and bool fields tagged `FIELD_<i>`, and a
+// value for every one of them.
+func wideStruct(n int) (reflect.Type, map[string]string) {
+	fields := make([]reflect.StructField, n)
+	values := make(map[string]string, n)
+	for i := range fields {
+		key := fmt.Sprintf("FIELD_%03d", i)
+		var typ reflect.Type
+		switch i % 3 {
+		case 0:
+			typ, values[key] = reflect.TypeFor[string](), "value-"+strconv.Itoa(i)
+		case 1:
+			typ, values[key] = reflect.TypeFor[int](), strconv.Itoa(i)
+		case 2:
+			typ, values[key] = reflect.TypeFor[bool](), "true"
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%03d", i),
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf(`env:"%s"`, key)),
+		}
+	}
+	return reflect.StructOf(fields), values
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			typ, values := wideStruct(n)
+			source := newMapSource("values", func(context.Context) (map[string]string, error) {
+				return values, nil
+			})
+			cfg := reflect.New(typ).Interface()
+			if _, err := Parse(cfg, WithSources(source)); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := Parse(cfg, WithSources(source)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package envstruct
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// PollPolicy configures how often WithSourcePolling polls a source.
+type PollPolicy struct {
+	// Interval is the time between polls. Defaults to 1 minute.
+	Interval time.Duration
+	// Jitter is the longest random time added to every interval, so replicas that started
+	// together do not all poll the backend at once.
+	Jitter time.Duration
+}
+
+func (p PollPolicy) withDefaults() PollPolicy {
+	if p.Interval <= 0 {
+		p.Interval = time.Minute
+	}
+	return p
+}
+
+// next returns the time until the next poll.
+func (p PollPolicy) next() time.Duration {
+	if p.Jitter <= 0 {
+		return p.Interval
+	}
+	return p.Interval + rand.N(p.Jitter)
+}
+
+// WithSourcePolling returns a source that reports changes of `source` by polling it, for sources
+// that can not watch for changes themselves, such as SSM, HTTP endpoints or Consul without
+// blocking queries. Every poll makes the source load its values again and looks up the keys that
+// were looked up through the returned source since it was created; if any of them changed, the
+// returned source, which implements Notifier, signals the change to Watch or Store.Watch:
+//
+//	ssm := WithSourcePolling(SSM(SSMConfig{Path: "/my-service/prod"}), PollPolicy{
+//		Interval: 5 * time.Minute,
+//		Jitter:   30 * time.Second,
+//	})
+//	go store.Watch(ctx)
+//
+// Each source can be polled at its own interval, unlike WithPollInterval which reloads every
+// source at once. Polling runs while Watch or Store.Watch watches the returned source and stops
+// when their context is done, and errors while polling are ignored until the next poll. If
+// `source` implements Notifier its changes are forwarded too.
+//
+// The returned source is recorded in a `Result` under the name of `source`.
+func WithSourcePolling(source Source, policy PollPolicy) Source {
+	return &pollSource{
+		source:  source,
+		policy:  policy.withDefaults(),
+		values:  make(map[string]polledValue),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// pollSource is the Source returned by WithSourcePolling.
+type pollSource struct {
+	source Source
+	policy PollPolicy
+
+	mu sync.Mutex
+	// values are the last values looked up by key.
+	values map[string]polledValue
+
+	changed chan struct{}
+}
+
+type polledValue struct {
+	value string
+	ok    bool
+}
+
+func (s *pollSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	value, ok, err := s.source.Lookup(ctx, key)
+	if err == nil {
+		s.mu.Lock()
+		s.values[key] = polledValue{value: value, ok: ok}
+		s.mu.Unlock()
+	}
+	return value, ok, err
+}
+
+func (s *pollSource) Prefetch(ctx context.Context) error {
+	if prefetcher, ok := s.source.(Prefetcher); ok {
+		return prefetcher.Prefetch(ctx)
+	}
+	return nil
+}
+
+func (s *pollSource) PrefetchKeys(ctx context.Context, keys []string) error {
+	if prefetcher, ok := s.source.(KeyPrefetcher); ok {
+		return prefetcher.PrefetchKeys(ctx, keys)
+	}
+	return nil
+}
+
+// Changed returns a channel that receives a value whenever a poll found a change, or the polled
+// source reported one.
+func (s *pollSource) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// startWatching polls the source, and forwards the changes it reports, until `ctx` is done.
+func (s *pollSource) startWatching(ctx context.Context) {
+	if src, ok := s.source.(interface{ startWatching(ctx context.Context) }); ok {
+		src.startWatching(ctx)
+	}
+	if notifier, ok := s.source.(Notifier); ok {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-notifier.Changed():
+					if !ok {
+						return
+					}
+					s.notify()
+				}
+			}
+		}()
+	}
+	go func() {
+		timer := time.NewTimer(s.policy.next())
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			if s.poll(ctx) {
+				s.notify()
+			}
+			timer.Reset(s.policy.next())
+		}
+	}()
+}
+
+// poll reloads the source and reports whether any of the keys looked up so far changed.
+func (s *pollSource) poll(ctx context.Context) bool {
+	if src, ok := s.source.(interface{ reload() }); ok {
+		src.reload()
+	}
+	s.mu.Lock()
+	keys := sortedKeys(s.values)
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.policy.Interval)
+	defer cancel()
+	changed := false
+	for _, key := range keys {
+		value, ok, err := s.source.Lookup(ctx, key)
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		if s.values[key] != (polledValue{value: value, ok: ok}) {
+			s.values[key] = polledValue{value: value, ok: ok}
+			changed = true
+		}
+		s.mu.Unlock()
+	}
+	return changed
+}
+
+// notify signals a change without blocking.
+func (s *pollSource) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *pollSource) String() string {
+	return SourceName(s.source)
+}
+
+func (s *pollSource) limitedToSecrets() bool {
+	src, ok := s.source.(interface{ limitedToSecrets() bool })
+	return ok && src.limitedToSecrets()
+}
+
+func (s *pollSource) watchedFiles() []string {
+	src, ok := s.source.(interface{ watchedFiles() []string })
+	if !ok {
+		return nil
+	}
+	return src.watchedFiles()
+}
+
+func (s *pollSource) reload() {
+	if src, ok := s.source.(interface{ reload() }); ok {
+		src.reload()
+	}
+}
@@ -0,0 +1,101 @@
+package envstruct_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// quietSource is a source that can change without reporting it, like a remote backend.
+type quietSource struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *quietSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func (s *quietSource) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+type pollConfig struct {
+	Level string `env:"LOG_LEVEL"`
+}
+
+func TestWithSourcePolling(t *testing.T) {
+	backend := &quietSource{values: map[string]string{"LOG_LEVEL": "info"}}
+	source := envstruct.WithSourcePolling(backend, envstruct.PollPolicy{
+		Interval: 10 * time.Millisecond,
+		Jitter:   5 * time.Millisecond,
+	})
+	store, err := envstruct.NewStore(pollConfig{}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	backend.set("LOG_LEVEL", "debug")
+	waitFor(t, "the polled change", func() bool {
+		return store.Load().Level == "debug"
+	})
+}
+
+func TestWithSourcePollingForwardsChanges(t *testing.T) {
+	backend := sources.Static(map[string]string{"LOG_LEVEL": "info"})
+	source := envstruct.WithSourcePolling(backend, envstruct.PollPolicy{Interval: time.Hour})
+	store, err := envstruct.NewStore(pollConfig{}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	backend.Set("LOG_LEVEL", "debug")
+	waitFor(t, "the forwarded change", func() bool {
+		return store.Load().Level == "debug"
+	})
+}
+
+func TestWithSourcePollingStopsWithContext(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for range 10 {
+		backend := sources.Static(map[string]string{"LOG_LEVEL": "info"})
+		source := envstruct.WithSourcePolling(backend, envstruct.PollPolicy{Interval: time.Millisecond})
+		store, err := envstruct.NewStore(pollConfig{}, envstruct.WithSources(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- store.Watch(ctx)
+		}()
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "the polling goroutines to stop", func() bool {
+		return runtime.NumGoroutine() <= before
+	})
+}
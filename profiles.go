@@ -0,0 +1,58 @@
+package envstruct
+
+import (
+	"strings"
+)
+
+// WithProfiles selects a profile, such as `dev`, `staging` or `prod`, from the value of the
+// `selector` key, e.g. `APP_ENV`. The selector is resolved from the source chain like any other
+// key. When a profile is selected:
+//
+//   - the dotenv files returned by DotEnvFiles for the profile (`.env`, `.env.local`,
+//     `.env.<profile>` and `.env.<profile>.local`) are added to the end of the source chain
+//   - every key is first looked up with the upper cased profile as a suffix and then as a prefix
+//     before the plain key is used, so with the profile `prod` the key `DB_HOST` is resolved from
+//     `DB_HOST_PROD`, then `PROD_DB_HOST`, then `DB_HOST`
+//
+// If the selector has no value, no profile is applied.
+func WithProfiles(selector string) Option {
+	return func(o *options) {
+		o.profileSelector = selector
+	}
+}
+
+// applyProfile resolves the profile selector and updates the options for the selected profile.
+func (o *options) applyProfile() error {
+	if o.profileSelector == "" {
+		return nil
+	}
+	var profile string
+	for _, source := range o.sourceChain() {
+		value, _, err := o.lookup(source, SourceName(source), o.profileSelector)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			profile = value
+			break
+		}
+	}
+	if profile == "" {
+		return nil
+	}
+
+	o.profile = profile
+	o.sources = append(o.sourceChain(), DotEnv(DotEnvFiles(profile)...))
+	return nil
+}
+
+// appendCandidateKeys appends the keys to look up for `key` to `dst` in order, including the
+// profile specific variants when a profile is selected. Callers pass a small array so no slice is
+// allocated per field.
+func (o options) appendCandidateKeys(dst []string, key string) []string {
+	if o.profile == "" {
+		return append(dst, key)
+	}
+	profile := strings.ToUpper(o.profile)
+	return append(dst, key+"_"+profile, profile+"_"+key, key)
+}
@@ -0,0 +1,56 @@
+package envstruct_test
+
+import (
+	"os"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type profileConfig struct {
+	Host   string `env:"DB_HOST"`
+	Port   int    `env:"DB_PORT"`
+	Level  string `env:"LOG_LEVEL"`
+	Region string `env:"REGION"`
+}
+
+func TestProfiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env.prod", []byte("REGION=eu-west-1\nLOG_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := sources.Static(map[string]string{
+		"APP_ENV":      "prod",
+		"DB_HOST":      "localhost",
+		"DB_HOST_PROD": "db.prod.internal",
+		"DB_PORT":      "5432",
+		"PROD_DB_PORT": "6543",
+		"LOG_LEVEL":    "warn",
+	})
+	var cfg profileConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithProfiles("APP_ENV")); err != nil {
+		t.Fatal(err)
+	}
+	// suffixed keys win over prefixed keys, which win over plain keys, and the profile's dotenv
+	// files come after the rest of the chain
+	want := profileConfig{Host: "db.prod.internal", Port: 6543, Level: "warn", Region: "eu-west-1"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestProfilesNotSelected(t *testing.T) {
+	t.Chdir(t.TempDir())
+	source := sources.Static(map[string]string{"DB_HOST": "localhost", "DB_HOST_PROD": "db.prod.internal"})
+	var cfg profileConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithProfiles("APP_ENV")); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("got host %q, want the plain key without a profile", cfg.Host)
+	}
+	if got := source.Lookups("DB_HOST_PROD"); got != 0 {
+		t.Errorf("got %d lookups of a profile key, want 0", got)
+	}
+}
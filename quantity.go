@@ -0,0 +1,133 @@
+package envstruct
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+)
+
+// quantityPattern splits a Kubernetes quantity into its number and suffix.
+var quantityPattern = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))([eE][+-]?[0-9]+|[a-zA-Z]*)$`)
+
+// quantitySuffixes maps the suffixes of Kubernetes quantities to their multipliers.
+var quantitySuffixes = map[string]*big.Rat{
+	"n":  big.NewRat(1, 1e9),
+	"u":  big.NewRat(1, 1e6),
+	"m":  big.NewRat(1, 1e3),
+	"":   big.NewRat(1, 1),
+	"k":  big.NewRat(1e3, 1),
+	"M":  big.NewRat(1e6, 1),
+	"G":  big.NewRat(1e9, 1),
+	"T":  big.NewRat(1e12, 1),
+	"P":  big.NewRat(1e15, 1),
+	"E":  big.NewRat(1e18, 1),
+	"Ki": big.NewRat(1<<10, 1),
+	"Mi": big.NewRat(1<<20, 1),
+	"Gi": big.NewRat(1<<30, 1),
+	"Ti": big.NewRat(1<<40, 1),
+	"Pi": big.NewRat(1<<50, 1),
+	"Ei": big.NewRat(1<<60, 1),
+}
+
+// maxQuantityExponentLen is the number of digits and sign allowed in the exponent of a quantity,
+// which is enough for any value a float64 can hold.
+const maxQuantityExponentLen = 4
+
+// parseQuantity parses a Kubernetes resource quantity such as `500m`, `2Gi` or `1e3` exactly.
+func parseQuantity(s string) (*big.Rat, error) {
+	match := quantityPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid quantity '%s'", s)
+	}
+	number, ok := new(big.Rat).SetString(match[1])
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity '%s'", s)
+	}
+
+	suffix := match[2]
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		// a decimal exponent, e.g. `1e3`, limited so huge exponents can not exhaust memory
+		if len(suffix) > maxQuantityExponentLen+1 {
+			return nil, fmt.Errorf("quantity '%s' is out of range", s)
+		}
+		exponent, ok := new(big.Rat).SetString("1" + suffix)
+		if !ok {
+			return nil, fmt.Errorf("invalid quantity '%s'", s)
+		}
+		return number.Mul(number, exponent), nil
+	}
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity suffix '%s'", suffix)
+	}
+	return number.Mul(number, multiplier), nil
+}
+
+// setQuantityField parses `value` as a Kubernetes resource quantity and sets it on the number
+// `field`, for fields tagged with the `quantity` option, so limits can use familiar units:
+//
+//	MemoryLimit int64   `env:"MEMORY_LIMIT,quantity"`       // 2Gi is 2147483648
+//	CPU         float64 `env:"CPU,quantity"`                // 500m is 0.5
+//	CPUMillis   int     `env:"CPU_MILLIS,quantity=milli"`   // 500m is 500
+//
+// Integer fields are rounded up, like `resource.Quantity.Value`, and `quantity=milli` sets them
+// to the value in thousandths instead, like `resource.Quantity.MilliValue`.
+func setQuantityField(field reflect.Value, key, value, scale string, errIfMissing bool) error {
+	typeName := field.Type().String()
+	if value == "" {
+		if errIfMissing {
+			return &envVarError{reason: ReasonMissing, key: key, typeName: typeName}
+		}
+		return nil
+	}
+	parseErr := func(err error) error {
+		return &envVarError{reason: ReasonParse, key: key, typeName: typeName, err: err}
+	}
+
+	quantity, err := parseQuantity(value)
+	if err != nil {
+		return parseErr(err)
+	}
+	switch scale {
+	case "":
+	case "milli":
+		quantity.Mul(quantity, big.NewRat(1000, 1))
+	default:
+		return parseErr(fmt.Errorf("unknown quantity scale '%s'", scale))
+	}
+
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		converted, _ := quantity.Float64()
+		if field.OverflowFloat(converted) {
+			return parseErr(fmt.Errorf("quantity '%s' is out of range", value))
+		}
+		field.SetFloat(converted)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rounded := ceilRat(quantity)
+		if !rounded.IsInt64() || field.OverflowInt(rounded.Int64()) {
+			return parseErr(fmt.Errorf("quantity '%s' is out of range", value))
+		}
+		field.SetInt(rounded.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rounded := ceilRat(quantity)
+		if !rounded.IsUint64() || field.OverflowUint(rounded.Uint64()) {
+			return parseErr(fmt.Errorf("quantity '%s' is out of range", value))
+		}
+		field.SetUint(rounded.Uint64())
+	default:
+		return parseErr(errors.New("the quantity option requires a number field"))
+	}
+	return nil
+}
+
+// ceilRat rounds `r` up to an integer.
+func ceilRat(r *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
@@ -0,0 +1,76 @@
+package envstruct_test
+
+import (
+	"errors"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestQuantity(t *testing.T) {
+	tests := []struct {
+		value      string
+		int64Value int64
+		milliValue int
+		floatValue float64
+	}{
+		{"2Gi", 2 << 30, 2 << 30 * 1000, 2 << 30},
+		{"500m", 1, 500, 0.5},
+		{"1.5k", 1500, 1500000, 1500},
+		{"1e3", 1000, 1000000, 1000},
+		{"100M", 100000000, 100000000000, 1e8},
+		{"250u", 1, 1, 0.00025},
+		{"-0.5", 0, -500, -0.5},
+		{"3", 3, 3000, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var cfg struct {
+				Memory    int64   `env:"QUANTITY,quantity"`
+				CPUMillis int     `env:"QUANTITY,quantity=milli"`
+				CPU       float64 `env:"QUANTITY,quantity"`
+			}
+			source := sources.Static(map[string]string{"QUANTITY": tt.value})
+			if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Memory != tt.int64Value || cfg.CPUMillis != tt.milliValue || cfg.CPU != tt.floatValue {
+				t.Errorf("got %+v, want %d, %d and %v", cfg, tt.int64Value, tt.milliValue, tt.floatValue)
+			}
+		})
+	}
+}
+
+func TestQuantityErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  any
+	}{
+		{"invalid", &struct {
+			V int64 `env:"QUANTITY,quantity"`
+		}{}},
+		{"out of range", &struct {
+			V uint8 `env:"LARGE,quantity"`
+		}{}},
+		{"negative unsigned", &struct {
+			V uint `env:"NEGATIVE,quantity"`
+		}{}},
+		{"unknown scale", &struct {
+			V int `env:"LARGE,quantity=micro"`
+		}{}},
+		{"not a number", &struct {
+			V string `env:"LARGE,quantity"`
+		}{}},
+	}
+	source := sources.Static(map[string]string{"QUANTITY": "2Gb", "LARGE": "1Ki", "NEGATIVE": "-1"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := envstruct.Parse(tt.cfg, envstruct.WithSources(source))
+			var fieldErr *envstruct.FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonParse {
+				t.Fatalf("got error %v, want a parse error", err)
+			}
+		})
+	}
+}
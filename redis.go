@@ -0,0 +1,157 @@
+package envstruct
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig configures the Redis source.
+type RedisConfig struct {
+	// Addr is the `host:port` of the Redis server. Defaults to `localhost:6379`.
+	Addr string
+	// Username and Password authenticate with the server, if set.
+	Username string
+	Password string
+	// DB is the database to select.
+	DB int
+	// TLSConfig enables TLS for the connection if set.
+	TLSConfig *tls.Config
+	// Hash is the key of a hash whose fields hold the values. If empty, the string keys starting
+	// with Prefix are read instead.
+	Hash string
+	// Prefix selects the string keys to read when Hash is empty, e.g. `my-service:`. It is removed
+	// from the keys.
+	Prefix string
+	// InvalidationChannel is a pub/sub channel to subscribe to. Any message published to it
+	// causes the values to be read again on the next lookup.
+	InvalidationChannel string
+	// Client is used instead of connecting to Addr if set, e.g. a client created with
+	// `redis.NewFailoverClient` for Sentinel. Cluster clients are not supported.
+	Client *redis.Client
+}
+
+// Redis returns a source that reads values pushed into Redis, either from the fields of a hash or
+// from string keys sharing a prefix. Names are flattened like config file keys, with `:` treated
+// as a path separator, so with the prefix `my-service:` the key `my-service:db:host` is resolved
+// as `DB_HOST`, as is a hash field named `db.host`.
+//
+// The values are read the first time a key is looked up. If InvalidationChannel is set, the
+// source subscribes to it at that point and reads the values again after any message is
+// published to it, so configuration can be changed at runtime with e.g.
+// `PUBLISH my-service:invalidate 1`. The source implements Notifier and signals each message.
+func Redis(cfg RedisConfig) Source {
+	client := cfg.Client
+	if client == nil {
+		addr := cfg.Addr
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		client = redis.NewClient(&redis.Options{
+			Addr:      addr,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			TLSConfig: cfg.TLSConfig,
+		})
+	}
+
+	name := "redis:" + cfg.Hash
+	if cfg.Hash == "" {
+		name = "redis:" + cfg.Prefix + "*"
+	}
+
+	var (
+		mu         sync.Mutex
+		values     map[string]string
+		subscribed bool
+	)
+	source := newNotifySource(&funcSource{name: name})
+	load := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if values != nil {
+			return nil
+		}
+		if cfg.InvalidationChannel != "" && !subscribed {
+			// subscribe before reading so no update published in between is missed
+			pubsub := client.Subscribe(context.Background(), cfg.InvalidationChannel)
+			if _, err := pubsub.Receive(ctx); err != nil {
+				pubsub.Close()
+				return err
+			}
+			subscribed = true
+			go func() {
+				for range pubsub.Channel() {
+					mu.Lock()
+					values = nil
+					mu.Unlock()
+					source.notify()
+				}
+			}()
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		var err error
+		if cfg.Hash != "" {
+			values, err = readRedisHash(ctx, client, cfg.Hash)
+		} else {
+			values, err = readRedisKeys(ctx, client, cfg.Prefix)
+		}
+		return err
+	}
+
+	source.prefetch = load
+	source.lookup = func(ctx context.Context, key string) (string, bool, error) {
+		if err := load(ctx); err != nil {
+			return "", false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value, ok := values[key]
+		return value, ok, nil
+	}
+	return source
+}
+
+// readRedisHash reads the fields of the hash `hash`.
+func readRedisHash(ctx context.Context, client *redis.Client, hash string) (map[string]string, error) {
+	fields, err := client.HGetAll(ctx, hash).Result()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(fields))
+	for field, value := range fields {
+		values[flattenPath(field, ":", defaultKeySeparator)] = value
+	}
+	return values, nil
+}
+
+// readRedisKeys reads every string key starting with `prefix`.
+func readRedisKeys(ctx context.Context, client *redis.Client, prefix string) (map[string]string, error) {
+	values := make(map[string]string)
+	iter := client.ScanType(ctx, 0, prefix+"*", 100, "string").Iterator()
+	for iter.Next(ctx) {
+		redisKey := iter.Val()
+		value, err := client.Get(ctx, redisKey).Result()
+		if errors.Is(err, redis.Nil) {
+			// deleted since it was scanned
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[flattenPath(strings.TrimPrefix(redisKey, prefix), ":", defaultKeySeparator)] = value
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,76 @@
+package envstruct_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestRedisHash(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.HSet("my-service", "db.host", "db.internal", "db:port", "5432", "log_level", "info")
+	var cfg remoteConfig
+	source := envstruct.Redis(envstruct.RedisConfig{Addr: server.Addr(), Hash: "my-service"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432, Level: "info"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestRedisKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.Set("my-service:db:host", "db.internal")
+	server.Set("my-service:db:port", "5432")
+	server.Set("other-service:log_level", "debug")
+	server.HSet("my-service:log_level", "ignored", "hash")
+	var cfg remoteConfig
+	source := envstruct.Redis(envstruct.RedisConfig{Addr: server.Addr(), Prefix: "my-service:"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432}); cfg != want {
+		t.Errorf("got %+v, want only the string keys with the prefix %+v", cfg, want)
+	}
+}
+
+func TestRedisInvalidation(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.HSet("my-service", "log_level", "info")
+	source := envstruct.Redis(envstruct.RedisConfig{
+		Addr:                server.Addr(),
+		Hash:                "my-service",
+		InvalidationChannel: "my-service:invalidate",
+	})
+	store, err := envstruct.NewStore(remoteConfig{}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	// values are cached until a message is published
+	server.HSet("my-service", "log_level", "debug")
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Load().Level; got != "info" {
+		t.Fatalf("got level %q before invalidation, want the cached value", got)
+	}
+	server.Publish("my-service:invalidate", "1")
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Load().Level != "debug" {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want the new level after the invalidation message", store.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package envstruct
+
+import (
+	"context"
+	"errors"
+)
+
+// Registry returns a source that reads the values of a Windows registry key. On this platform the
+// source always returns an error.
+func Registry(path string) Source {
+	return &funcSource{
+		name: "registry:" + path,
+		lookup: func(context.Context, string) (string, bool, error) {
+			return "", false, errors.New("the windows registry is only available on windows")
+		},
+	}
+}
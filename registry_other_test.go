@@ -0,0 +1,17 @@
+//go:build !windows
+
+package envstruct_test
+
+import (
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestRegistryUnavailable(t *testing.T) {
+	var cfg remoteConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Registry(`HKLM\SOFTWARE\MyService`)))
+	if err == nil {
+		t.Fatal("expected an error outside of windows")
+	}
+}
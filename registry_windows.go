@@ -0,0 +1,103 @@
+//go:build windows
+
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+var registryRoots = map[string]registry.Key{
+	"HKLM":                registry.LOCAL_MACHINE,
+	"HKEY_LOCAL_MACHINE":  registry.LOCAL_MACHINE,
+	"HKCU":                registry.CURRENT_USER,
+	"HKEY_CURRENT_USER":   registry.CURRENT_USER,
+	"HKCR":                registry.CLASSES_ROOT,
+	"HKEY_CLASSES_ROOT":   registry.CLASSES_ROOT,
+	"HKU":                 registry.USERS,
+	"HKEY_USERS":          registry.USERS,
+	"HKCC":                registry.CURRENT_CONFIG,
+	"HKEY_CURRENT_CONFIG": registry.CURRENT_CONFIG,
+}
+
+// Registry returns a source that reads the values of a Windows registry key, such as
+// `HKLM\SOFTWARE\MyCompany\MyService`. Value names are flattened like config file keys and the
+// values of subkeys are included with the subkey name as a prefix, so the value `Host` under the
+// subkey `Database` is resolved as `DATABASE_HOST`.
+//
+// String values have environment variables expanded where the registry type asks for it, integer
+// values are formatted in base 10 and multi-string values are joined with commas. The key is read
+// the first time a key is looked up. On other platforms the source always returns an error.
+func Registry(path string) Source {
+	return newMapSource("registry:"+path, func(ctx context.Context) (map[string]string, error) {
+		rootName, subPath, _ := strings.Cut(path, `\`)
+		root, ok := registryRoots[strings.ToUpper(rootName)]
+		if !ok {
+			return nil, fmt.Errorf("unknown registry root key '%s'", rootName)
+		}
+		values := make(map[string]string)
+		if err := readRegistryKey(root, subPath, "", values); err != nil {
+			return nil, fmt.Errorf("error reading registry key '%s': %w", path, err)
+		}
+		return values, nil
+	})
+}
+
+func readRegistryKey(root registry.Key, path, prefix string, values map[string]string) error {
+	key, err := registry.OpenKey(root, path, registry.QUERY_VALUE|registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		value, err := readRegistryValue(key, name)
+		if err != nil {
+			return fmt.Errorf("error reading value '%s': %w", name, err)
+		}
+		values[joinFlatKey(prefix, name, defaultKeySeparator)] = value
+	}
+
+	subKeys, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return err
+	}
+	for _, subKey := range subKeys {
+		subPrefix := joinFlatKey(prefix, subKey, defaultKeySeparator)
+		if err := readRegistryKey(root, path+`\`+subKey, subPrefix, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRegistryValue(key registry.Key, name string) (string, error) {
+	_, valueType, err := key.GetValue(name, nil)
+	if err != nil {
+		return "", err
+	}
+	switch valueType {
+	case registry.SZ, registry.EXPAND_SZ:
+		value, _, err := key.GetStringValue(name)
+		if err != nil || valueType != registry.EXPAND_SZ {
+			return value, err
+		}
+		return registry.ExpandString(value)
+	case registry.DWORD, registry.QWORD:
+		value, _, err := key.GetIntegerValue(name)
+		return strconv.FormatUint(value, 10), err
+	case registry.MULTI_SZ:
+		value, _, err := key.GetStringsValue(name)
+		return strings.Join(value, ","), err
+	default:
+		return "", fmt.Errorf("unsupported registry value type %d", valueType)
+	}
+}
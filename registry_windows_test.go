@@ -0,0 +1,66 @@
+//go:build windows
+
+package envstruct_test
+
+import (
+	"testing"
+
+	"golang.org/x/sys/windows/registry"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestRegistry(t *testing.T) {
+	const path = `Software\envstruct-test`
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, path, registry.ALL_ACCESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	sub, _, err := registry.CreateKey(key, "Database", registry.ALL_ACCESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	t.Cleanup(func() {
+		_ = registry.DeleteKey(key, "Database")
+		_ = registry.DeleteKey(registry.CURRENT_USER, path)
+	})
+
+	t.Setenv("ENVSTRUCT_TEST_DIR", `C:\data`)
+	for _, err := range []error{
+		key.SetStringValue("LogLevel", "debug"),
+		key.SetExpandStringValue("DataDir", `%ENVSTRUCT_TEST_DIR%\app`),
+		key.SetStringsValue("Hosts", []string{"a", "b"}),
+		sub.SetStringValue("Host", "db.internal"),
+		sub.SetDWordValue("Port", 5432),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg struct {
+		Level   string `env:"LOGLEVEL"`
+		DataDir string `env:"DATADIR"`
+		Hosts   string `env:"HOSTS"`
+		Host    string `env:"DATABASE_HOST"`
+		Port    int    `env:"DATABASE_PORT"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Registry(`HKCU\`+path))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "debug" || cfg.DataDir != `C:\data\app` || cfg.Hosts != "a,b" ||
+		cfg.Host != "db.internal" || cfg.Port != 5432 {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestRegistryUnknownRoot(t *testing.T) {
+	var cfg struct {
+		Level string `env:"LOGLEVEL"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.Registry(`HKXX\Software`))); err == nil {
+		t.Fatal("expected an error")
+	}
+}
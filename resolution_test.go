@@ -0,0 +1,62 @@
+package envstruct_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestResultFields(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dotenv := writeFile(t, ".env", "LOG_LEVEL=debug\n")
+	static := sources.Static(map[string]string{
+		"APP_ENV":      "prod",
+		"DB_HOST":      "localhost",
+		"DB_HOST_PROD": "db.prod.internal",
+		"PROD_DB_PORT": "6543",
+	}).Named("static")
+	var cfg struct {
+		Host  string `env:"DB_HOST"`
+		Port  int    `env:"DB_PORT"`
+		Level string `env:"LOG_LEVEL"`
+		Name  string `env:"SERVICE_NAME" default:"orders"`
+		Unset string `env:"UNSET"`
+	}
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(static, envstruct.DotEnv(dotenv)), envstruct.WithProfiles("APP_ENV"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fields are listed in field order, with the profile specific key that was used, and fields
+	// that were not set are left out
+	want := []envstruct.Resolution{
+		{Field: "Host", Key: "DB_HOST_PROD", Source: "static"},
+		{Field: "Port", Key: "PROD_DB_PORT", Source: "static"},
+		{Field: "Level", Key: "LOG_LEVEL", Source: "dotenv:" + dotenv, Origin: dotenv},
+		{Field: "Name", Source: "default"},
+	}
+	if !reflect.DeepEqual(res.Fields, want) {
+		t.Errorf("got %+v, want %+v", res.Fields, want)
+	}
+	for _, r := range res.Fields {
+		if res.Sources[r.Field] != r.Source {
+			t.Errorf("got source %q for %s in Sources, want %q", res.Sources[r.Field], r.Field, r.Source)
+		}
+	}
+}
+
+func TestResolutionJSON(t *testing.T) {
+	data, err := json.Marshal([]envstruct.Resolution{
+		{Field: "Host", Key: "DB_HOST", Source: "dotenv:.env", Origin: ".env"},
+		{Field: "Name", Source: "default"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"field":"Host","key":"DB_HOST","source":"dotenv:.env","origin":".env"},{"field":"Name","source":"default"}]`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,202 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures how WithSourceRetry retries a failing source.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a call is made before its error is returned, including
+	// the first one. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the longest wait before the first retry. Defaults to 100 milliseconds.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries, which doubles after every retry. Defaults to 5
+	// seconds.
+	MaxBackoff time.Duration
+	// Retryable reports whether an error is transient and the call should be retried. Defaults to
+	// IsRetryable.
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// WithSourceRetry returns a source that retries lookups and prefetches of `source` that fail with
+// a transient error, such as a network error or throttling, using exponential backoff with full
+// jitter. Keys that do not exist are not errors and are never retried, and neither are errors the
+// policy does not consider retryable, such as denied permissions. Retries stop early if the
+// context is done.
+//
+// The returned source is recorded in a `Result` under the name of `source`:
+//
+//	vault := WithSourceRetry(Vault(VaultConfig{Path: "my-service/config"}), RetryPolicy{MaxAttempts: 5})
+func WithSourceRetry(source Source, policy RetryPolicy) Source {
+	return &retrySource{source: source, policy: policy.withDefaults()}
+}
+
+// retrySource is the Source returned by WithSourceRetry.
+type retrySource struct {
+	source Source
+	policy RetryPolicy
+}
+
+func (s *retrySource) Lookup(ctx context.Context, key string) (value string, ok bool, err error) {
+	err = s.policy.retry(ctx, func() error {
+		value, ok, err = s.source.Lookup(ctx, key)
+		return err
+	})
+	return value, ok, err
+}
+
+// Prefetch prefetches the wrapped source if it implements Prefetcher.
+func (s *retrySource) Prefetch(ctx context.Context) error {
+	prefetcher, ok := s.source.(Prefetcher)
+	if !ok {
+		return nil
+	}
+	return s.policy.retry(ctx, func() error {
+		return prefetcher.Prefetch(ctx)
+	})
+}
+
+// PrefetchKeys prefetches the keys from the wrapped source if it implements KeyPrefetcher.
+func (s *retrySource) PrefetchKeys(ctx context.Context, keys []string) error {
+	prefetcher, ok := s.source.(KeyPrefetcher)
+	if !ok {
+		return nil
+	}
+	return s.policy.retry(ctx, func() error {
+		return prefetcher.PrefetchKeys(ctx, keys)
+	})
+}
+
+// Changed forwards the changes reported by the wrapped source. If it does not implement Notifier
+// the returned channel never receives.
+func (s *retrySource) Changed() <-chan struct{} {
+	if notifier, ok := s.source.(Notifier); ok {
+		return notifier.Changed()
+	}
+	return nil
+}
+
+func (s *retrySource) String() string {
+	return SourceName(s.source)
+}
+
+func (s *retrySource) limitedToSecrets() bool {
+	src, ok := s.source.(interface{ limitedToSecrets() bool })
+	return ok && src.limitedToSecrets()
+}
+
+func (s *retrySource) watchedFiles() []string {
+	src, ok := s.source.(interface{ watchedFiles() []string })
+	if !ok {
+		return nil
+	}
+	return src.watchedFiles()
+}
+
+func (s *retrySource) reload() {
+	if src, ok := s.source.(interface{ reload() }); ok {
+		src.reload()
+	}
+}
+
+func (s *retrySource) startWatching(ctx context.Context) {
+	if src, ok := s.source.(interface{ startWatching(ctx context.Context) }); ok {
+		src.startWatching(ctx)
+	}
+}
+
+// retry calls `fn` until it succeeds, fails with an error that is not retryable, the attempts are
+// used up or `ctx` is done.
+func (p RetryPolicy) retry(ctx context.Context, fn func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(rand.N(backoff + 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, p.MaxBackoff)
+	}
+}
+
+// throttlingCodes are the error codes AWS services use for throttled requests.
+var throttlingCodes = map[string]bool{
+	"Throttling":                    true,
+	"ThrottlingException":           true,
+	"ThrottledException":            true,
+	"TooManyRequestsException":      true,
+	"RequestLimitExceeded":          true,
+	"ProvisionedThroughputExceeded": true,
+}
+
+// IsRetryable reports whether `err` returned by a source is likely transient: timeouts, refused or
+// reset connections, throttling and unavailable servers, as reported by HTTP statuses 429 and 5xx,
+// gRPC status codes and AWS error codes. It is the default of RetryPolicy.Retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+	var retryable interface{ RetryableError() bool }
+	if errors.As(err, &retryable) {
+		return retryable.RetryableError()
+	}
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) && throttlingCodes[apiErr.ErrorCode()] {
+		return true
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Aborted:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+// unavailableError is a transient source error, which is retried.
+type unavailableError struct{}
+
+func (unavailableError) Error() string        { return "source unavailable" }
+func (unavailableError) RetryableError() bool { return true }
+
+// fastRetry retries without waiting long.
+var fastRetry = envstruct.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+}
+
+func TestSourceRetry(t *testing.T) {
+	static := sources.Static(map[string]string{"HOST": "db.internal"}).Named("static")
+	source := envstruct.WithSourceRetry(static, fastRetry)
+	if got := envstruct.SourceName(source); got != "static" {
+		t.Errorf("got name %q, want the name of the wrapped source", got)
+	}
+
+	static.Fail(unavailableError{})
+	if _, _, err := source.Lookup(context.Background(), "HOST"); !errors.Is(err, unavailableError{}) {
+		t.Fatalf("got error %v, want the error of the last attempt", err)
+	}
+	if got := static.Lookups("HOST"); got != 3 {
+		t.Errorf("got %d attempts, want 3", got)
+	}
+
+	// errors that are not transient are returned at once
+	static.Fail(errors.New("permission denied"))
+	if _, _, err := source.Lookup(context.Background(), "HOST"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := static.Lookups("HOST"); got != 4 {
+		t.Errorf("got %d attempts, want 1 more", got-3)
+	}
+
+	// missing keys are not errors
+	static.Fail(nil)
+	if _, ok, err := source.Lookup(context.Background(), "MISSING"); ok || err != nil {
+		t.Errorf("got %v, %v for a missing key", ok, err)
+	}
+	if got := static.Lookups("MISSING"); got != 1 {
+		t.Errorf("got %d lookups of a missing key, want 1", got)
+	}
+}
+
+func TestSourceRetryRecovers(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"db": {"host": "db.internal"}}`)
+	}))
+	defer server.Close()
+
+	var cfg struct {
+		Host string `env:"DB_HOST"`
+	}
+	source := envstruct.WithSourceRetry(envstruct.HTTP(envstruct.HTTPConfig{URL: server.URL}), fastRetry)
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || requests.Load() != 3 {
+		t.Errorf("got %q after %d requests, want the value after 2 failed requests", cfg.Host, requests.Load())
+	}
+}
+
+func TestSourceRetryContext(t *testing.T) {
+	static := sources.Static(nil)
+	static.Fail(unavailableError{})
+	source := envstruct.WithSourceRetry(static, envstruct.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, _, err := source.Lookup(ctx, "HOST"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := static.Lookups("HOST"); got != 1 {
+		t.Errorf("got %d attempts, want the retries to stop with the context", got)
+	}
+}
+
+func TestSourceRetryCustomRetryable(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	static := sources.Static(nil)
+	static.Fail(errFlaky)
+	policy := fastRetry
+	policy.MaxAttempts = 4
+	policy.Retryable = func(err error) bool { return errors.Is(err, errFlaky) }
+	source := envstruct.WithSourceRetry(static, policy)
+	source.Lookup(context.Background(), "HOST")
+	if got := static.Lookups("HOST"); got != 4 {
+		t.Errorf("got %d attempts, want 4", got)
+	}
+}
+
+// apiError is an error of an AWS service.
+type apiError string
+
+func (e apiError) Error() string     { return string(e) }
+func (e apiError) ErrorCode() string { return string(e) }
+
+// timeoutError is a network timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("permission denied"), false},
+		{context.Canceled, false},
+		{fmt.Errorf("lookup: %w", context.DeadlineExceeded), true},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{timeoutError{}, true},
+		{unavailableError{}, true},
+		{apiError("ThrottlingException"), true},
+		{apiError("AccessDeniedException"), false},
+		{status.Error(codes.Unavailable, "unavailable"), true},
+		{status.Error(codes.ResourceExhausted, "slow down"), true},
+		{status.Error(codes.NotFound, "not found"), false},
+		{status.Error(codes.PermissionDenied, "denied"), false},
+	}
+	for _, tt := range tests {
+		if got := envstruct.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	for code, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusInternalServerError: true,
+		http.StatusForbidden:           false,
+		http.StatusNotFound:            false,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}))
+		_, _, err := envstruct.HTTP(envstruct.HTTPConfig{URL: server.URL}).Lookup(context.Background(), "HOST")
+		server.Close()
+		if err == nil {
+			t.Fatalf("expected an error for status %d", code)
+		}
+		if got := envstruct.IsRetryable(err); got != want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a cron schedule that can be used as a field type, so scheduler configuration fails
+// when it is parsed rather than when a job first runs:
+//
+//	Cleanup Schedule `env:"CLEANUP_SCHEDULE"`
+//
+// Schedules are written as standard 5-field cron expressions such as `*/15 * * * *`, descriptors
+// such as `@daily`, or intervals such as `@every 1h30m`. Times are in the local time zone unless
+// the expression starts with `CRON_TZ=<zone>`. The zero Schedule never runs.
+type Schedule struct {
+	spec     string
+	schedule cron.Schedule
+}
+
+// ParseSchedule parses a cron schedule.
+func ParseSchedule(spec string) (Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{spec: spec, schedule: schedule}, nil
+}
+
+// Next returns the first time the schedule runs after `t`, or the zero time if it never does.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.schedule == nil {
+		return time.Time{}
+	}
+	return s.schedule.Next(t)
+}
+
+// String returns the expression the schedule was parsed from.
+func (s Schedule) String() string {
+	return s.spec
+}
+
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.spec), nil
+}
+
+func (s *Schedule) UnmarshalText(text []byte) error {
+	parsed, err := ParseSchedule(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// validateCron checks that a string is a cron schedule accepted by ParseSchedule.
+func validateCron(_ context.Context, value reflect.Value, _ string) error {
+	s, err := ruleString(value, "cron")
+	if err != nil {
+		return err
+	}
+	if _, err := ParseSchedule(s); err != nil {
+		return fmt.Errorf("must be a cron schedule: %w", err)
+	}
+	return nil
+}
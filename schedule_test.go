@@ -0,0 +1,69 @@
+package envstruct_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+func TestSchedule(t *testing.T) {
+	var cfg struct {
+		Cleanup envstruct.Schedule `env:"CLEANUP_SCHEDULE"`
+		Report  envstruct.Schedule `env:"REPORT_SCHEDULE" default:"CRON_TZ=UTC @daily"`
+		Sync    envstruct.Schedule `env:"SYNC_SCHEDULE"`
+		Never   envstruct.Schedule `env:"NEVER_SCHEDULE"`
+	}
+	source := sources.Static(map[string]string{"CLEANUP_SCHEDULE": "*/15 * * * *", "SYNC_SCHEDULE": "@every 1h30m"})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 1, 10, 7, 0, 0, time.UTC)
+	if got, want := cfg.Cleanup.Next(start.In(time.Local)), start.Add(8*time.Minute); !got.Equal(want) {
+		t.Errorf("got next cleanup %v, want %v", got, want)
+	}
+	if got, want := cfg.Report.Next(start), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got next report %v, want %v", got, want)
+	}
+	if got, want := cfg.Sync.Next(start), start.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("got next sync %v, want %v", got, want)
+	}
+	if got := cfg.Never.Next(start); !got.IsZero() {
+		t.Errorf("got next run %v for the zero schedule, want the zero time", got)
+	}
+	if cfg.Cleanup.String() != "*/15 * * * *" {
+		t.Errorf("got %q, want the expression", cfg.Cleanup.String())
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	var cfg struct {
+		Cleanup envstruct.Schedule `env:"CLEANUP_SCHEDULE"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(map[string]string{"CLEANUP_SCHEDULE": "* * *"})))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonParse {
+		t.Fatalf("got error %v, want a parse error", err)
+	}
+}
+
+func TestValidateCron(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"0 3 * * *", true},
+		{"@hourly", true},
+		{"CRON_TZ=Europe/Paris 0 9 * * 1-5", true},
+		{"0 3 * *", false},
+		{"61 * * * *", false},
+	}
+	for _, tt := range tests {
+		if err := validateValue[string](t, "cron", tt.value); (err == nil) != tt.valid {
+			t.Errorf("cron with %q: got error %v, want valid %v", tt.value, err, tt.valid)
+		}
+	}
+}
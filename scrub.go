@@ -0,0 +1,48 @@
+package envstruct
+
+import (
+	"os"
+	"reflect"
+)
+
+// WithScrubSecrets makes Parse unset the variables of fields tagged with the `secret` option, and
+// of Secret fields, after a successful parse, so child processes started afterwards do not inherit
+// them and later calls to `os.Getenv` do not return them. Values bound to fields are copied first
+// and are unaffected.
+//
+// On unix systems the bytes of the values are also zeroed before they are unset, which zeroes
+// strings obtained from `os.Getenv` for the same variables elsewhere in the program too. This is
+// a best effort to keep the values from lingering in memory: it does not reach copies made
+// elsewhere, and it does not change what the operating system reports about the process, such as
+// `/proc/<pid>/environ` on Linux, which other processes may also have read before the parse.
+//
+// Scrubbing is not safe while other goroutines read the environment, so it is best used once at
+// startup.
+func WithScrubSecrets() Option {
+	return func(o *options) {
+		o.scrubSecrets = true
+	}
+}
+
+// scrubEnv unsets the variables of the secret fields of `val`, and all of their profile
+// specific variants.
+func (o options) scrubEnv(val reflect.Value) error {
+	var err error
+	walkFields(val, "", func(f boundField) {
+		if err != nil || !f.tag.has("secret") {
+			return
+		}
+		var buf [3]string
+		for _, key := range o.appendCandidateKeys(buf[:0], f.tag.key) {
+			value, ok := os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+			overwriteEnvValue(value)
+			if err = os.Unsetenv(key); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
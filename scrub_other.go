@@ -0,0 +1,6 @@
+//go:build !unix
+
+package envstruct
+
+// overwriteEnvValue does nothing on systems where `os.LookupEnv` returns a copy of the value.
+func overwriteEnvValue(string) {}
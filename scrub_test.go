@@ -0,0 +1,74 @@
+package envstruct_test
+
+import (
+	"os"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+type scrubConfig struct {
+	Host     string                   `env:"DB_HOST"`
+	Password string                   `env:"DB_PASSWORD,secret"`
+	Token    envstruct.Secret[string] `env:"API_TOKEN"`
+	Port     int                      `env:"DB_PORT"`
+}
+
+// stillSet returns the keys of `keys` that are still set in the environment.
+func stillSet(keys ...string) []string {
+	var set []string
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); ok {
+			set = append(set, key)
+		}
+	}
+	return set
+}
+
+func TestScrubSecrets(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("API_TOKEN", "token")
+	var cfg scrubConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithScrubSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.Token.Value() != "token" {
+		t.Errorf("got %q and %q, want the values to be bound before scrubbing", cfg.Password, cfg.Token.Value())
+	}
+	if set := stillSet("DB_PASSWORD", "API_TOKEN"); len(set) > 0 {
+		t.Errorf("got %q still set, want the secrets to be scrubbed", set)
+	}
+	if os.Getenv("DB_HOST") != "db.internal" {
+		t.Error("want variables that are not secret to be kept")
+	}
+}
+
+func TestScrubSecretsProfiles(t *testing.T) {
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_PASSWORD_PROD", "hunter3")
+	t.Setenv("PROD_DB_PASSWORD", "hunter4")
+	var cfg scrubConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithScrubSecrets(), envstruct.WithProfiles("APP_ENV")); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter3" {
+		t.Errorf("got password %q, want the suffixed key", cfg.Password)
+	}
+	if set := stillSet("DB_PASSWORD", "DB_PASSWORD_PROD", "PROD_DB_PASSWORD"); len(set) > 0 {
+		t.Errorf("got %q still set, want every variant of the secret to be scrubbed", set)
+	}
+}
+
+func TestScrubSecretsFailedParse(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("DB_PORT", "abc")
+	var cfg scrubConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithScrubSecrets()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if os.Getenv("DB_PASSWORD") != "hunter2" {
+		t.Error("want the environment to be kept when the parse fails")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package envstruct
+
+import "unsafe"
+
+// overwriteEnvValue zeroes the memory of `value`, a string returned by `os.LookupEnv`. On unix
+// systems it shares memory with the copy of the environment kept by the syscall package, so the
+// value is zeroed there too.
+func overwriteEnvValue(value string) {
+	if value == "" {
+		return
+	}
+	clear(unsafe.Slice(unsafe.StringData(value), len(value)))
+}
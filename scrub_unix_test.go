@@ -0,0 +1,27 @@
+//go:build unix
+
+package envstruct_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestScrubSecretsOverwritesMemory(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	// strings from the environment share memory with the copy kept by the runtime
+	leaked := os.Getenv("DB_PASSWORD")
+	var cfg scrubConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithScrubSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	if leaked != strings.Repeat("\x00", len("hunter2")) {
+		t.Errorf("got %q, want the memory of the value to be zeroed", leaked)
+	}
+	if cfg.Password != "hunter2" {
+		t.Errorf("got password %q, want the bound copy to be kept", cfg.Password)
+	}
+}
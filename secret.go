@@ -0,0 +1,87 @@
+package envstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// Secret holds a value that must not be printed or logged, such as a password or API key. It is
+// bound like a field of type `T`, and is always treated as if it were tagged with the `secret`
+// option:
+//
+//	DBPassword Secret[string] `env:"DB_PASSWORD"`
+//
+// Formatting it with any verb of the fmt package, including `%+v` dumps of the enclosing struct,
+// marshaling it to JSON or text and logging it with slog all produce `[REDACTED]`. Use Value to
+// get the actual value.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding `value`.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the secret value.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format implements `fmt.Formatter` so every verb, not only `%s` and `%v`, is redacted.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// UnmarshalText sets the secret value the same way a field of type `T` is set.
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	if b, ok := any(&s.value).(*[]byte); ok {
+		// copy directly so no string copy of the secret is left behind
+		*b = append([]byte(nil), text...)
+		return nil
+	}
+	ok, err := setField(reflect.ValueOf(&s.value).Elem(), "", string(text), false)
+	if err != nil {
+		// the underlying error, such as a `strconv.NumError`, may quote the value
+		return fmt.Errorf("value is not a valid %s", reflect.TypeFor[T]())
+	}
+	if !ok {
+		return fmt.Errorf("type %s is not supported", reflect.TypeFor[T]())
+	}
+	return nil
+}
+
+// secretValue returns the value of the secret without its type, and marks Secret types for
+// isSecretType.
+func (s Secret[T]) secretValue() any {
+	return s.value
+}
+
+var secretMarkerType = reflect.TypeFor[interface{ secretValue() any }]()
+
+// isSecretType reports whether `t` is a Secret type.
+func isSecretType(t reflect.Type) bool {
+	return t.Implements(secretMarkerType)
+}
@@ -0,0 +1,88 @@
+package envstruct_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type secretFields struct {
+	User     string                   `env:"DB_USER"`
+	Password envstruct.Secret[string] `env:"DB_PASSWORD"`
+	PIN      envstruct.Secret[int]    `env:"PIN"`
+	Key      envstruct.Secret[[]byte] `env:"SIGNING_KEY"`
+}
+
+func TestSecret(t *testing.T) {
+	source := sources.Static(map[string]string{
+		"DB_USER":     "app",
+		"DB_PASSWORD": "hunter2",
+		"PIN":         "1234",
+		"SIGNING_KEY": "key-bytes",
+	})
+	var cfg secretFields
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password.Value() != "hunter2" || cfg.PIN.Value() != 1234 || string(cfg.Key.Value()) != "key-bytes" {
+		t.Fatalf("got %q, %d and %q, want the parsed values", cfg.Password.Value(), cfg.PIN.Value(), cfg.Key.Value())
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d", "%x", "%10.3v"} {
+		if got := fmt.Sprintf(format, cfg); strings.Contains(got, "hunter2") || strings.Contains(got, "1234") {
+			t.Errorf("%s printed a secret: %s", format, got)
+		}
+	}
+	if got := fmt.Sprint(cfg.Password); got != "[REDACTED]" {
+		t.Errorf("got %q, want [REDACTED]", got)
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"User":"app","Password":"[REDACTED]","PIN":"[REDACTED]","Key":"[REDACTED]"}`; string(data) != want {
+		t.Errorf("got JSON %s, want %s", data, want)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("loaded", "password", cfg.Password, "config", cfg)
+	if strings.Contains(buf.String(), "hunter2") || !strings.Contains(buf.String(), "password=[REDACTED]") {
+		t.Errorf("slog printed %q", buf.String())
+	}
+}
+
+func TestSecretInvalidValue(t *testing.T) {
+	source := sources.Static(map[string]string{"PIN": "12a4"})
+	var cfg secretFields
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "12a4") {
+		t.Errorf("got error %q, which contains the secret", err)
+	}
+	if !strings.Contains(err.Error(), "not a valid int") {
+		t.Errorf("got error %q, want the expected type", err)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	s := envstruct.NewSecret("hunter2")
+	if s.Value() != "hunter2" {
+		t.Errorf("got %q", s.Value())
+	}
+	text, err := s.MarshalText()
+	if err != nil || string(text) != "[REDACTED]" {
+		t.Errorf("got text %q, %v", text, err)
+	}
+	if got := fmt.Sprintf("%#v", s); got != "[REDACTED]" {
+		t.Errorf("got %q", got)
+	}
+}
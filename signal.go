@@ -0,0 +1,41 @@
+package envstruct
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReloadOnSignal reloads the config whenever the process receives one of `signals`, or SIGHUP if
+// none are given, until `ctx` is done, following the convention of Unix daemons:
+//
+//	go store.ReloadOnSignal(ctx)
+//	...
+//	kill -HUP <pid>
+//
+// Reloads use the options given to NewStore, and sources that keep what they load, such as DotEnv
+// and File, are read again. Failed reloads leave the current config in use and are handled as
+// described by Watch. The signals are only handled by the process while ReloadOnSignal runs.
+func (s *Store[T]) ReloadOnSignal(ctx context.Context, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+
+	opts := append(s.opts[:len(s.opts):len(s.opts)], WithContext(ctx))
+	o := newOptions(opts)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-received:
+			o.reloadSources()
+			_ = o.retryReload(ctx, func() error {
+				return s.reload(opts)
+			})
+		}
+	}
+}
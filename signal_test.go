@@ -0,0 +1,56 @@
+//go:build unix
+
+package envstruct_test
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	// SIGHUP terminates the process unless it is handled, and ReloadOnSignal may not handle it yet
+	// when the first one is sent
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGHUP)
+	defer signal.Stop(ignored)
+
+	file := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(file, []byte("LOG_LEVEL=info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := envstruct.NewStore(watchConfig{}, envstruct.WithSources(envstruct.DotEnv(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.ReloadOnSignal(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := os.WriteFile(file, []byte("LOG_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if store.Load().Level != "info" {
+		t.Fatal("the file was reloaded before a signal was sent")
+	}
+	waitFor(t, "the reload on SIGHUP", func() bool {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return store.Load().Level == "debug"
+	})
+}
@@ -0,0 +1,127 @@
+package envstruct
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultSignatureKey is the key the signature of the signed fields is read from by default.
+const DefaultSignatureKey = "CONFIG_SIGNATURE"
+
+// SignatureConfig configures how WithSignature verifies the fields tagged with the `signed` option.
+// One of HMACKey or PublicKey must be set.
+type SignatureConfig struct {
+	// Key is the key the base64 encoded signature is resolved from. Defaults to
+	// DefaultSignatureKey.
+	Key string
+	// HMACKey is the secret of HMAC-SHA256 signatures.
+	HMACKey []byte
+	// PublicKey is the key of Ed25519 signatures.
+	PublicKey ed25519.PublicKey
+}
+
+// WithSignature makes Parse verify a signature over the values of the fields tagged with the
+// `signed` option, e.g. `env:"DB_HOST,signed"`, before any field is set, so values tampered with
+// in the orchestrator config are rejected. The signature is resolved from the source chain like
+// any other key and is the base64 encoding of the HMAC-SHA256 or Ed25519 signature of
+// SignatureMessage over the values of the signed keys as they are resolved, before decryption.
+// The signed fields are then set from the values that were verified, without looking them up
+// again.
+//
+// Parse fails if the signature is missing or does not match, or if no field is tagged with the
+// `signed` option.
+func WithSignature(cfg SignatureConfig) Option {
+	return func(o *options) {
+		o.signature = &cfg
+	}
+}
+
+// SignatureMessage returns the message that is signed for the signed keys and their values: a
+// `KEY="value"` line for every key in sorted order, with the value quoted like a Go string literal
+// so values with newlines can not be confused with other lines. Keys without a value are included
+// with a blank value, so removing a signed variable also invalidates the signature.
+func SignatureMessage(values map[string]string) []byte {
+	var sb strings.Builder
+	for _, key := range sortedKeys(values) {
+		sb.WriteString(key + "=" + strconv.Quote(values[key]) + "\n")
+	}
+	return []byte(sb.String())
+}
+
+// verifySignature checks the signature of the fields of `val` tagged with the `signed` option, and
+// returns the values it checked by field path so they can be bound without resolving them again.
+func (o options) verifySignature(val reflect.Value) (map[string]resolved, error) {
+	cfg := o.signature
+	if len(cfg.HMACKey) == 0 && len(cfg.PublicKey) == 0 {
+		return nil, errors.New("a signature key is required to verify signed fields")
+	}
+	if len(cfg.PublicKey) > 0 && len(cfg.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("an Ed25519 public key must be %d bytes, not %d", ed25519.PublicKeySize, len(cfg.PublicKey))
+	}
+	key := cfg.Key
+	if key == "" {
+		key = DefaultSignatureKey
+	}
+
+	signed := make(map[string]resolved)
+	values := make(map[string]string)
+	var err error
+	walkFields(val, "", func(f boundField) {
+		if err != nil || !f.tag.has("signed") {
+			return
+		}
+		var res resolved
+		res, err = o.resolve(f)
+		signed[f.path] = res
+		values[f.tag.key] = res.value
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.New("no fields are tagged with the signed option")
+	}
+
+	encoded, err := o.resolveKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("signature '%s' is missing", key)
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signature '%s': %w", key, err)
+	}
+
+	message := SignatureMessage(values)
+	if len(cfg.HMACKey) > 0 {
+		mac := hmac.New(sha256.New, cfg.HMACKey)
+		mac.Write(message)
+		if hmac.Equal(mac.Sum(nil), signature) {
+			return signed, nil
+		}
+	}
+	if len(cfg.PublicKey) > 0 && ed25519.Verify(cfg.PublicKey, message, signature) {
+		return signed, nil
+	}
+	return nil, fmt.Errorf("signature '%s' does not match the values of %s", key, strings.Join(sortedKeys(values), ", "))
+}
+
+// resolveKey returns the first non-blank value of `key` in the source chain.
+func (o options) resolveKey(key string) (string, error) {
+	for _, source := range o.sourceChain() {
+		value, _, err := o.lookup(source, SourceName(source), key)
+		if err != nil || value != "" {
+			return value, err
+		}
+	}
+	return "", nil
+}
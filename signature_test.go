@@ -0,0 +1,165 @@
+package envstruct_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type signedConfig struct {
+	Host string `env:"DB_HOST,signed"`
+	Port int    `env:"DB_PORT,signed"`
+	Name string `env:"APP_NAME"`
+}
+
+func hmacSignature(key []byte, values map[string]string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(envstruct.SignatureMessage(values))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestWithSignatureHMAC(t *testing.T) {
+	key := []byte("signing key")
+	signed := map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"}
+
+	tests := map[string]struct {
+		values  map[string]string
+		wantErr string
+	}{
+		"valid": {
+			values: map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432", "APP_NAME": "orders",
+				"CONFIG_SIGNATURE": hmacSignature(key, signed)},
+		},
+		"tampered": {
+			values: map[string]string{"DB_HOST": "evil.internal", "DB_PORT": "5432",
+				"CONFIG_SIGNATURE": hmacSignature(key, signed)},
+			wantErr: "does not match",
+		},
+		"removed": {
+			values:  map[string]string{"DB_HOST": "db.internal", "CONFIG_SIGNATURE": hmacSignature(key, signed)},
+			wantErr: "does not match",
+		},
+		"missing signature": {
+			values:  map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"},
+			wantErr: "is missing",
+		},
+		"invalid signature": {
+			values:  map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432", "CONFIG_SIGNATURE": "%%%"},
+			wantErr: "error decoding signature",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg signedConfig
+			_, err := envstruct.Parse(&cfg,
+				envstruct.WithSources(sources.Static(tt.values)),
+				envstruct.WithSignature(envstruct.SignatureConfig{HMACKey: key}),
+			)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg.Host != "db.internal" || cfg.Port != 5432 {
+					t.Errorf("got %+v", cfg)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+			if cfg != (signedConfig{}) {
+				t.Errorf("fields were set before the signature was verified: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestWithSignatureEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"}
+	signature := ed25519.Sign(private, envstruct.SignatureMessage(signed))
+	values := map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432",
+		"CONFIG_SIGNATURE": base64.StdEncoding.EncodeToString(signature)}
+
+	var cfg signedConfig
+	_, err = envstruct.Parse(&cfg,
+		envstruct.WithSources(sources.Static(values)),
+		envstruct.WithSignature(envstruct.SignatureConfig{PublicKey: public}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host != "db.internal" || cfg.Port != 5432 {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestWithSignatureInvalidPublicKey(t *testing.T) {
+	values := map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432", "CONFIG_SIGNATURE": "c2ln"}
+	var cfg signedConfig
+	_, err := envstruct.Parse(&cfg,
+		envstruct.WithSources(sources.Static(values)),
+		envstruct.WithSignature(envstruct.SignatureConfig{PublicKey: ed25519.PublicKey("short")}),
+	)
+	if err == nil || !strings.Contains(err.Error(), "public key must be") {
+		t.Fatalf("got error %v, want an invalid key error", err)
+	}
+}
+
+// flippingSource returns `first` for the first lookup of a key and `later` afterwards.
+type flippingSource struct {
+	mu    sync.Mutex
+	seen  map[string]bool
+	first map[string]string
+	later map[string]string
+}
+
+func (s *flippingSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := s.first
+	if s.seen[key] {
+		values = s.later
+	}
+	s.seen[key] = true
+	value, ok := values[key]
+	return value, ok, nil
+}
+
+func TestWithSignatureBindsVerifiedValues(t *testing.T) {
+	key := []byte("signing key")
+	signed := map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"}
+	source := &flippingSource{
+		seen: make(map[string]bool),
+		first: map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432",
+			"CONFIG_SIGNATURE": hmacSignature(key, signed)},
+		later: map[string]string{"DB_HOST": "evil.internal", "DB_PORT": "6543"},
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		clear(source.seen)
+		var cfg signedConfig
+		_, err := envstruct.Parse(&cfg,
+			envstruct.WithSources(source),
+			envstruct.WithSignature(envstruct.SignatureConfig{HMACKey: key}),
+			envstruct.WithConcurrency(concurrency),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Host != "db.internal" || cfg.Port != 5432 {
+			t.Errorf("concurrency %d: got %+v, want the verified values", concurrency, cfg)
+		}
+	}
+}
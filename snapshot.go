@@ -0,0 +1,22 @@
+package envstruct
+
+import (
+	"slices"
+	"strings"
+)
+
+// Snapshot returns the effective configuration of `cfg` as `KEY=value` lines sorted by key, with
+// values redacted and quoted like Dump shows them. The output only depends on the values of the
+// fields, so it is stable across runs and can be checked in, as `envtest.Golden` does.
+func Snapshot(cfg any, opts ...Option) []byte {
+	o := newOptions(opts)
+	var lines []string
+	walkFields(structValue(cfg), "", func(f boundField) {
+		if f.tag.has("secret") && o.omitSecrets {
+			return
+		}
+		lines = append(lines, f.tag.key+"="+dumpValue(displayValue(f))+"\n")
+	})
+	slices.Sort(lines)
+	return []byte(strings.Join(lines, ""))
+}
@@ -0,0 +1,55 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SOPSFile returns a source that reads a config file encrypted with SOPS, so encrypted config can
+// be committed to a repository and decrypted transparently at parse time. Decryption is delegated
+// to the `sops` binary, which must be on the PATH and finds its keys the usual way, such as through
+// `SOPS_AGE_KEY_FILE` for age or the cloud credentials for KMS.
+//
+// Files with a `.json`, `.yaml` or `.yml` extension are decrypted as that format and flattened
+// like JSONFile. TOML files are not supported by SOPS. Any other file is decrypted as a dotenv
+// file. WithKeySeparator can be used to change how nested keys are joined.
+//
+// The file is decrypted the first time a key is looked up.
+func SOPSFile(name string, opts ...Option) Source {
+	o := newOptions(opts)
+	return newMapSource("sops:"+name, func(ctx context.Context) (map[string]string, error) {
+		// decryption may call a remote KMS
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		format := configFormat(name)
+		if format == "toml" {
+			return nil, fmt.Errorf("error decrypting '%s': sops does not support TOML files", name)
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sops",
+			"--decrypt",
+			"--input-type", format,
+			"--output-type", format,
+			name,
+		)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("error decrypting '%s': %w: %s", name, err, msg)
+			}
+			return nil, fmt.Errorf("error decrypting '%s': %w", name, err)
+		}
+
+		values, err := decodeConfig(format, stdout.Bytes(), o.keySeparator())
+		if err != nil {
+			return nil, fmt.Errorf("error reading decrypted '%s': %w", name, err)
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,89 @@
+//go:build unix
+
+package envstruct_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// fakeSOPS puts a `sops` script on the PATH that records its arguments and prints the file it is
+// given, or fails with `stderr` when it is not empty.
+func fakeSOPS(t *testing.T, stderr string) (args func() string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > '" + argsFile + "'\n"
+	if stderr != "" {
+		script += "echo '" + stderr + "' >&2\nexit 1\n"
+	} else {
+		script += "for last; do :; done\ncat \"$last\"\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sops"), []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return func() string {
+		data, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+}
+
+func TestSOPSFile(t *testing.T) {
+	args := fakeSOPS(t, "")
+	name := writeFile(t, "secrets.yaml", "database:\n  host: db.internal\n  password: hunter2\n")
+	var cfg struct {
+		Host     string `env:"DATABASE_HOST"`
+		Password string `env:"DATABASE_PASSWORD"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.SOPSFile(name))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.internal" || cfg.Password != "hunter2" {
+		t.Errorf("got %+v, want the flattened decrypted values", cfg)
+	}
+	if want := "--decrypt --input-type yaml --output-type yaml " + name; args() != want {
+		t.Errorf("got sops arguments %q, want %q", args(), want)
+	}
+}
+
+func TestSOPSFileDotEnv(t *testing.T) {
+	args := fakeSOPS(t, "")
+	name := writeFile(t, "secrets.env", "DB_PASSWORD=hunter2\n")
+	var cfg struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.SOPSFile(name))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" {
+		t.Errorf("got %+v", cfg)
+	}
+	if want := "--decrypt --input-type dotenv --output-type dotenv " + name; args() != want {
+		t.Errorf("got sops arguments %q, want %q", args(), want)
+	}
+}
+
+func TestSOPSFileFails(t *testing.T) {
+	fakeSOPS(t, "no key could decrypt the data")
+	name := writeFile(t, "secrets.env", "DB_PASSWORD=ENC[...]\n")
+	var cfg struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.SOPSFile(name)))
+	if err == nil || !strings.Contains(err.Error(), "no key could decrypt the data") {
+		t.Fatalf("got error %v, want the error output of sops", err)
+	}
+
+	toml := writeFile(t, "secrets.toml", "")
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.SOPSFile(toml))); err == nil {
+		t.Fatal("expected an error for a TOML file")
+	}
+}
@@ -0,0 +1,478 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source is a provider of configuration values for Parse. Implement it to plug any config system
+// into the source chain.
+//
+// Lookup returns the value of `key` and whether the source has it. An error should only be
+// returned when the source could not be read; a key that does not exist is reported with false.
+//
+// A source that implements `fmt.Stringer` is recorded in a `Result` under the returned name,
+// otherwise its type name is used.
+type Source interface {
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+// Prefetcher can optionally be implemented by a Source that loads its values in bulk. Parse calls
+// Prefetch once for every source in the chain before any key is looked up, so slow sources can be
+// loaded up front and loading errors are reported before binding starts.
+type Prefetcher interface {
+	Prefetch(ctx context.Context) error
+}
+
+// KeyPrefetcher can optionally be implemented by a Source that can fetch many keys in one round
+// trip, such as a batch API of a remote secret store. Parse calls PrefetchKeys once for every
+// source with the keys of all fields it may be asked for, after Prefetch and before any key is
+// looked up, so binding only reads values that are already loaded. Lookup must still work for
+// keys that were not prefetched.
+type KeyPrefetcher interface {
+	PrefetchKeys(ctx context.Context, keys []string) error
+}
+
+// Notifier can optionally be implemented by a Source that watches for changes, such as a source
+// backed by a remote config system. Changed returns a channel that receives a value whenever the
+// values of the source may have changed. Changes are coalesced until the value is received.
+type Notifier interface {
+	Changed() <-chan struct{}
+}
+
+// SourceName returns the name `source` is recorded under in a `Result`.
+func SourceName(source Source) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
+
+// funcSource is the Source implementation used by the sources in this package.
+type funcSource struct {
+	name     string
+	lookup   func(ctx context.Context, key string) (string, bool, error)
+	prefetch func(ctx context.Context) error
+	// secretsOnly limits the source to fields tagged with the `secret` option.
+	secretsOnly bool
+	// files are the files the source reads, which Watch watches for changes.
+	files []string
+	// reset makes the source load its values again on the next lookup.
+	reset func()
+}
+
+func (s *funcSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return s.lookup(ctx, key)
+}
+
+func (s *funcSource) Prefetch(ctx context.Context) error {
+	if s.prefetch == nil {
+		return nil
+	}
+	return s.prefetch(ctx)
+}
+
+func (s *funcSource) String() string {
+	return s.name
+}
+
+func (s *funcSource) limitedToSecrets() bool {
+	return s.secretsOnly
+}
+
+func (s *funcSource) watchedFiles() []string {
+	return s.files
+}
+
+func (s *funcSource) reload() {
+	if s.reset != nil {
+		s.reset()
+	}
+}
+
+// keyPrefetchSource is a funcSource that implements KeyPrefetcher.
+type keyPrefetchSource struct {
+	*funcSource
+	prefetchKeys func(ctx context.Context, keys []string) error
+}
+
+func (s *keyPrefetchSource) PrefetchKeys(ctx context.Context, keys []string) error {
+	return s.prefetchKeys(ctx, keys)
+}
+
+// originSource is a funcSource that reports where within the source a key was found, which is
+// recorded in `Resolution.Origin`.
+type originSource struct {
+	*funcSource
+	originOf func(key string) string
+}
+
+func (s *originSource) origin(key string) string {
+	return s.originOf(key)
+}
+
+// notifySource is a funcSource that implements Notifier.
+type notifySource struct {
+	*funcSource
+	changed chan struct{}
+}
+
+func newNotifySource(source *funcSource) *notifySource {
+	return &notifySource{funcSource: source, changed: make(chan struct{}, 1)}
+}
+
+func (s *notifySource) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// notify signals a change without blocking.
+func (s *notifySource) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Env returns a source that reads from the process environment.
+func Env() Source {
+	return &funcSource{
+		name: "env",
+		lookup: func(_ context.Context, key string) (string, bool, error) {
+			value, ok := os.LookupEnv(key)
+			return value, ok, nil
+		},
+	}
+}
+
+// DotEnv returns a source that reads the given dotenv files. Files are read in order, with values
+// from later files overriding values from earlier ones, and files that do not exist are skipped,
+// matching LoadDotEnv. Unlike LoadDotEnv the process environment is never modified.
+//
+// Files are read the first time a key is looked up. The file each value was read from is recorded
+// in `Resolution.Origin`.
+func DotEnv(files ...string) Source {
+	var (
+		mu      sync.Mutex
+		origins map[string]string
+	)
+	source := newMapSource("dotenv:"+strings.Join(files, ","), func(context.Context) (map[string]string, error) {
+		values := make(map[string]string)
+		fileOrigins := make(map[string]string)
+		for _, file := range files {
+			fileValues, err := readDotEnvFile(file)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range fileValues {
+				values[key] = value
+				fileOrigins[key] = file
+			}
+		}
+		mu.Lock()
+		origins = fileOrigins
+		mu.Unlock()
+		return values, nil
+	})
+	source.files = files
+	return &originSource{
+		funcSource: source,
+		originOf: func(key string) string {
+			mu.Lock()
+			defer mu.Unlock()
+			return origins[key]
+		},
+	}
+}
+
+// File returns a source that reads the config file `name`. The format is chosen by the file
+// extension: `.json`, `.yaml`, `.yml` and `.toml` files are flattened into keys the same way as
+// JSONFile, and any other file is read as a dotenv file. WithKeySeparator can be used to change
+// how nested keys are joined.
+//
+// The file is read the first time a key is looked up. Unlike DotEnv, a missing file is an error.
+func File(name string, opts ...Option) Source {
+	return configFileSource(name, configFormat(name), newOptions(opts))
+}
+
+// configFileSource returns a source that reads the config file `name` in `format`, one of the
+// formats returned by configFormat.
+func configFileSource(name, format string, o options) Source {
+	source := newMapSource("file:"+name, func(context.Context) (map[string]string, error) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		values, err := decodeConfig(format, data, o.keySeparator())
+		if err != nil {
+			return nil, fmt.Errorf("error reading '%s': %w", name, err)
+		}
+		return values, nil
+	})
+	source.files = []string{name}
+	return source
+}
+
+// newMapSource returns a source backed by the map returned from `load`. `load` is called when the
+// source is prefetched or the first time a key is looked up, and again after a failure until it
+// succeeds, so transient errors of remote backends can be retried.
+func newMapSource(name string, load func(ctx context.Context) (map[string]string, error)) *funcSource {
+	var (
+		mu     sync.Mutex
+		loaded bool
+		values map[string]string
+	)
+	prefetch := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if loaded {
+			return nil
+		}
+		loadedValues, err := load(ctx)
+		if err != nil {
+			return err
+		}
+		values, loaded = loadedValues, true
+		return nil
+	}
+	return &funcSource{
+		name:     name,
+		prefetch: prefetch,
+		lookup: func(ctx context.Context, key string) (string, bool, error) {
+			if err := prefetch(ctx); err != nil {
+				return "", false, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := values[key]
+			return value, ok, nil
+		},
+		reset: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			loaded = false
+		},
+	}
+}
+
+// sourceChain returns the configured source chain, or the environment if none is configured.
+func (o options) sourceChain() []Source {
+	if len(o.sources) == 0 {
+		return defaultChain[:1:1]
+	}
+	return o.sources
+}
+
+// allSources returns the source chain followed by the named sources in name order.
+func (o options) allSources() []Source {
+	// clip so appending never writes to the backing array of the configured chain
+	sources := slices.Clip(o.sourceChain())
+	for _, name := range sortedKeys(o.namedSources) {
+		sources = append(sources, o.namedSources[name])
+	}
+	return sources
+}
+
+// defaultChain is the source chain used when no sources are given. It is shared since the
+// environment source has no state.
+var defaultChain = []Source{Env()}
+
+// prefetch calls Prefetch on every source in the chain and every named source that implements
+// Prefetcher.
+func (o options) prefetch() error {
+	sources := o.allSources()
+	for _, source := range sources {
+		prefetcher, ok := source.(Prefetcher)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		err := prefetcher.Prefetch(o.context())
+		if o.metrics != nil {
+			o.metrics.observeLookup(SourceName(source), start)
+		}
+		if err != nil {
+			return fmt.Errorf("error prefetching source '%s': %w", SourceName(source), err)
+		}
+	}
+	return nil
+}
+
+// degradedSources returns the names of the sources in the chain and the named sources that report
+// being degraded, such as sources returned by WithSourceBreaker while their breaker is open.
+func (o options) degradedSources() []string {
+	var names []string
+	sources := o.allSources()
+	for _, source := range sources {
+		if src, ok := source.(interface{ Degraded() bool }); ok && src.Degraded() {
+			names = append(names, SourceName(source))
+		}
+	}
+	return names
+}
+
+// prefetchKeys calls PrefetchKeys on every source that implements KeyPrefetcher with the keys it
+// may be asked for while binding `val`, following the same rules as resolve.
+func (o options) prefetchKeys(val reflect.Value) error {
+	// most parses use no such source, so skip walking the fields
+	found := false
+	for _, source := range o.sourceChain() {
+		_, ok := source.(KeyPrefetcher)
+		found = found || ok
+	}
+	for _, source := range o.namedSources {
+		_, ok := source.(KeyPrefetcher)
+		found = found || ok
+	}
+	if !found {
+		return nil
+	}
+
+	var sources []Source
+	keys := make(map[Source][]string)
+	add := func(source Source, f boundField) {
+		if _, ok := source.(KeyPrefetcher); !ok {
+			return
+		}
+		if _, seen := keys[source]; !seen {
+			sources = append(sources, source)
+		}
+		keys[source] = o.appendCandidateKeys(keys[source], f.tag.key)
+	}
+	walkFields(val, "", func(f boundField) {
+		if !f.value.CanSet() || f.tag.get("exec") != "" || f.tag.get("op") != "" {
+			return
+		}
+		if selected := f.sourceName(); selected != "" {
+			if source, _, ok := o.namedSource(selected); ok {
+				add(source, f)
+			}
+			return
+		}
+		for _, source := range o.sourceChain() {
+			if src, ok := source.(interface{ limitedToSecrets() bool }); ok && src.limitedToSecrets() && !f.tag.has("secret") {
+				continue
+			}
+			add(source, f)
+		}
+	})
+
+	for _, source := range sources {
+		if err := source.(KeyPrefetcher).PrefetchKeys(o.context(), keys[source]); err != nil {
+			return fmt.Errorf("error prefetching source '%s': %w", SourceName(source), err)
+		}
+	}
+	return nil
+}
+
+// resolved is the value resolved for a field and where it came from.
+type resolved struct {
+	value string
+	// source is the name of the source the value came from, or of the source that failed.
+	source string
+	// key is the key the value was found under, which is a profile specific variant of the key
+	// of the field when a profile is selected.
+	key string
+	// origin is where within the source the key was found, if the source reports it, such as the
+	// dotenv file.
+	origin string
+}
+
+// resolve looks the key of `f` up in each configured source in turn and returns the first
+// non-blank value along with where it came from. If a source fails, its name is returned with the
+// error.
+//
+// Fields that select a source with the `source` tag or tag option are only looked up in that
+// source, even if it is limited to secrets. Fields with the `exec` tag option are set from the
+// output of their command, and fields with the `op` tag option from 1Password.
+func (o options) resolve(f boundField) (resolved, error) {
+	if command := f.tag.get("exec"); command != "" {
+		value, err := o.execValue(command)
+		return resolved{value: value, source: "exec", key: f.tag.key}, err
+	}
+	if ref := f.tag.get("op"); ref != "" {
+		value, err := o.onePasswordValue(ref)
+		return resolved{value: value, source: "1password", key: f.tag.key}, err
+	}
+	if selected := f.sourceName(); selected != "" {
+		source, name, ok := o.namedSource(selected)
+		if !ok {
+			return resolved{source: selected}, fmt.Errorf("no source named '%s' is configured", selected)
+		}
+		var buf [3]string
+		for _, key := range o.appendCandidateKeys(buf[:0], f.tag.key) {
+			value, name, err := o.lookup(source, name, key)
+			if err != nil || value != "" {
+				return newResolved(source, name, key, value), err
+			}
+		}
+		return resolved{}, nil
+	}
+
+	var buf [3]string
+	for _, key := range o.appendCandidateKeys(buf[:0], f.tag.key) {
+		for _, source := range o.sourceChain() {
+			if src, ok := source.(interface{ limitedToSecrets() bool }); ok && src.limitedToSecrets() && !f.tag.has("secret") {
+				continue
+			}
+			value, name, err := o.lookup(source, SourceName(source), key)
+			if err != nil || value != "" {
+				return newResolved(source, name, key, value), err
+			}
+		}
+	}
+	return resolved{}, nil
+}
+
+// newResolved returns `value` found under `key` in `source`, recorded as `name`.
+func newResolved(source Source, name, key, value string) resolved {
+	res := resolved{value: value, source: name, key: key}
+	if value == "" {
+		return res
+	}
+	if src, ok := source.(interface{ origin(key string) string }); ok {
+		res.origin = src.origin(key)
+	}
+	return res
+}
+
+// lookup looks `key` up in `source`, treating blank values as missing.
+func (o options) lookup(source Source, name, key string) (string, string, error) {
+	if o.metrics != nil {
+		defer o.metrics.observeLookup(name, time.Now())
+	}
+	value, ok, err := source.Lookup(o.context(), key)
+	if err != nil {
+		return "", name, fmt.Errorf("error reading from source '%s': %w", name, err)
+	}
+	if !ok || value == "" {
+		return "", "", nil
+	}
+	return value, name, nil
+}
+
+// namedSource returns the source registered under `name` with WithNamedSource, or else the first
+// source in the chain whose name is `name` or starts with `name:`, so `vault` selects a source
+// named `vault:secret/my-service`. The name the source should be recorded under is also returned.
+func (o options) namedSource(name string) (Source, string, bool) {
+	if source, ok := o.namedSources[name]; ok {
+		return source, name, true
+	}
+	for _, source := range o.sourceChain() {
+		sourceName := SourceName(source)
+		if sourceName == name || strings.HasPrefix(sourceName, name+":") {
+			return source, sourceName, true
+		}
+	}
+	return nil, "", false
+}
@@ -0,0 +1,151 @@
+// Package sources provides a source for tests of code that builds its own source chain, so
+// missing keys, failing sources and slow sources can be simulated without touching the real
+// environment:
+//
+//	source := sources.Static(map[string]string{"PORT": "8080"})
+//	source.FailKey("DB_PASSWORD", errors.New("permission denied"))
+//	source.SetLatency(50 * time.Millisecond)
+//	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+//
+// StaticSource implements the `envstruct.Source` and `envstruct.Notifier` interfaces.
+package sources
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// StaticSource is an in-memory source whose values and failures can be changed while it is in
+// use. It is safe for concurrent use.
+type StaticSource struct {
+	mu      sync.Mutex
+	name    string
+	values  map[string]string
+	errs    map[string]error
+	err     error
+	latency time.Duration
+	lookups map[string]int
+	changed chan struct{}
+}
+
+// Static returns a source serving a copy of `values`. Keys that are not in `values` are reported
+// as missing.
+func Static(values map[string]string) *StaticSource {
+	cloned := make(map[string]string, len(values))
+	maps.Copy(cloned, values)
+	return &StaticSource{
+		name:    "static",
+		values:  cloned,
+		errs:    make(map[string]error),
+		lookups: make(map[string]int),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Named sets the name the source is recorded under in an `envstruct.Result`, which defaults to
+// `static`, and returns the source.
+func (s *StaticSource) Named(name string) *StaticSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+	return s
+}
+
+// Set sets the value of `key` and signals a change.
+func (s *StaticSource) Set(key, value string) {
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Delete removes `key`, so it is reported as missing, and signals a change.
+func (s *StaticSource) Delete(key string) {
+	s.mu.Lock()
+	delete(s.values, key)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// FailKey makes lookups of `key` return `err`. A nil error makes them succeed again.
+func (s *StaticSource) FailKey(key string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.errs, key)
+		return
+	}
+	s.errs[key] = err
+}
+
+// Fail makes every lookup return `err`, as if the source could not be reached. A nil error makes
+// lookups succeed again.
+func (s *StaticSource) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// SetLatency delays every lookup by `latency`, or until the context of the lookup is done.
+func (s *StaticSource) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// Lookups returns the number of times `key` was looked up, including failed lookups.
+func (s *StaticSource) Lookups(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookups[key]
+}
+
+// Lookup implements `envstruct.Source`.
+func (s *StaticSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	s.lookups[key]++
+	latency := s.latency
+	s.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", false, s.err
+	}
+	if err := s.errs[key]; err != nil {
+		return "", false, err
+	}
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+// Changed implements `envstruct.Notifier`. It receives a value after Set or Delete.
+func (s *StaticSource) Changed() <-chan struct{} {
+	return s.changed
+}
+
+func (s *StaticSource) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.name
+}
+
+// notify signals a change without blocking.
+func (s *StaticSource) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,123 @@
+package sources_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+var (
+	_ envstruct.Source   = (*sources.StaticSource)(nil)
+	_ envstruct.Notifier = (*sources.StaticSource)(nil)
+)
+
+type config struct {
+	Port     int    `env:"PORT"`
+	Password string `env:"DB_PASSWORD,required"`
+}
+
+func TestStatic(t *testing.T) {
+	values := map[string]string{"PORT": "8080", "DB_PASSWORD": "hunter2"}
+	source := sources.Static(values).Named("test")
+	values["PORT"] = "9090"
+
+	var cfg config
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("got port %d, want the value given to Static", cfg.Port)
+	}
+	if res.Sources["Port"] != "test" {
+		t.Errorf("got source %q, want %q", res.Sources["Port"], "test")
+	}
+	if got := source.Lookups("PORT"); got != 1 {
+		t.Errorf("got %d lookups of PORT, want 1", got)
+	}
+}
+
+func TestStaticMissingKey(t *testing.T) {
+	source := sources.Static(map[string]string{"PORT": "8080", "DB_PASSWORD": "hunter2"})
+	source.Delete("DB_PASSWORD")
+	if _, ok, err := source.Lookup(context.Background(), "DB_PASSWORD"); ok || err != nil {
+		t.Fatalf("got ok %v, error %v for a deleted key", ok, err)
+	}
+
+	var cfg config
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonMissing || fieldErr.Key != "DB_PASSWORD" {
+		t.Fatalf("got error %v, want DB_PASSWORD to be missing", err)
+	}
+}
+
+func TestStaticFailures(t *testing.T) {
+	denied := errors.New("permission denied")
+	unreachable := errors.New("connection refused")
+	source := sources.Static(map[string]string{"PORT": "8080", "DB_PASSWORD": "hunter2"})
+
+	source.FailKey("DB_PASSWORD", denied)
+	var cfg config
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonSource || !errors.Is(err, denied) {
+		t.Fatalf("got error %v, want a source error for DB_PASSWORD", err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("got port %d, want other keys to still resolve", cfg.Port)
+	}
+
+	source.FailKey("DB_PASSWORD", nil)
+	source.Fail(unreachable)
+	if _, _, err := source.Lookup(context.Background(), "PORT"); !errors.Is(err, unreachable) {
+		t.Errorf("got error %v, want every lookup to fail", err)
+	}
+	source.Fail(nil)
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Errorf("unexpected error after the failures were cleared: %v", err)
+	}
+}
+
+func TestStaticLatency(t *testing.T) {
+	source := sources.Static(map[string]string{"PORT": "8080"})
+	source.SetLatency(20 * time.Millisecond)
+
+	start := time.Now()
+	if _, _, err := source.Lookup(context.Background(), "PORT"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("lookup took %v, want at least the latency", elapsed)
+	}
+
+	source.SetLatency(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := source.Lookup(ctx, "PORT"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the lookup to end with its context", err)
+	}
+}
+
+func TestStaticChanged(t *testing.T) {
+	source := sources.Static(nil)
+	source.Set("PORT", "8080")
+	source.Set("PORT", "9090")
+	select {
+	case <-source.Changed():
+	default:
+		t.Fatal("no change was signaled")
+	}
+	select {
+	case <-source.Changed():
+		t.Fatal("changes were not coalesced")
+	default:
+	}
+	if value, ok, _ := source.Lookup(context.Background(), "PORT"); !ok || value != "9090" {
+		t.Errorf("got %q, want the last value set", value)
+	}
+}
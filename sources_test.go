@@ -0,0 +1,250 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type layeredConfig struct {
+	Host  string `env:"HOST"`
+	Port  int    `env:"PORT" default:"8080"`
+	Level string `env:"LOG_LEVEL"`
+	DB    struct {
+		Name string `env:"DB_NAME"`
+	}
+}
+
+func TestParseSourceChain(t *testing.T) {
+	override := sources.Static(map[string]string{"HOST": "override.internal", "LOG_LEVEL": ""}).Named("override")
+	base := sources.Static(map[string]string{"HOST": "base.internal", "LOG_LEVEL": "info", "DB_NAME": "orders"}).Named("base")
+
+	var cfg layeredConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(override, base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := layeredConfig{Host: "override.internal", Port: 8080, Level: "info"}
+	want.DB.Name = "orders"
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	// blank values fall through to later sources
+	wantSources := map[string]string{"Host": "override", "Port": "default", "Level": "base", "DB.Name": "base"}
+	if len(res.Sources) != len(wantSources) {
+		t.Errorf("got sources %v, want %v", res.Sources, wantSources)
+	}
+	for field, source := range wantSources {
+		if res.Sources[field] != source {
+			t.Errorf("got source %q for %s, want %q", res.Sources[field], field, source)
+		}
+	}
+	if got := base.Lookups("HOST"); got != 0 {
+		t.Errorf("got %d lookups of HOST in the base source, want 0 once an earlier source has it", got)
+	}
+}
+
+func TestParseDefaultsToEnv(t *testing.T) {
+	t.Setenv("HOST", "env.internal")
+	var cfg layeredConfig
+	res, err := envstruct.Parse(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "env.internal" || res.Sources["Host"] != "env" {
+		t.Errorf("got host %q from %q, want it from the environment", cfg.Host, res.Sources["Host"])
+	}
+}
+
+func TestParseErrOnMissingValue(t *testing.T) {
+	var cfg layeredConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(map[string]string{"HOST": "db"})),
+		envstruct.WithErrOnMissingValue())
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want ParseErrors", err)
+	}
+	var missing []string
+	for _, fieldErr := range errs {
+		if fieldErr.Reason == envstruct.ReasonMissing {
+			missing = append(missing, fieldErr.Field)
+		}
+	}
+	// Port has a default
+	if len(missing) != 2 || missing[0] != "Level" || missing[1] != "DB.Name" {
+		t.Errorf("got missing fields %q, want Level and DB.Name", missing)
+	}
+}
+
+func TestParseSourceError(t *testing.T) {
+	failing := sources.Static(nil).Named("remote")
+	failing.Fail(errors.New("connection refused"))
+	var cfg layeredConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(failing))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("got error %v, want a FieldError", err)
+	}
+	if fieldErr.Reason != envstruct.ReasonSource || fieldErr.Hint != "check that source 'remote' is available" {
+		t.Errorf("got %s error with hint %q", fieldErr.Reason, fieldErr.Hint)
+	}
+}
+
+func TestDotEnvSource(t *testing.T) {
+	dir := t.TempDir()
+	env := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(env, []byte("HOST=localhost\nLOG_LEVEL=info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("HOST=dev.internal\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	unsetenv(t, "HOST")
+
+	var cfg layeredConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(
+		envstruct.DotEnv(env, local, filepath.Join(dir, ".env.missing"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "dev.internal" || cfg.Level != "info" {
+		t.Errorf("got %+v, want later files to override earlier ones", cfg)
+	}
+	if _, ok := os.LookupEnv("HOST"); ok {
+		t.Error("the environment was modified")
+	}
+	for _, r := range res.Fields {
+		if r.Field == "Host" && r.Origin != local {
+			t.Errorf("got origin %q for Host, want %q", r.Origin, local)
+		}
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.json": `{"host": "db.internal", "db": {"name": "orders"}}`,
+		"config.yaml": "host: db.internal\ndb:\n  name: orders\n",
+		"config.toml": "host = \"db.internal\"\n[db]\nname = \"orders\"\n",
+		"config.env":  "HOST=db.internal\nDB_NAME=orders\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var cfg layeredConfig
+			if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.File(writeFile(t, name, content)))); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Host != "db.internal" || cfg.DB.Name != "orders" {
+				t.Errorf("got %+v", cfg)
+			}
+		})
+	}
+
+	var cfg layeredConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(envstruct.File(filepath.Join(t.TempDir(), "missing.json")))); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// prefetchingSource is a custom source that loads its values in bulk.
+type prefetchingSource struct {
+	values     map[string]string
+	err        error
+	prefetched int
+	lookups    int
+}
+
+func (s *prefetchingSource) Prefetch(context.Context) error {
+	s.prefetched++
+	return s.err
+}
+
+func (s *prefetchingSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.lookups++
+	if s.prefetched == 0 {
+		return "", false, errors.New("looked up before prefetching")
+	}
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func TestCustomSource(t *testing.T) {
+	source := &prefetchingSource{values: map[string]string{"HOST": "custom.internal", "LOG_LEVEL": "info"}}
+	var cfg layeredConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "custom.internal" || cfg.Level != "info" {
+		t.Errorf("got %+v", cfg)
+	}
+	if source.prefetched != 1 {
+		t.Errorf("got %d prefetches, want 1", source.prefetched)
+	}
+	// sources without a String method are named by their type
+	if want := "*envstruct_test.prefetchingSource"; res.Sources["Host"] != want || envstruct.SourceName(source) != want {
+		t.Errorf("got source %q, want %q", res.Sources["Host"], want)
+	}
+	if got := envstruct.SourceName(sources.Static(nil).Named("named")); got != "named" {
+		t.Errorf("got name %q, want the name returned by String", got)
+	}
+}
+
+func TestCustomSourcePrefetchError(t *testing.T) {
+	source := &prefetchingSource{err: errors.New("unreachable")}
+	var cfg layeredConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if source.lookups != 0 {
+		t.Errorf("got %d lookups, want none after the prefetch failed", source.lookups)
+	}
+}
+
+func TestNamedSource(t *testing.T) {
+	env := sources.Static(map[string]string{"DB_HOST": "env.internal", "DB_PASSWORD": "from-env", "API_KEY": "from-env"})
+	vault := sources.Static(map[string]string{"DB_PASSWORD": "hunter2"}).Named("vault")
+	ssm := sources.Static(map[string]string{"API_KEY": "abc123"}).Named("ssm:/my-service")
+
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Password string `env:"DB_PASSWORD" source:"vault"`
+		APIKey   string `env:"API_KEY,source=ssm"`
+	}
+	res, err := envstruct.Parse(&cfg,
+		envstruct.WithSources(env, ssm),
+		envstruct.WithNamedSource("vault", vault),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "env.internal" || cfg.Password != "hunter2" || cfg.APIKey != "abc123" {
+		t.Errorf("got %+v, want fields with a source to only use that source", cfg)
+	}
+	if res.Sources["Password"] != "vault" || res.Sources["APIKey"] != "ssm:/my-service" {
+		t.Errorf("got sources %v", res.Sources)
+	}
+	// named sources are not part of the chain
+	if got := vault.Lookups("DB_HOST"); got != 0 {
+		t.Errorf("got %d lookups of DB_HOST in the named source, want 0", got)
+	}
+}
+
+func TestNamedSourceMissing(t *testing.T) {
+	var cfg struct {
+		Password string `env:"DB_PASSWORD" source:"vault"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(map[string]string{"DB_PASSWORD": "from-env"})))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonSource {
+		t.Fatalf("got error %v, want a source error for the unknown source", err)
+	}
+	if cfg.Password != "" {
+		t.Errorf("got password %q, want it not read from the chain", cfg.Password)
+	}
+}
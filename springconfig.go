@@ -0,0 +1,112 @@
+package envstruct
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SpringCloudConfigConfig configures the SpringCloudConfig source.
+type SpringCloudConfigConfig struct {
+	// URL is the address of the config server, e.g. `http://config-server:8888`.
+	URL string
+	// Application is the application name the server looks config up for.
+	Application string
+	// Profile is the active profile, or a comma separated list of profiles. Defaults to
+	// `default`.
+	Profile string
+	// Label selects a version of the config, such as a git branch or tag. If empty, the server's
+	// default label is used.
+	Label string
+	// Username and Password authenticate with HTTP basic auth, if set.
+	Username string
+	Password string
+	// Header is added to every request, e.g. `{"Authorization": {"Bearer ..."}}`.
+	Header http.Header
+	// TLSConfig is used for requests to the server if set.
+	TLSConfig *tls.Config
+}
+
+// SpringCloudConfig returns a source that reads config from a Spring Cloud Config server with its
+// `/{application}/{profile}/{label}` endpoint, so Go services can share a config server with JVM
+// services. Property names are flattened like config file keys, so `spring.datasource.url` is
+// resolved as `SPRING_DATASOURCE_URL` and `servers[0]` as `SERVERS_0`. When a property is defined
+// by more than one property source, the server's precedence applies.
+//
+// The config is read the first time a key is looked up.
+func SpringCloudConfig(cfg SpringCloudConfigConfig) Source {
+	profile := cfg.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	endpoint := strings.TrimSuffix(cfg.URL, "/") + "/" + url.PathEscape(cfg.Application) + "/" +
+		url.PathEscape(profile)
+	if cfg.Label != "" {
+		// labels may contain slashes, which the server expects as `(_)`
+		endpoint += "/" + strings.ReplaceAll(url.PathEscape(cfg.Label), "%2F", "(_)")
+	}
+
+	return newMapSource("spring-cloud-config:"+endpoint, func(ctx context.Context) (map[string]string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, headerValues := range cfg.Header {
+			req.Header[name] = headerValues
+		}
+		req.Header.Set("Accept", "application/json")
+		if cfg.Username != "" {
+			req.SetBasicAuth(cfg.Username, cfg.Password)
+		}
+
+		var resp struct {
+			PropertySources []struct {
+				Source map[string]json.RawMessage `json:"source"`
+			} `json:"propertySources"`
+		}
+		found, err := doJSONRequest(newHTTPClient(cfg.TLSConfig), req, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("no config found at %s", req.URL.Redacted())
+		}
+
+		values := make(map[string]string)
+		// property sources are ordered from highest to lowest precedence
+		for i := len(resp.PropertySources) - 1; i >= 0; i-- {
+			for property, raw := range resp.PropertySources[i].Source {
+				value, err := springPropertyValue(raw)
+				if err != nil {
+					return nil, fmt.Errorf("error decoding property '%s': %w", property, err)
+				}
+				values[springPropertyKey(property)] = value
+			}
+		}
+		return values, nil
+	})
+}
+
+// springPropertyKey flattens a Spring property name, including list indexes, into a key.
+func springPropertyKey(property string) string {
+	property = strings.NewReplacer("[", ".", "]", "").Replace(property)
+	return flattenPath(property, ".", defaultKeySeparator)
+}
+
+// springPropertyValue returns the text of a property value. Numbers and booleans are kept as
+// written so large numbers do not lose precision.
+func springPropertyValue(raw json.RawMessage) (string, error) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var value string
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	}
+	if string(raw) == "null" {
+		return "", nil
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,71 @@
+package envstruct_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+func TestSpringCloudConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if r.URL.Path != "/orders/prod,eu/feature(_)tls" || user != "config" || password != "secret" ||
+			r.Header.Get("X-Tenant") != "shop" {
+			t.Errorf("unexpected request %s by %q", r.URL, user)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"name": "orders",
+			"propertySources": [
+				{"name": "orders-prod.yml", "source": {"db.host": "db.prod.internal", "servers[0]": "a", "servers[1]": "b"}},
+				{"name": "application.yml", "source": {"db.host": "localhost", "db.port": 5432, "log-level": "info", "debug": null}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	source := envstruct.SpringCloudConfig(envstruct.SpringCloudConfigConfig{
+		URL:         server.URL + "/",
+		Application: "orders",
+		Profile:     "prod,eu",
+		Label:       "feature/tls",
+		Username:    "config",
+		Password:    "secret",
+		Header:      http.Header{"X-Tenant": {"shop"}},
+	})
+	var cfg struct {
+		remoteConfig
+		Server string `env:"SERVERS_1"`
+		Debug  string `env:"DEBUG" default:"off"`
+	}
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	// the first property source takes precedence
+	if want := (remoteConfig{Host: "db.prod.internal", Port: 5432, Level: "info"}); cfg.remoteConfig != want {
+		t.Errorf("got %+v, want %+v", cfg.remoteConfig, want)
+	}
+	// list indexes are flattened and null values leave the key unset
+	if cfg.Server != "b" || cfg.Debug != "off" {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestSpringCloudConfigNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders/default" {
+			t.Errorf("got path %s, want the default profile", r.URL.Path)
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	source := envstruct.SpringCloudConfig(envstruct.SpringCloudConfigConfig{URL: server.URL, Application: "orders"})
+	var cfg remoteConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err == nil {
+		t.Fatal("expected an error when the server has no config")
+	}
+}
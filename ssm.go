@@ -0,0 +1,63 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// SSMConfig configures the SSM source.
+type SSMConfig struct {
+	// Path is the parameter hierarchy to read, e.g. `/my-service/prod`.
+	Path string
+	// Recursive also reads parameters in nested levels of the hierarchy.
+	Recursive bool
+	// Client is used to call SSM. If nil, a client is created from the default AWS configuration.
+	Client ssm.GetParametersByPathAPIClient
+}
+
+// SSM returns a source that reads every parameter under a path from AWS Systems Manager
+// Parameter Store. SecureString parameters are decrypted. Parameter names are made relative to
+// the path and flattened like config files, so `/my-service/prod/db/host` under the path
+// `/my-service/prod` is resolved as `DB_HOST`.
+//
+// Parameters are fetched in batches with GetParametersByPath the first time a key is looked up.
+func SSM(cfg SSMConfig) Source {
+	return newMapSource("ssm:"+cfg.Path, func(ctx context.Context) (map[string]string, error) {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		client := cfg.Client
+		if client == nil {
+			awsCfg, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error loading AWS config: %w", err)
+			}
+			client = ssm.NewFromConfig(awsCfg)
+		}
+
+		values := make(map[string]string)
+		paginator := ssm.NewGetParametersByPathPaginator(client, &ssm.GetParametersByPathInput{
+			Path:           aws.String(cfg.Path),
+			Recursive:      aws.Bool(cfg.Recursive),
+			WithDecryption: aws.Bool(true),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, param := range page.Parameters {
+				name := strings.TrimPrefix(aws.ToString(param.Name), cfg.Path)
+				if key := flattenPath(name, "/", defaultKeySeparator); key != "" {
+					values[key] = aws.ToString(param.Value)
+				}
+			}
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,55 @@
+package envstruct_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// fakeSSMPath serves one parameter per page from a parameter hierarchy.
+type fakeSSMPath struct {
+	params []types.Parameter
+	inputs []*ssm.GetParametersByPathInput
+}
+
+func (c *fakeSSMPath) GetParametersByPath(_ context.Context, in *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	c.inputs = append(c.inputs, in)
+	i := 0
+	if in.NextToken != nil {
+		i, _ = strconv.Atoi(*in.NextToken)
+	}
+	out := &ssm.GetParametersByPathOutput{Parameters: c.params[i : i+1]}
+	if i+1 < len(c.params) {
+		out.NextToken = aws.String(strconv.Itoa(i + 1))
+	}
+	return out, nil
+}
+
+func TestSSM(t *testing.T) {
+	client := &fakeSSMPath{params: []types.Parameter{
+		{Name: aws.String("/my-service/prod/db/host"), Value: aws.String("db.internal")},
+		{Name: aws.String("/my-service/prod/db/port"), Value: aws.String("5432")},
+		{Name: aws.String("/my-service/prod/log-level"), Value: aws.String("debug")},
+	}}
+	source := envstruct.SSM(envstruct.SSMConfig{Path: "/my-service/prod", Recursive: true, Client: client})
+	var cfg remoteConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (remoteConfig{Host: "db.internal", Port: 5432, Level: "debug"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if len(client.inputs) != 3 {
+		t.Fatalf("got %d calls, want one per page", len(client.inputs))
+	}
+	in := client.inputs[0]
+	if aws.ToString(in.Path) != "/my-service/prod" || !aws.ToBool(in.Recursive) || !aws.ToBool(in.WithDecryption) {
+		t.Errorf("got input %+v, want a recursive, decrypted read of the path", in)
+	}
+}
@@ -0,0 +1,118 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ssmBatchSize is the most names GetParameters accepts in one call.
+const ssmBatchSize = 10
+
+// SSMParametersClient is the part of the SSM client used by the SSMParameters source.
+type SSMParametersClient interface {
+	GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+}
+
+// SSMParametersConfig configures the SSMParameters source.
+type SSMParametersConfig struct {
+	// Prefix is prepended to keys to form parameter names, e.g. `/my-service/prod/` to read
+	// `DB_HOST` from `/my-service/prod/DB_HOST`.
+	Prefix string
+	// Client is used to call SSM. If nil, a client is created from the default AWS configuration.
+	Client SSMParametersClient
+}
+
+// SSMParameters returns a source that reads individual parameters from AWS Systems Manager
+// Parameter Store by name. Unlike SSM it does not need a parameter hierarchy per service, and only
+// the parameters that are bound to fields are read. SecureString parameters are decrypted.
+//
+// The source implements KeyPrefetcher, so Parse fetches the parameters of all fields with
+// GetParameters in batches of ten before binding, rather than with a call per key. Fetched
+// parameters, including missing ones, are not fetched again until the source is reloaded: Watch
+// and Store.Watch with WithPollInterval, and WithSourcePolling, make it forget every parameter it
+// fetched, so the next parse reads them from SSM again.
+func SSMParameters(cfg SSMParametersConfig) Source {
+	var (
+		mu      sync.Mutex
+		client  = cfg.Client
+		values  = make(map[string]string)
+		fetched = make(map[string]bool)
+	)
+	// fetch reads the parameters of the keys that were not fetched yet; mu must be held
+	fetch := func(ctx context.Context, keys []string) error {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+
+		if client == nil {
+			awsCfg, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("error loading AWS config: %w", err)
+			}
+			client = ssm.NewFromConfig(awsCfg)
+		}
+
+		var pending []string
+		for _, key := range keys {
+			if !fetched[key] {
+				pending = append(pending, key)
+				fetched[key] = true
+			}
+		}
+		for start := 0; start < len(pending); start += ssmBatchSize {
+			batch := pending[start:min(start+ssmBatchSize, len(pending))]
+			names := make([]string, len(batch))
+			byName := make(map[string]string, len(batch))
+			for i, key := range batch {
+				names[i] = cfg.Prefix + key
+				byName[names[i]] = key
+			}
+			out, err := client.GetParameters(ctx, &ssm.GetParametersInput{
+				Names:          names,
+				WithDecryption: aws.Bool(true),
+			})
+			if err != nil {
+				for _, key := range pending[start:] {
+					delete(fetched, key)
+				}
+				return err
+			}
+			for _, param := range out.Parameters {
+				if key, ok := byName[aws.ToString(param.Name)]; ok {
+					values[key] = aws.ToString(param.Value)
+				}
+			}
+		}
+		return nil
+	}
+
+	return &keyPrefetchSource{
+		funcSource: &funcSource{
+			name: "ssm:" + cfg.Prefix,
+			lookup: func(ctx context.Context, key string) (string, bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if err := fetch(ctx, []string{key}); err != nil {
+					return "", false, err
+				}
+				value, ok := values[key]
+				return value, ok, nil
+			},
+			reset: func() {
+				mu.Lock()
+				defer mu.Unlock()
+				clear(values)
+				clear(fetched)
+			},
+		},
+		prefetchKeys: func(ctx context.Context, keys []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fetch(ctx, keys)
+		},
+	}
+}
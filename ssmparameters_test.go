@@ -0,0 +1,119 @@
+package envstruct_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+)
+
+// fakeSSM serves parameters from a map and records the names of every GetParameters call.
+type fakeSSM struct {
+	mu     sync.Mutex
+	params map[string]string
+	calls  [][]string
+}
+
+func (c *fakeSSM) GetParameters(_ context.Context, in *ssm.GetParametersInput, _ ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, in.Names)
+	out := &ssm.GetParametersOutput{}
+	for _, name := range in.Names {
+		if value, ok := c.params[name]; ok {
+			out.Parameters = append(out.Parameters, types.Parameter{Name: aws.String(name), Value: aws.String(value)})
+		} else {
+			out.InvalidParameters = append(out.InvalidParameters, name)
+		}
+	}
+	return out, nil
+}
+
+func (c *fakeSSM) set(name, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.params[name] = value
+}
+
+func (c *fakeSSM) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func TestSSMParametersBatches(t *testing.T) {
+	client := &fakeSSM{params: make(map[string]string)}
+	for c := 'A'; c <= 'L'; c++ {
+		key := "KEY_" + string(c)
+		client.params["/svc/"+key] = strings.ToLower(key)
+	}
+	var cfg struct {
+		A string `env:"KEY_A"`
+		B string `env:"KEY_B"`
+		C string `env:"KEY_C"`
+		D string `env:"KEY_D"`
+		E string `env:"KEY_E"`
+		F string `env:"KEY_F"`
+		G string `env:"KEY_G"`
+		H string `env:"KEY_H"`
+		I string `env:"KEY_I"`
+		J string `env:"KEY_J"`
+		K string `env:"KEY_K"`
+		L string `env:"KEY_L"`
+		M string `env:"KEY_MISSING"`
+	}
+	source := envstruct.SSMParameters(envstruct.SSMParametersConfig{Prefix: "/svc/", Client: client})
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.A != "key_a" || cfg.L != "key_l" || cfg.M != "" {
+		t.Errorf("got %+v", cfg)
+	}
+	if got := client.callCount(); got != 2 {
+		t.Errorf("got %d GetParameters calls, want 2 batches for 13 keys", got)
+	}
+
+	// fetched parameters, including missing ones, are not fetched again
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source)); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.callCount(); got != 2 {
+		t.Errorf("got %d GetParameters calls after parsing again, want 2", got)
+	}
+}
+
+func TestSSMParametersReload(t *testing.T) {
+	client := &fakeSSM{params: map[string]string{"/svc/LOG_LEVEL": "info"}}
+	source := envstruct.SSMParameters(envstruct.SSMParametersConfig{Prefix: "/svc/", Client: client})
+	type config struct {
+		LogLevel string `env:"LOG_LEVEL"`
+		Region   string `env:"REGION"`
+	}
+	store, err := envstruct.NewStore(config{},
+		envstruct.WithSources(source), envstruct.WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	client.set("/svc/LOG_LEVEL", "debug")
+	client.set("/svc/REGION", "eu-west-1")
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Load() != (config{LogLevel: "debug", Region: "eu-west-1"}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want the updated parameters after a reload", store.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,147 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the latest config of a service that reloads its config. Load can be called from any
+// goroutine, and reloads replace the config as a whole, so readers never see a config that is
+// partly old and partly new:
+//
+//	store, err := NewStore(Config{Port: 8080}, WithSources(DotEnv(".env"), Env()))
+//	...
+//	go store.Watch(ctx)
+//	...
+//	cfg := store.Load()
+//
+// Load returns a copy of the config, but fields such as slices and maps share their contents with
+// the stored config and must not be modified.
+type Store[T any] struct {
+	current atomic.Pointer[T]
+	// base is the value every parse starts from.
+	base T
+	opts []Option
+	// mu serializes reloads and guards subscribers.
+	mu          sync.Mutex
+	subscribers []func(old, new *T)
+}
+
+// NewStore parses a copy of `cfg` with `opts` and returns a Store holding the result. Every reload
+// starts again from `cfg`, so defaults set in code apply and removed variables are unset again.
+func NewStore[T any](cfg T, opts ...Option) (s *Store[T], err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in NewStore: %w", err)
+		}
+	}()
+	s = &Store[T]{base: cfg, opts: opts}
+	if _, err := Parse(&cfg, opts...); err != nil {
+		return nil, err
+	}
+	s.current.Store(&cfg)
+	return s, nil
+}
+
+// Load returns the current config.
+func (s *Store[T]) Load() T {
+	return *s.current.Load()
+}
+
+// Reload loads the values of the sources again, parses the config with the options given to
+// NewStore and replaces the current config if the parse succeeds. If it fails the current config
+// stays in use.
+func (s *Store[T]) Reload() (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Reload: %w", err)
+		}
+	}()
+	newOptions(s.opts).reloadSources()
+	return s.reload(s.opts)
+}
+
+// Watch reloads the config whenever its sources may have changed, as described by Watch, until
+// `ctx` is done. Failed reloads leave the current config in use and are handled as described by
+// Watch.
+func (s *Store[T]) Watch(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Watch: %w", err)
+		}
+	}()
+	opts := append(s.opts[:len(s.opts):len(s.opts)], WithContext(ctx))
+	o := newOptions(opts)
+	return o.watchSources(ctx, func() error {
+		_ = o.retryReload(ctx, func() error {
+			return s.reload(opts)
+		})
+		return nil
+	})
+}
+
+// reload parses the config with `opts` and replaces the current config if it changed.
+func (s *Store[T]) reload(opts []Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.base
+	if _, err := Parse(&next, opts...); err != nil {
+		return err
+	}
+	old := s.current.Load()
+	if reflect.DeepEqual(*old, next) {
+		return nil
+	}
+	s.current.Store(&next)
+	for _, subscriber := range s.subscribers {
+		subscriber(old, &next)
+	}
+	return nil
+}
+
+// OnChange subscribes `fn` to changes of the field at the dotted `path` in the configs of `store`,
+// e.g. `LogLevel` or `Database.Host`, so components only react to the fields they use:
+//
+//	err := OnChange(store, "LogLevel", func(old, new slog.Level) {
+//		levelVar.Set(new)
+//	})
+//
+// `fn` is called with the old and new value of the field after a reload replaced the config and
+// the field changed. Subscribers are called in the order they subscribed, from the goroutine
+// that reloaded, and must not reload the store themselves.
+//
+// An error is returned if `path` is not a field with an `env` tag, or the field is not of type
+// `V`.
+func OnChange[T, V any](store *Store[T], path string, fn func(old, new V)) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in OnChange: %w", err)
+		}
+	}()
+	fields := typeFields(reflect.TypeFor[T]())
+	i := slices.IndexFunc(fields, func(pf plannedField) bool {
+		return pf.path == path
+	})
+	if i < 0 {
+		return fmt.Errorf("%s has no field '%s' with an env tag", reflect.TypeFor[T](), path)
+	}
+	pf := fields[i]
+	if pf.field.Type != reflect.TypeFor[V]() {
+		return fmt.Errorf("field '%s' is of type %s, not %s", path, pf.field.Type, reflect.TypeFor[V]())
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.subscribers = append(store.subscribers, func(old, new *T) {
+		oldValue := reflect.ValueOf(old).Elem().FieldByIndex(pf.index).Interface().(V)
+		newValue := reflect.ValueOf(new).Elem().FieldByIndex(pf.index).Interface().(V)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			fn(oldValue, newValue)
+		}
+	})
+	return nil
+}
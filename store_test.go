@@ -0,0 +1,169 @@
+package envstruct_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type storeConfig struct {
+	Generation int    `env:"GENERATION"`
+	Name       string `env:"NAME"`
+}
+
+func TestStore(t *testing.T) {
+	source := sources.Static(map[string]string{"GENERATION": "1"})
+	store, err := envstruct.NewStore(storeConfig{Name: "orders"}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (storeConfig{Generation: 1, Name: "orders"}); store.Load() != want {
+		t.Fatalf("got %+v, want %+v", store.Load(), want)
+	}
+
+	source.Set("GENERATION", "2")
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if want := (storeConfig{Generation: 2, Name: "orders"}); store.Load() != want {
+		t.Errorf("got %+v after reloading, want %+v", store.Load(), want)
+	}
+
+	source.Set("GENERATION", "abc")
+	if err := store.Reload(); err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+	if want := (storeConfig{Generation: 2, Name: "orders"}); store.Load() != want {
+		t.Errorf("got %+v after a failed reload, want the previous config %+v", store.Load(), want)
+	}
+}
+
+func TestStoreReloadsFiles(t *testing.T) {
+	file := writeFile(t, ".env", "GENERATION=1\n")
+	store, err := envstruct.NewStore(storeConfig{}, envstruct.WithSources(envstruct.DotEnv(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("GENERATION=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Load().Generation; got != 2 {
+		t.Errorf("got generation %d after reloading, want the value written to the file", got)
+	}
+}
+
+func TestNewStoreFails(t *testing.T) {
+	source := sources.Static(map[string]string{"GENERATION": "abc"})
+	if _, err := envstruct.NewStore(storeConfig{}, envstruct.WithSources(source)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestStoreLoadDuringReload(t *testing.T) {
+	source := sources.Static(map[string]string{"GENERATION": "0"})
+	store, err := envstruct.NewStore(storeConfig{Name: "orders"}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+
+	const generations = 200
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := 0
+			for last < generations {
+				cfg := store.Load()
+				if cfg.Generation < last || cfg.Name != "orders" {
+					t.Errorf("got %+v after generation %d", cfg, last)
+					return
+				}
+				last = cfg.Generation
+			}
+		}()
+	}
+	for i := 1; i <= generations; i++ {
+		// Watch reloads on every change while this goroutine reloads as well
+		source.Set("GENERATION", strconv.Itoa(i))
+		if err := store.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
+
+type subscribedConfig struct {
+	LogLevel string `env:"LOG_LEVEL"`
+	Database struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT"`
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	source := sources.Static(map[string]string{"LOG_LEVEL": "info", "DB_HOST": "db-1", "DB_PORT": "5432"})
+	store, err := envstruct.NewStore(subscribedConfig{}, envstruct.WithSources(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	err = envstruct.OnChange(store, "LogLevel", func(old, new string) {
+		calls = append(calls, "LogLevel "+old+" -> "+new)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = envstruct.OnChange(store, "Database.Host", func(old, new string) {
+		calls = append(calls, "Database.Host "+old+" -> "+new)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reload := func() {
+		t.Helper()
+		if err := store.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source.Set("DB_PORT", "6543")
+	reload()
+	if len(calls) != 0 {
+		t.Fatalf("got calls %q after an unsubscribed field changed", calls)
+	}
+	source.Set("DB_HOST", "db-2")
+	source.Set("LOG_LEVEL", "debug")
+	reload()
+	want := []string{"LogLevel info -> debug", "Database.Host db-1 -> db-2"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+}
+
+func TestOnChangeInvalidField(t *testing.T) {
+	store, err := envstruct.NewStore(subscribedConfig{}, envstruct.WithSources(sources.Static(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := envstruct.OnChange(store, "Database.Name", func(old, new string) {}); err == nil {
+		t.Error("expected an error for a field that does not exist")
+	}
+	if err := envstruct.OnChange(store, "Database.Port", func(old, new string) {}); err == nil {
+		t.Error("expected an error for a field of another type")
+	}
+}
@@ -0,0 +1,202 @@
+package envstruct
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// envTag is a parsed `env` struct tag of the form `KEY[,option[=value]]...`, e.g.
+// `env:"DB_PASSWORD,secret"`.
+type envTag struct {
+	key     string
+	options map[string]string
+}
+
+func parseEnvTag(tag string) envTag {
+	key, rest, _ := strings.Cut(tag, ",")
+	parsed := envTag{key: strings.TrimSpace(key)}
+	if rest == "" {
+		return parsed
+	}
+	parsed.options = make(map[string]string)
+	for _, opt := range strings.Split(rest, ",") {
+		name, value, _ := strings.Cut(opt, "=")
+		parsed.options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return parsed
+}
+
+// has reports whether the option `name` is present on the tag.
+func (t envTag) has(name string) bool {
+	_, ok := t.options[name]
+	return ok
+}
+
+// withOption returns a copy of the tag with the option `name` added.
+func (t envTag) withOption(name string) envTag {
+	options := make(map[string]string, len(t.options)+1)
+	for key, value := range t.options {
+		options[key] = value
+	}
+	options[name] = ""
+	return envTag{key: t.key, options: options}
+}
+
+// get returns the value of the option `name`, or a blank string if it is not present.
+func (t envTag) get(name string) string {
+	return t.options[name]
+}
+
+// boundField is an exported struct field with an `env` tag.
+type boundField struct {
+	// path is the dotted path of the field from the top level struct.
+	path  string
+	tag   envTag
+	value reflect.Value
+	field reflect.StructField
+	// rules are the rules of the `validate` struct tag.
+	rules []string
+	// offset and fast are copied from the plannedField.
+	offset uintptr
+	fast   bool
+}
+
+// walkFields recursively calls `fn` for every exported field in `val` that has an `env` tag.
+func walkFields(val reflect.Value, path string, fn func(boundField)) {
+	for _, pf := range typeFields(val.Type()) {
+		fieldPath := pf.path
+		if path != "" {
+			fieldPath = path + "." + pf.path
+		}
+		fn(boundField{
+			path:   fieldPath,
+			tag:    pf.tag,
+			value:  val.FieldByIndex(pf.index),
+			field:  pf.field,
+			rules:  pf.rules,
+			offset: pf.offset,
+			fast:   pf.fast,
+		})
+	}
+}
+
+// plannedField is a field with an `env` tag found in a struct type, relative to that type.
+type plannedField struct {
+	// index is the index sequence of the field for `reflect.Value.FieldByIndex`.
+	index []int
+	path  string
+	tag   envTag
+	field reflect.StructField
+	rules []string
+	// offset is the offset of the field from the start of the struct type the plan is for.
+	offset uintptr
+	// fast reports whether the field can be set with setFieldFast.
+	fast bool
+}
+
+// fieldPlans caches the fields of struct types by `reflect.Type`, so tags are only walked and
+// parsed once per type rather than on every parse.
+var fieldPlans sync.Map
+
+// typeFields returns the fields with an `env` tag of the struct type `t`, including those of nested
+// structs, in the order walkFields visits them. The result is shared and must not be modified.
+func typeFields(t reflect.Type) []plannedField {
+	if cached, ok := fieldPlans.Load(t); ok {
+		return cached.([]plannedField)
+	}
+	var fields []plannedField
+	planFields(t, nil, 0, "", &fields)
+	cached, _ := fieldPlans.LoadOrStore(t, fields)
+	return cached.([]plannedField)
+}
+
+func planFields(t reflect.Type, index []int, offset uintptr, path string, fields *[]plannedField) {
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fieldPath := joinFieldPath(path, fieldType.Name)
+		fieldOffset := offset + fieldType.Offset
+
+		tag := parseEnvTag(fieldType.Tag.Get("env"))
+		// structs are bound as a whole when a single value is decomposed into their fields or
+		// they unmarshal themselves
+		if fieldType.Type.Kind() == reflect.Struct && !tag.has("parseurl") &&
+			!reflect.PointerTo(fieldType.Type).Implements(textUnmarshalerType) {
+			// fields of unexported structs can not be set or read, unless the struct is embedded
+			if fieldType.IsExported() || fieldType.Anonymous {
+				planFields(fieldType.Type, fieldIndex, fieldOffset, fieldPath, fields)
+			}
+			continue
+		}
+
+		if fieldType.IsExported() && tag.key != "" {
+			if isSecretType(fieldType.Type) && !tag.has("secret") {
+				tag = tag.withOption("secret")
+			}
+			var rules []string
+			if rule := fieldType.Tag.Get("validate"); rule != "" {
+				for _, rule := range strings.Split(rule, ",") {
+					rules = append(rules, strings.TrimSpace(rule))
+				}
+			}
+			*fields = append(*fields, plannedField{
+				index:  fieldIndex,
+				path:   fieldPath,
+				tag:    tag,
+				field:  fieldType,
+				rules:  rules,
+				offset: fieldOffset,
+				fast:   fastKind(fieldType.Type) && !tag.has("parseurl") && !tag.has("quantity"),
+			})
+		}
+	}
+}
+
+// fastKind reports whether fields of type `t` are set by setField from their kind alone, without
+// UnmarshalText.
+func fastKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+	}
+	return false
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// structValue returns the struct `obj` points to, or `obj` itself if it is not a pointer.
+func structValue(obj any) reflect.Value {
+	val := reflect.ValueOf(obj)
+
+	// if pointer, get value
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	return val
+}
+
+// sourceName returns the name of the source selected for the field with the `source` tag or tag
+// option, or a blank string if the field uses the whole source chain.
+func (f boundField) sourceName() string {
+	if name := f.tag.get("source"); name != "" {
+		return name
+	}
+	return f.field.Tag.Get("source")
+}
+
+// sortedKeys returns the keys of `m` in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package envstruct
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type plannedConfig struct {
+	Host   string         `env:"HOST"`
+	hidden string         `env:"HIDDEN"`
+	Token  Secret[string] `env:"TOKEN"`
+	Server struct {
+		Port     int     `env:"SERVER_PORT,required" validate:"min=1, max=65535"`
+		Endpoint url.URL `env:"SERVER_ENDPOINT,parseurl"`
+	}
+	internal struct {
+		Name string `env:"INTERNAL_NAME"`
+	}
+	Untagged string
+}
+
+func TestTypeFields(t *testing.T) {
+	typ := reflect.TypeFor[plannedConfig]()
+	fields := typeFields(typ)
+	var paths []string
+	for _, pf := range fields {
+		paths = append(paths, pf.path)
+	}
+	want := []string{"Host", "Token", "Server.Port", "Server.Endpoint"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("got fields %q, want %q", paths, want)
+	}
+
+	var cfg plannedConfig
+	val := reflect.ValueOf(&cfg).Elem()
+	for _, pf := range fields {
+		field := val.FieldByIndex(pf.index)
+		if got := field.Addr().Pointer() - val.Addr().Pointer(); got != pf.offset {
+			t.Errorf("got offset %d for %s, want %d", pf.offset, pf.path, got)
+		}
+	}
+	if !fields[1].tag.has("secret") {
+		t.Error("want the Secret field to be secret without the option")
+	}
+	if got := fields[2].rules; !reflect.DeepEqual(got, []string{"min=1", "max=65535"}) {
+		t.Errorf("got rules %q", got)
+	}
+	if !fields[2].fast || fields[3].fast {
+		t.Error("want only the int field to be set fast")
+	}
+
+	// the fields are planned once per type
+	if again := typeFields(typ); &again[0] != &fields[0] {
+		t.Error("want the cached fields for the same type")
+	}
+}
+
+func TestTypeFieldsConcurrently(t *testing.T) {
+	// a new type, so every goroutine races to plan it
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeFor[string](), Tag: `env:"A"`},
+		{Name: "B", Type: reflect.TypeFor[int](), Tag: `env:"B"`},
+	})
+	results := make([][]plannedField, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = typeFields(typ)
+		}()
+	}
+	wg.Wait()
+	for _, fields := range results {
+		if len(fields) != 2 || &fields[0] != &results[0][0] {
+			t.Fatal("want every goroutine to get the same cached fields")
+		}
+	}
+}
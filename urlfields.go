@@ -0,0 +1,93 @@
+package envstruct
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// setURLFields parses `value` as a URL and sets the fields of the struct `field` that are named
+// after its parts, for fields tagged with the `parseurl` option:
+//
+//	type Database struct {
+//		Scheme   string
+//		Host     string
+//		Port     int
+//		User     string
+//		Password string
+//		Path     string
+//		Query    url.Values
+//	}
+//
+//	DB Database `env:"DATABASE_URL,parseurl"`
+//
+// Host is the host name without the port, and Path has its leading slash removed, so for
+// `postgres://u:p@db:5432/app?sslmode=require` Path is the database name `app`. Port can be a
+// string or an int, and Query a string holding the raw query, `url.Values` or a
+// `map[string]string` holding the first value of each parameter. `Username` and `Fragment` fields
+// are also set. Other fields are left unchanged.
+func setURLFields(field reflect.Value, key, value string, errIfMissing bool) error {
+	if value == "" {
+		if errIfMissing {
+			_, err := newEnvVarMissingErr[url.URL](key)
+			return err
+		}
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		_, err := newEnvVarParsingErr[url.URL](key, err)
+		return err
+	}
+
+	password, _ := parsed.User.Password()
+	parts := map[string]string{
+		"Scheme":   parsed.Scheme,
+		"Host":     parsed.Hostname(),
+		"Port":     parsed.Port(),
+		"User":     parsed.User.Username(),
+		"Username": parsed.User.Username(),
+		"Password": password,
+		"Path":     strings.TrimPrefix(parsed.Path, "/"),
+		"Fragment": parsed.Fragment,
+	}
+	for name, part := range parts {
+		partField := field.FieldByName(name)
+		if !partField.IsValid() || !partField.CanSet() {
+			continue
+		}
+		switch partField.Kind() {
+		case reflect.String:
+			partField.SetString(part)
+		case reflect.Int:
+			if part == "" {
+				continue
+			}
+			converted, err := strconv.Atoi(part)
+			if err != nil {
+				_, err := newEnvVarParsingErr[url.URL](key, err)
+				return err
+			}
+			partField.SetInt(int64(converted))
+		}
+	}
+
+	query := field.FieldByName("Query")
+	if !query.IsValid() || !query.CanSet() {
+		return nil
+	}
+	switch query.Interface().(type) {
+	case string:
+		query.SetString(parsed.RawQuery)
+	case url.Values:
+		query.Set(reflect.ValueOf(parsed.Query()))
+	case map[string]string:
+		params := make(map[string]string)
+		for name, values := range parsed.Query() {
+			params[name] = values[0]
+		}
+		query.Set(reflect.ValueOf(params))
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// validationRule checks the value of a field against a rule with the argument `arg`. The returned
+// error describes the requirement, e.g. `must be at most 10`. `ctx` is the context of the parse,
+// for rules that look things up.
+type validationRule func(ctx context.Context, value reflect.Value, arg string) error
+
+// validationRules are the rules available in `validate` tags.
+var validationRules = map[string]validationRule{
+	"len":      validateLen,
+	"min":      validateMin,
+	"max":      validateMax,
+	"oneof":    validateOneOf,
+	"url":      validateURL,
+	"email":    validateEmail,
+	"hostname": validateHostname,
+	"uuid":     validateUUID,
+	"hostport": validateHostPort,
+	"file":     validateFile,
+	"dir":      validateDir,
+	"port":     validatePort,
+	"cron":     validateCron,
+}
+
+// customValidations holds the validations registered with RegisterValidation.
+var customValidations = struct {
+	sync.RWMutex
+	funcs map[string]func(any) error
+}{
+	funcs: make(map[string]func(any) error),
+}
+
+// RegisterValidation registers `fn` as the validation `name`, for reusable rules such as
+// organization specific URL policies. Registered validations can be used as a `validate` tag
+// option, or as a rule in the `validate` tag:
+//
+//	RegisterValidation("https_only", func(value any) error {
+//		if !strings.HasPrefix(value.(string), "https://") {
+//			return errors.New("must use https")
+//		}
+//		return nil
+//	})
+//
+//	CallbackURL string `env:"CALLBACK_URL,validate=https_only"`
+//
+// `fn` is called with the value of the field once all fields are bound, and the error it returns
+// should describe the requirement, since it is used in the error message and hint. Registering a
+// name again replaces the earlier validation. The names of built-in rules can not be used.
+func RegisterValidation(name string, fn func(any) error) error {
+	if name == "" || fn == nil {
+		return errors.New("in RegisterValidation: a name and function are required")
+	}
+	if _, ok := validationRules[name]; ok {
+		return fmt.Errorf("in RegisterValidation: '%s' is a built-in rule", name)
+	}
+
+	customValidations.Lock()
+	defer customValidations.Unlock()
+	customValidations.funcs[name] = fn
+	return nil
+}
+
+// validationError is returned for a field whose value does not satisfy a rule.
+type validationError struct {
+	key  string
+	rule string
+	err  error
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("environment variable '%s' failed validation '%s': %v", e.key, e.rule, e.err)
+}
+
+func (e *validationError) Unwrap() error {
+	return e.err
+}
+
+// validateStruct checks every field of `val` that has a `validate` tag once all fields are bound,
+// e.g. `validate:"min=1,max=65535"`. Rules are separated by commas and the arguments of `oneof`
+// by spaces. Failures are added to the parse errors with the reason ReasonValidation. Fields that
+// already failed to parse are not validated, and neither are optional fields that no source or
+// default set, so `validate:"url"` only applies once the variable is given. The tag is left to
+// the validator instead if one is set with WithValidator.
+//
+// The rules are:
+//
+//   - `len=N`, `min=N` and `max=N` check the length of strings and the value of numbers
+//   - `oneof=a b c` checks that the value is one of the listed values
+//   - `url`, `email`, `hostname` and `uuid` check that a string has that format
+//   - `hostport` checks that a string is a `host:port` address as accepted by `net.Dial` and
+//     `net.Listen`, e.g. `db:5432` or `:8080`, and `hostport=resolve` also checks that the host
+//     resolves
+//   - `file` and `dir` check that a string is the path of a readable file or directory, and
+//     `file=0600` or `dir=0750` also check that it has no permission bits outside those given
+//   - `port` checks that a number is a port between 1 and 65535, and `port=unprivileged` that it
+//     is not below 1024
+//   - `cron` checks that a string is a cron schedule accepted by ParseSchedule
+//   - the name of a validation registered with RegisterValidation runs that validation
+//
+// The `validate` tag option, e.g. `env:"CALLBACK_URL,validate=https_only"`, adds a single rule and
+// is checked even when a validator is set.
+//
+// Fields tagged with the `severity=warn` option, e.g. `env:"PORT,severity=warn"`, report failed
+// rules as warnings in the `Result` instead of failing the parse, so new constraints can be rolled
+// out without breaking services that do not meet them yet.
+func (p *parser) validateStruct(val reflect.Value, path string) {
+	var failed map[string]bool
+	if len(p.errs) > 0 {
+		failed = make(map[string]bool, len(p.errs))
+		for _, err := range p.errs {
+			failed[err.Field] = true
+		}
+	}
+
+	walkFields(val, path, func(f boundField) {
+		if failed[f.path] {
+			return
+		}
+		if _, set := p.res.Sources[f.path]; !set && !p.opts.errOnMissingValue && !f.tag.has("required") {
+			return
+		}
+		check := func(rule string) bool {
+			name, arg, _ := strings.Cut(rule, "=")
+			if err := checkRule(p.opts.context(), f.value, name, arg); err != nil {
+				p.addValidationError(f.tag, &FieldError{
+					Field:  f.path,
+					Key:    f.tag.key,
+					Reason: ReasonValidation,
+					Hint:   fmt.Sprintf("'%s' %v", f.tag.key, err),
+					Err:    &validationError{key: f.tag.key, rule: rule, err: err},
+				})
+				return false
+			}
+			return true
+		}
+		if rule := strings.TrimSpace(f.tag.get("validate")); rule != "" && !check(rule) {
+			return
+		}
+		if p.opts.validator != nil {
+			return
+		}
+		// the rules of the struct tag are split once per type, see typeFields
+		for _, rule := range f.rules {
+			if !check(rule) {
+				return
+			}
+		}
+	})
+}
+
+// addValidationError adds a validation failure to the parse errors, or to the warnings of the
+// result if the field is tagged with the `severity=warn` option.
+func (p *parser) addValidationError(tag envTag, err *FieldError) {
+	if tag.get("severity") == "warn" {
+		p.res.Warnings = append(p.res.Warnings, err)
+		return
+	}
+	p.errs = append(p.errs, err)
+}
+
+// checkRule checks `value` against the rule `name`.
+func checkRule(ctx context.Context, value reflect.Value, name, arg string) error {
+	if rule, ok := validationRules[name]; ok {
+		return rule(ctx, value, arg)
+	}
+
+	customValidations.RLock()
+	fn, ok := customValidations.funcs[name]
+	customValidations.RUnlock()
+	if !ok {
+		return fmt.Errorf("must use a known validation rule, not '%s'", name)
+	}
+	return fn(value.Interface())
+}
+
+// ruleSize returns the length of a string or the value of an int for the size rules.
+func ruleSize(value reflect.Value, rule string) (int64, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(utf8.RuneCountInString(value.String())), nil
+	case reflect.Int:
+		return value.Int(), nil
+	default:
+		return 0, fmt.Errorf("must be a string or number to use '%s'", rule)
+	}
+}
+
+// ruleArg parses the numeric argument of the size rules.
+func ruleArg(arg, rule string) (int64, error) {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("must use a number with '%s', not '%s'", rule, arg)
+	}
+	return n, nil
+}
+
+func validateLen(_ context.Context, value reflect.Value, arg string) error {
+	size, err := ruleSize(value, "len")
+	if err != nil {
+		return err
+	}
+	want, err := ruleArg(arg, "len")
+	if err != nil {
+		return err
+	}
+	if size != want {
+		if value.Kind() == reflect.String {
+			return fmt.Errorf("must be %d characters long", want)
+		}
+		return fmt.Errorf("must be %d", want)
+	}
+	return nil
+}
+
+func validateMin(_ context.Context, value reflect.Value, arg string) error {
+	size, err := ruleSize(value, "min")
+	if err != nil {
+		return err
+	}
+	limit, err := ruleArg(arg, "min")
+	if err != nil {
+		return err
+	}
+	if size < limit {
+		if value.Kind() == reflect.String {
+			return fmt.Errorf("must be at least %d characters long", limit)
+		}
+		return fmt.Errorf("must be at least %d", limit)
+	}
+	return nil
+}
+
+func validateMax(_ context.Context, value reflect.Value, arg string) error {
+	size, err := ruleSize(value, "max")
+	if err != nil {
+		return err
+	}
+	limit, err := ruleArg(arg, "max")
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		if value.Kind() == reflect.String {
+			return fmt.Errorf("must be at most %d characters long", limit)
+		}
+		return fmt.Errorf("must be at most %d", limit)
+	}
+	return nil
+}
+
+func validateOneOf(_ context.Context, value reflect.Value, arg string) error {
+	current, ok := formatValue(value)
+	if !ok {
+		return errors.New("must be a string, number or bool to use 'oneof'")
+	}
+	allowed := strings.Fields(arg)
+	if !slices.Contains(allowed, current) {
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// ruleString returns the value of a string field for the format rules.
+func ruleString(value reflect.Value, rule string) (string, error) {
+	if value.Kind() != reflect.String {
+		return "", fmt.Errorf("must be a string to use '%s'", rule)
+	}
+	return value.String(), nil
+}
+
+func validateURL(_ context.Context, value reflect.Value, _ string) error {
+	s, err := ruleString(value, "url")
+	if err != nil {
+		return err
+	}
+	parsed, err := url.ParseRequestURI(s)
+	if err != nil || parsed.Scheme == "" || (parsed.Host == "" && parsed.Opaque == "") {
+		return errors.New("must be an absolute URL")
+	}
+	return nil
+}
+
+func validateEmail(_ context.Context, value reflect.Value, _ string) error {
+	s, err := ruleString(value, "email")
+	if err != nil {
+		return err
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return errors.New("must be an email address")
+	}
+	return nil
+}
+
+// hostnamePattern matches RFC 1123 host names.
+var hostnamePattern = regexp.MustCompile(
+	`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]{0,61}[a-zA-Z0-9]))*\.?$`,
+)
+
+func validateHostname(_ context.Context, value reflect.Value, _ string) error {
+	s, err := ruleString(value, "hostname")
+	if err != nil {
+		return err
+	}
+	if len(s) > 253 || !hostnamePattern.MatchString(s) {
+		return errors.New("must be a host name")
+	}
+	return nil
+}
+
+// uuidPattern matches UUIDs in their canonical text form.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validateUUID(_ context.Context, value reflect.Value, _ string) error {
+	s, err := ruleString(value, "uuid")
+	if err != nil {
+		return err
+	}
+	if !uuidPattern.MatchString(s) {
+		return errors.New("must be a UUID")
+	}
+	return nil
+}
+
+// lookupHost resolves hosts for `hostport=resolve`.
+var lookupHost = net.DefaultResolver.LookupHost
+
+func validateHostPort(ctx context.Context, value reflect.Value, arg string) error {
+	s, err := ruleString(value, "hostport")
+	if err != nil {
+		return err
+	}
+	if arg != "" && arg != "resolve" {
+		return fmt.Errorf("must use 'resolve' or nothing with 'hostport', not '%s'", arg)
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return errors.New("must be a host:port address")
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("must have a port between 0 and 65535, not '%s'", port)
+	}
+	if host != "" && net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		return fmt.Errorf("must have a valid host, not '%s'", host)
+	}
+
+	if arg == "resolve" && host != "" && net.ParseIP(host) == nil {
+		ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+		defer cancel()
+		if _, err := lookupHost(ctx, host); err != nil {
+			return fmt.Errorf("must have a host that resolves, '%s' does not", host)
+		}
+	}
+	return nil
+}
+
+func validateFile(_ context.Context, value reflect.Value, arg string) error {
+	return validatePath(value, "file", arg)
+}
+
+func validateDir(_ context.Context, value reflect.Value, arg string) error {
+	return validatePath(value, "dir", arg)
+}
+
+// validatePath checks that the path in `value` is a readable file or directory, depending on
+// `rule`, whose permissions are limited to the octal permission bits in `arg` if given.
+func validatePath(value reflect.Value, rule, arg string) error {
+	path, err := ruleString(value, rule)
+	if err != nil {
+		return err
+	}
+	var allowed uint64 = 0o777
+	if arg != "" {
+		allowed, err = strconv.ParseUint(arg, 8, 32)
+		if err != nil || allowed > 0o777 {
+			return fmt.Errorf("must use octal permission bits with '%s', not '%s'", rule, arg)
+		}
+	}
+
+	noun := rule
+	if rule == "dir" {
+		noun = "directory"
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("must be an existing %s, '%s' does not exist", noun, path)
+	}
+	if err != nil {
+		return fmt.Errorf("must be a readable %s: %w", noun, err)
+	}
+	if info.IsDir() != (rule == "dir") {
+		return fmt.Errorf("must be a %s, '%s' is not", noun, path)
+	}
+	if extra := info.Mode().Perm() &^ fs.FileMode(allowed); extra != 0 {
+		return fmt.Errorf("must not have permissions beyond %04o, '%s' has %04o", allowed, path, info.Mode().Perm())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("must be a readable %s: %w", noun, err)
+	}
+	return f.Close()
+}
+
+func validatePort(_ context.Context, value reflect.Value, arg string) error {
+	if value.Kind() != reflect.Int {
+		return errors.New("must be a number to use 'port'")
+	}
+	if arg != "" && arg != "unprivileged" {
+		return fmt.Errorf("must use 'unprivileged' or nothing with 'port', not '%s'", arg)
+	}
+	port := value.Int()
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("must be a port between 1 and 65535, got %d", port)
+	}
+	if arg == "unprivileged" && port < 1024 {
+		return fmt.Errorf("must be an unprivileged port between 1024 and 65535, got %d", port)
+	}
+	return nil
+}
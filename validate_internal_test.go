@@ -0,0 +1,35 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type contextKey struct{}
+
+func TestValidateHostPortContext(t *testing.T) {
+	var got context.Context
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		got = ctx
+		return nil, errors.New("no such host")
+	}
+	t.Cleanup(func() {
+		lookupHost = net.DefaultResolver.LookupHost
+	})
+
+	var cfg struct {
+		Addr string `env:"ADDR" validate:"hostport=resolve"`
+	}
+	ctx := context.WithValue(context.Background(), contextKey{}, "parse")
+	source := newMapSource("values", func(context.Context) (map[string]string, error) {
+		return map[string]string{"ADDR": "db.internal:5432"}, nil
+	})
+	if _, err := Parse(&cfg, WithSources(source), WithContext(ctx)); err == nil {
+		t.Fatal("expected an error for a host that does not resolve")
+	}
+	if got == nil || got.Value(contextKey{}) != "parse" {
+		t.Error("want the host to be resolved with the context of the parse")
+	}
+}
@@ -0,0 +1,268 @@
+package envstruct_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type validatedConfig struct {
+	Port     int    `env:"PORT" validate:"port"`
+	Callback string `env:"CALLBACK_URL" validate:"url"`
+	Level    string `env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := map[string]struct {
+		values    map[string]string
+		wantField string
+	}{
+		"valid": {
+			values: map[string]string{"PORT": "8080", "CALLBACK_URL": "https://example.com/cb", "LOG_LEVEL": "info"},
+		},
+		"unset optional fields are not validated": {
+			values: map[string]string{},
+		},
+		"invalid url": {
+			values:    map[string]string{"CALLBACK_URL": "example.com/cb"},
+			wantField: "Callback",
+		},
+		"invalid port": {
+			values:    map[string]string{"PORT": "70000"},
+			wantField: "Port",
+		},
+		"invalid oneof": {
+			values:    map[string]string{"LOG_LEVEL": "trace"},
+			wantField: "Level",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg validatedConfig
+			_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(tt.values)))
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var fieldErr *envstruct.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("got error %v, want a FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField || fieldErr.Reason != envstruct.ReasonValidation {
+				t.Errorf("got %s error for %s, want validation error for %s", fieldErr.Reason, fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateStructDefaults(t *testing.T) {
+	var cfg struct {
+		Callback string `env:"CALLBACK_URL" default:"not a url" validate:"url"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(nil)))
+	if err == nil {
+		t.Fatal("expected the default to be validated")
+	}
+}
+
+func TestValidateStructRequired(t *testing.T) {
+	var cfg struct {
+		Callback string `env:"CALLBACK_URL,required" validate:"url"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(nil)))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonMissing {
+		t.Fatalf("got error %v, want a missing error", err)
+	}
+}
+
+func TestValidateStructWarn(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT,severity=warn" validate:"port=unprivileged"`
+	}
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(sources.Static(map[string]string{"PORT": "80"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(res.Warnings))
+	}
+	if w := res.Warnings[0]; w.Field != "Port" || w.Key != "PORT" || w.Reason != envstruct.ReasonValidation {
+		t.Errorf("got warning %+v, want a validation failure of PORT", w)
+	}
+	if cfg.Port != 80 {
+		t.Errorf("got port %d, want the value to be set despite the warning", cfg.Port)
+	}
+}
+
+func TestValidateStructWarnWithErrors(t *testing.T) {
+	var cfg struct {
+		Port     int    `env:"PORT,severity=warn" validate:"port=unprivileged"`
+		Callback string `env:"CALLBACK_URL,validate=url"`
+		Level    string `env:"LOG_LEVEL,severity=warn,validate=oneof=debug info"`
+	}
+	source := sources.Static(map[string]string{"PORT": "80", "CALLBACK_URL": "example.com", "LOG_LEVEL": "trace"})
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source))
+
+	// warnings are reported separately from errors, for rules in the tag and the tag option
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "Callback" {
+		t.Fatalf("got error %v, want one error for Callback", err)
+	}
+	if len(res.Warnings) != 2 || res.Warnings[0].Field != "Port" || res.Warnings[1].Field != "Level" {
+		t.Errorf("got warnings %v, want warnings for Port and Level", res.Warnings)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	err := envstruct.RegisterValidation("test_https_only", func(value any) error {
+		if !strings.HasPrefix(value.(string), "https://") {
+			return errors.New("must use https")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		Callback string `env:"CALLBACK_URL,validate=test_https_only"`
+		Webhook  string `env:"WEBHOOK_URL" validate:"url,test_https_only"`
+	}
+	source := sources.Static(map[string]string{"CALLBACK_URL": "http://example.com", "WEBHOOK_URL": "https://example.com"})
+	_, err = envstruct.Parse(&cfg, envstruct.WithSources(source))
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Callback" || !strings.Contains(fieldErr.Hint, "must use https") {
+		t.Fatalf("got error %v, want the registered validation to fail for Callback", err)
+	}
+
+	source.Set("CALLBACK_URL", "https://example.com")
+	source.Set("WEBHOOK_URL", "http://example.com")
+	_, err = envstruct.Parse(&cfg, envstruct.WithSources(source))
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Webhook" {
+		t.Fatalf("got error %v, want the registered validation to fail for Webhook", err)
+	}
+}
+
+func TestRegisterValidationErrors(t *testing.T) {
+	if err := envstruct.RegisterValidation("url", func(any) error { return nil }); err == nil {
+		t.Error("expected an error for the name of a built-in rule")
+	}
+	if err := envstruct.RegisterValidation("", func(any) error { return nil }); err == nil {
+		t.Error("expected an error for a blank name")
+	}
+	if err := envstruct.RegisterValidation("test_nil", nil); err == nil {
+		t.Error("expected an error for a nil function")
+	}
+}
+
+// validateValue parses `value` into a field with the rules `rules` and returns the validation
+// error, if any.
+func validateValue[T any](t *testing.T, rules, value string) error {
+	t.Helper()
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeFor[T](),
+		Tag:  reflect.StructTag(`env:"VALUE" validate:"` + rules + `"`),
+	}})
+	cfg := reflect.New(typ).Interface()
+	_, err := envstruct.Parse(cfg, envstruct.WithSources(sources.Static(map[string]string{"VALUE": value})))
+	if err == nil {
+		return nil
+	}
+	var fieldErr *envstruct.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Reason != envstruct.ReasonValidation {
+		t.Fatalf("got error %v, want a validation error", err)
+	}
+	return err
+}
+
+func TestValidateHostPort(t *testing.T) {
+	tests := []struct {
+		rules, value string
+		valid        bool
+	}{
+		{"hostport", "db:5432", true},
+		{"hostport", ":8080", true},
+		{"hostport", "10.0.0.1:443", true},
+		{"hostport", "[::1]:443", true},
+		{"hostport", "db", false},
+		{"hostport", "db:http", false},
+		{"hostport", "db:70000", false},
+		{"hostport", "bad_host!:80", false},
+		{"hostport=resolve", "localhost:80", true},
+		{"hostport=resolve", "127.0.0.1:80", true},
+		{"hostport=resolve", "does-not-exist.invalid:80", false},
+		{"hostport=dial", "db:5432", false},
+	}
+	for _, tt := range tests {
+		if err := validateValue[string](t, tt.rules, tt.value); (err == nil) != tt.valid {
+			t.Errorf("%s with %q: got error %v, want valid %v", tt.rules, tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateFileAndDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not kept on Windows")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(file, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rules, value string
+		valid        bool
+	}{
+		{"file", file, true},
+		{"file=0600", file, true},
+		{"file=0400", file, false},
+		{"file", dir, false},
+		{"file", filepath.Join(dir, "missing"), false},
+		{"file=rw", file, false},
+		{"dir", dir, true},
+		{"dir=0750", dir, true},
+		{"dir=0700", dir, false},
+		{"dir", file, false},
+	}
+	for _, tt := range tests {
+		if err := validateValue[string](t, tt.rules, tt.value); (err == nil) != tt.valid {
+			t.Errorf("%s with %q: got error %v, want valid %v", tt.rules, tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestValidatePort(t *testing.T) {
+	tests := []struct {
+		rules, value string
+		valid        bool
+	}{
+		{"port", "1", true},
+		{"port", "65535", true},
+		{"port", "0", false},
+		{"port", "65536", false},
+		{"port=unprivileged", "1024", true},
+		{"port=unprivileged", "443", false},
+		{"port=high", "8080", false},
+	}
+	for _, tt := range tests {
+		if err := validateValue[int](t, tt.rules, tt.value); (err == nil) != tt.valid {
+			t.Errorf("%s with %q: got error %v, want valid %v", tt.rules, tt.value, err, tt.valid)
+		}
+	}
+	if err := validateValue[string](t, "port", "8080"); err == nil {
+		t.Error("expected an error for a string field")
+	}
+}
@@ -0,0 +1,94 @@
+package envstruct
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// WithValidator runs the parsed struct through `v`, so the `validate` tags written for
+// go-playground/validator keep working:
+//
+//	v := validator.New(validator.WithRequiredStructEnabled())
+//	res, err := Parse(&cfg, WithValidator(v))
+//
+// Failed fields are added to the parse errors with the reason ReasonValidation and the environment
+// variable the field is bound to, like failures of the built-in rules, and are reported as
+// warnings for fields tagged with the `severity=warn` option. Fields that already failed to parse
+// are not reported again.
+//
+// The validator takes over the `validate` tag, so the built-in rules are not checked when this
+// option is used. The `validate` tag option is still checked.
+func WithValidator(v *validator.Validate) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
+
+// validatorError adapts a failure reported by go-playground/validator to the message used for the
+// built-in rules.
+type validatorError struct {
+	validator.FieldError
+}
+
+func (e validatorError) Error() string {
+	return fmt.Sprintf("must satisfy '%s'", validatorRule(e.FieldError))
+}
+
+func (e validatorError) Unwrap() error {
+	return e.FieldError
+}
+
+// validatorRule returns the rule a field failed in tag form, e.g. `max=10`.
+func validatorRule(fe validator.FieldError) string {
+	if fe.Param() == "" {
+		return fe.Tag()
+	}
+	return fe.Tag() + "=" + fe.Param()
+}
+
+// runValidator validates `obj` with the validator set with WithValidator, if any, and adds the
+// failures to the parse errors.
+func (p *parser) runValidator(obj any) error {
+	if p.opts.validator == nil {
+		return nil
+	}
+	err := p.opts.validator.Struct(obj)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	tags := make(map[string]envTag)
+	walkFields(structValue(obj), "", func(f boundField) {
+		tags[f.path] = f.tag
+	})
+	failed := make(map[string]bool, len(p.errs))
+	for _, fieldErr := range p.errs {
+		failed[fieldErr.Field] = true
+	}
+
+	for _, fe := range validationErrs {
+		// the namespace starts with the name of the top level struct
+		_, path, _ := strings.Cut(fe.StructNamespace(), ".")
+		if failed[path] {
+			continue
+		}
+		key := tags[path].key
+		name := key
+		if name == "" {
+			name = path
+		}
+		ruleErr := validatorError{fe}
+		p.addValidationError(tags[path], &FieldError{
+			Field:  path,
+			Key:    key,
+			Reason: ReasonValidation,
+			Hint:   fmt.Sprintf("'%s' %v", name, ruleErr),
+			Err:    &validationError{key: name, rule: validatorRule(fe), err: ruleErr},
+		})
+	}
+	return nil
+}
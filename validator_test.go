@@ -0,0 +1,68 @@
+package envstruct_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type playgroundConfig struct {
+	Port     int    `env:"PORT,validate=min=1024" validate:"min=1000,max=9000"`
+	Email    string `env:"ADMIN_EMAIL" validate:"omitempty,email"`
+	Workers  int    `env:"WORKERS,severity=warn" validate:"lte=16"`
+	Database struct {
+		Host string `env:"DB_HOST" validate:"required,hostname"`
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	source := sources.Static(map[string]string{
+		"PORT":        "9090",
+		"ADMIN_EMAIL": "not-an-email",
+		"WORKERS":     "32",
+	})
+	var cfg playgroundConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithValidator(v))
+
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want ParseErrors", err)
+	}
+	got := make(map[string]string)
+	for _, fieldErr := range errs {
+		if fieldErr.Reason != envstruct.ReasonValidation {
+			t.Errorf("got reason %s for %s, want %s", fieldErr.Reason, fieldErr.Field, envstruct.ReasonValidation)
+		}
+		got[fieldErr.Field] = fieldErr.Key
+	}
+	want := map[string]string{"Port": "PORT", "Email": "ADMIN_EMAIL", "Database.Host": "DB_HOST"}
+	if len(got) != len(want) {
+		t.Fatalf("got errors for %v, want %v", got, want)
+	}
+	for field, key := range want {
+		if got[field] != key {
+			t.Errorf("got key %q for %s, want %q", got[field], field, key)
+		}
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Field != "Workers" {
+		t.Errorf("got warnings %v, want one for Workers", res.Warnings)
+	}
+}
+
+func TestWithValidatorTagOption(t *testing.T) {
+	v := validator.New()
+	source := sources.Static(map[string]string{"PORT": "80", "DB_HOST": "db.internal"})
+	var cfg playgroundConfig
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithValidator(v))
+
+	// the validate tag option is still checked, and fields that failed are not reported twice
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "Port" {
+		t.Fatalf("got error %v, want one error for Port", err)
+	}
+}
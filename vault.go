@@ -0,0 +1,141 @@
+package envstruct
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VaultConfig configures the Vault source.
+type VaultConfig struct {
+	// Address is the Vault server address. Defaults to the `VAULT_ADDR` environment variable.
+	Address string
+	// Namespace is the Vault Enterprise namespace. Defaults to the `VAULT_NAMESPACE` environment
+	// variable.
+	Namespace string
+	// Token authenticates with a Vault token. Defaults to the `VAULT_TOKEN` environment variable
+	// when AppRole auth is not configured.
+	Token string
+	// RoleID and SecretID authenticate with the AppRole auth method.
+	RoleID   string
+	SecretID string
+	// AppRoleMount is the mount path of the AppRole auth method. Defaults to `approle`.
+	AppRoleMount string
+	// Mount is the mount path of the KV v2 secrets engine. Defaults to `secret`.
+	Mount string
+	// Path is the path of the secret within the mount, e.g. `my-service/config`.
+	Path string
+	// AllFields makes the source serve every field instead of only fields tagged with the `secret`
+	// option.
+	AllFields bool
+	// TLSConfig is used for requests to Vault if set.
+	TLSConfig *tls.Config
+}
+
+// Vault returns a source that reads a secret from a Vault KV v2 secrets engine. The keys of the
+// secret are flattened like config files, so a `db_password` key is resolved as `DB_PASSWORD`.
+//
+// By default the source is only consulted for fields tagged with the `secret` option, e.g.
+// `env:"DB_PASSWORD,secret"`, so other fields fall through to the rest of the chain:
+//
+//	res, err := Parse(&cfg, WithSources(Vault(VaultConfig{Path: "my-service/config"}), Env()))
+//
+// The secret is read the first time a key is looked up.
+func Vault(cfg VaultConfig) Source {
+	source := newMapSource("vault:"+cfg.mount()+"/"+cfg.Path, func(ctx context.Context) (map[string]string, error) {
+		client := newHTTPClient(cfg.TLSConfig)
+		token, err := cfg.token(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		path := "/v1/" + cfg.mount() + "/data/" + strings.Trim(cfg.Path, "/")
+		found, err := cfg.do(ctx, client, http.MethodGet, path, token, nil, &resp)
+		if err != nil || !found {
+			return map[string]string{}, err
+		}
+		return flattenMap(resp.Data.Data, defaultKeySeparator), nil
+	})
+	source.secretsOnly = !cfg.AllFields
+	return source
+}
+
+// token returns the token to use for reading secrets, logging in with AppRole if configured.
+func (cfg VaultConfig) token(ctx context.Context, client *http.Client) (string, error) {
+	if cfg.RoleID == "" {
+		if cfg.Token != "" {
+			return cfg.Token, nil
+		}
+		return os.Getenv("VAULT_TOKEN"), nil
+	}
+
+	mount := cfg.AppRoleMount
+	if mount == "" {
+		mount = "approle"
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role_id": cfg.RoleID, "secret_id": cfg.SecretID}
+	found, err := cfg.do(ctx, client, http.MethodPost, "/v1/auth/"+mount+"/login", "", body, &resp)
+	if err == nil && !found {
+		err = fmt.Errorf("approle auth method not found at '%s'", mount)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error logging in to vault with approle: %w", err)
+	}
+	return resp.Auth.ClientToken, nil
+}
+
+func (cfg VaultConfig) do(
+	ctx context.Context,
+	client *http.Client,
+	method, path, token string,
+	body, out any,
+) (bool, error) {
+	address := cfg.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return false, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(address, "/")+path, &reqBody)
+	if err != nil {
+		return false, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	return doJSONRequest(client, req, out)
+}
+
+func (cfg VaultConfig) mount() string {
+	if cfg.Mount == "" {
+		return "secret"
+	}
+	return strings.Trim(cfg.Mount, "/")
+}
@@ -0,0 +1,106 @@
+package envstruct_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type secretConfig struct {
+	Host     string `env:"DB_HOST"`
+	Password string `env:"DB_PASSWORD,secret"`
+}
+
+// newVaultServer returns a Vault server holding a secret at `secret/my-service/config`, which
+// accepts the token `root` and the AppRole `app` with the secret `s3cret`.
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, "no namespace", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role_id"] != "app" || body["secret_id"] != "s3cret" {
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"auth": map[string]string{"client_token": "approle-token"}})
+		case "/v1/secret/data/my-service/config":
+			if token := r.Header.Get("X-Vault-Token"); token != "root" && token != "approle-token" {
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]any{
+				"db_host":     "vault.internal",
+				"db_password": "hunter2",
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVault(t *testing.T) {
+	server := newVaultServer(t)
+	vault := envstruct.Vault(envstruct.VaultConfig{
+		Address:   server.URL,
+		Namespace: "team",
+		RoleID:    "app",
+		SecretID:  "s3cret",
+		Path:      "my-service/config",
+	})
+	env := sources.Static(map[string]string{"DB_HOST": "env.internal"})
+
+	var cfg secretConfig
+	res, err := envstruct.Parse(&cfg, envstruct.WithSources(vault, env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only secret fields are read from Vault by default
+	if want := (secretConfig{Host: "env.internal", Password: "hunter2"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if res.Sources["Password"] != "vault:secret/my-service/config" {
+		t.Errorf("got source %q for Password", res.Sources["Password"])
+	}
+}
+
+func TestVaultAllFields(t *testing.T) {
+	server := newVaultServer(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_NAMESPACE", "team")
+	t.Setenv("VAULT_TOKEN", "root")
+	vault := envstruct.Vault(envstruct.VaultConfig{Path: "my-service/config", AllFields: true})
+
+	var cfg secretConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(vault)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (secretConfig{Host: "vault.internal", Password: "hunter2"}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestVaultLoginFails(t *testing.T) {
+	server := newVaultServer(t)
+	vault := envstruct.Vault(envstruct.VaultConfig{
+		Address:   server.URL,
+		Namespace: "team",
+		RoleID:    "app",
+		SecretID:  "wrong",
+		Path:      "my-service/config",
+	})
+	var cfg secretConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(vault)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,263 @@
+package envstruct
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadable is implemented by sources that read files or keep what they load, so Watch can
+// watch their files and make them load their values again.
+type reloadable interface {
+	watchedFiles() []string
+	reload()
+}
+
+// watcher is implemented by sources that run goroutines to find changes, such as the sources
+// returned by WithSourcePolling, so they only run while Watch or Store.Watch watches them.
+type watcher interface {
+	startWatching(ctx context.Context)
+}
+
+// WithPollInterval makes Watch re-read every source and parse the config again every `interval`,
+// for sources that can not report changes themselves, such as AWS Secrets Manager or Consul.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithReloadErrorHandler sets a function Watch calls with the errors of the parses done on
+// reload, and of watching files. The previous config stays in use when a reload fails. The handler
+// may be called from other goroutines than the one that called Watch.
+func WithReloadErrorHandler(handler func(err error)) Option {
+	return func(o *options) {
+		o.reloadErrorHandler = handler
+	}
+}
+
+// WithReloadRetry sets how Watch, Store.Watch and Store.ReloadOnSignal retry a reload that failed
+// with an error `policy` considers retryable, such as a source that could not be reached. By
+// default the defaults of RetryPolicy are used. Reloads that fail with other errors, such as
+// values that do not parse or validate, are not retried until the sources change again.
+func WithReloadRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.reloadRetry = policy
+	}
+}
+
+// reloadError reports `err` to the reload error handler, if any.
+func (o options) reloadError(err error) {
+	if o.reloadErrorHandler != nil {
+		o.reloadErrorHandler(err)
+	}
+}
+
+// retryReload calls `reload` and retries it as set with WithReloadRetry, reporting every failure
+// to the reload error handler. It returns the error of the last attempt.
+func (o options) retryReload(ctx context.Context, reload func() error) error {
+	return o.reloadRetry.withDefaults().retry(ctx, func() error {
+		err := reload()
+		if err != nil && ctx.Err() == nil {
+			o.reloadError(err)
+		}
+		return err
+	})
+}
+
+// Watch parses `cfg` and then parses the config again whenever its sources may have changed,
+// calling `onChange` with the previous and the new config if any field changed, until `ctx` is
+// done:
+//
+//	var cfg Config
+//	err := Watch(ctx, &cfg, func(old, new Config) error {
+//		logger.Info("config changed", "changes", Diff(&old, &new))
+//		return nil
+//	}, WithSources(DotEnv(".env"), Env()), WithPollInterval(time.Minute))
+//
+// A reload is triggered when:
+//
+//   - a file read by DotEnv or File is written, created, removed or replaced
+//   - a source implementing Notifier signals a change
+//   - the interval set with WithPollInterval passes, in which case every source is read again
+//
+// Every reload starts from the value `cfg` had before it was first parsed, so defaults set in
+// code apply and removed variables are unset again. `cfg` is only written by the first parse;
+// later configs are only passed to `onChange`, which is called from the goroutine that called
+// Watch. If a reload fails, whether values do not parse or do not validate, the previous config
+// stays in use and is never replaced by a partly valid one. The error is passed to the handler
+// set with WithReloadErrorHandler and recorded by WithMetrics and WithHealth, and the reload is
+// retried as set with WithReloadRetry. If `onChange` returns an error Watch stops and returns it.
+// Store.Watch reloads a config that is shared between goroutines instead.
+//
+// Watch returns an error if the first parse fails, and nil once `ctx` is done.
+func Watch[T any](ctx context.Context, cfg *T, onChange func(old, new T) error, opts ...Option) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("in Watch: %w", err)
+		}
+	}()
+	opts = append(opts[:len(opts):len(opts)], WithContext(ctx))
+	o := newOptions(opts)
+
+	base := *cfg
+	if _, err := Parse(cfg, opts...); err != nil {
+		return err
+	}
+	current := *cfg
+
+	return o.watchSources(ctx, func() error {
+		var next T
+		err := o.retryReload(ctx, func() error {
+			next = base
+			_, err := Parse(&next, opts...)
+			return err
+		})
+		if err != nil || reflect.DeepEqual(current, next) {
+			return nil
+		}
+		if err := onChange(current, next); err != nil {
+			return err
+		}
+		current = next
+		return nil
+	})
+}
+
+// watchSources calls `reload` whenever the sources may have changed, as described by Watch, until
+// `ctx` is done or `reload` returns an error.
+func (o options) watchSources(ctx context.Context, reload func() error) error {
+	changed := make(chan struct{}, 1)
+	signal := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	if err := o.watchFiles(ctx, signal); err != nil {
+		return err
+	}
+	for _, source := range o.allSources() {
+		if src, ok := source.(watcher); ok {
+			src.startWatching(ctx)
+		}
+		notifier, ok := source.(Notifier)
+		if !ok {
+			continue
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-notifier.Changed():
+					if !ok {
+						return
+					}
+					signal()
+				}
+			}
+		}()
+	}
+	var poll <-chan time.Time
+	if o.pollInterval > 0 {
+		ticker := time.NewTicker(o.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-poll:
+			o.reloadSources()
+		}
+		if err := reload(); err != nil {
+			return err
+		}
+	}
+}
+
+// reloadSources makes every source that keeps what it loads load its values again.
+func (o options) reloadSources() {
+	for _, source := range o.allSources() {
+		if src, ok := source.(reloadable); ok {
+			src.reload()
+		}
+	}
+}
+
+// watchFiles watches the files read by the sources and calls `changed` after reloading the
+// sources that read a file that changed, until `ctx` is done. The directories of the files are
+// watched rather than the files, so files that are created later or replaced by renaming another
+// file over them, as many editors do, are seen.
+func (o options) watchFiles(ctx context.Context, changed func()) error {
+	sourcesByFile := make(map[string][]reloadable)
+	for _, source := range o.allSources() {
+		src, ok := source.(reloadable)
+		if !ok {
+			continue
+		}
+		for _, file := range src.watchedFiles() {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return err
+			}
+			sourcesByFile[abs] = append(sourcesByFile[abs], src)
+		}
+	}
+	if len(sourcesByFile) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	watched := make(map[string]bool)
+	for file := range sourcesByFile {
+		dir := filepath.Dir(file)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("error watching '%s': %w", dir, err)
+		}
+		watched[dir] = true
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				sources := sourcesByFile[filepath.Clean(event.Name)]
+				if len(sources) == 0 || event.Op == fsnotify.Chmod {
+					continue
+				}
+				for _, src := range sources {
+					src.reload()
+				}
+				changed()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				o.reloadError(err)
+			}
+		}
+	}()
+	return nil
+}
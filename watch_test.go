@@ -0,0 +1,267 @@
+package envstruct_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type watchConfig struct {
+	Level string `env:"LOG_LEVEL"`
+	Port  int    `env:"PORT"`
+}
+
+type change struct {
+	old, new watchConfig
+}
+
+// watch runs Watch on `cfg` in a goroutine, sending the configs passed to `onChange` to the
+// returned channel. The returned function stops Watch, after which `cfg` may be read; it is also
+// called when the test ends.
+func watch(t *testing.T, cfg *watchConfig, opts ...envstruct.Option) (<-chan change, func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan change)
+	done := make(chan error, 1)
+	go func() {
+		done <- envstruct.Watch(ctx, cfg, func(old, new watchConfig) error {
+			select {
+			case changes <- change{old, new}:
+			case <-ctx.Done():
+			}
+			return nil
+		}, opts...)
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			if err := <-done; err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	t.Cleanup(stop)
+	return changes, stop
+}
+
+// firstParse waits until the first parse of a watchConfig looked up its keys in `source`, so later
+// changes are seen as changes. PORT is looked up after LOG_LEVEL.
+func firstParse(t *testing.T, source *sources.StaticSource) {
+	t.Helper()
+	waitFor(t, "the first parse", func() bool {
+		return source.Lookups("PORT") > 0
+	})
+}
+
+// nextChange returns the next change sent by watch, failing the test if there is none.
+func nextChange(t *testing.T, changes <-chan change) change {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a change")
+		return change{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	source := sources.Static(map[string]string{"LOG_LEVEL": "info"})
+	cfg := watchConfig{Port: 9090}
+	changes, stop := watch(t, &cfg, envstruct.WithSources(source))
+	firstParse(t, source)
+
+	source.Set("LOG_LEVEL", "debug")
+	c := nextChange(t, changes)
+	if want := (watchConfig{Level: "info", Port: 9090}); c.old != want {
+		t.Errorf("got old config %+v, want %+v", c.old, want)
+	}
+	if want := (watchConfig{Level: "debug", Port: 9090}); c.new != want {
+		t.Errorf("got new config %+v, want %+v", c.new, want)
+	}
+
+	// a reload that changes nothing is not reported, and every reload starts from the
+	// original config, so removed variables are unset again
+	source.Set("LOG_LEVEL", "debug")
+	source.Delete("LOG_LEVEL")
+	c = nextChange(t, changes)
+	if want := (watchConfig{Port: 9090}); c.new != want {
+		t.Errorf("got new config %+v, want %+v", c.new, want)
+	}
+
+	stop()
+	if want := (watchConfig{Level: "info", Port: 9090}); cfg != want {
+		t.Errorf("got %+v, want cfg to only be written by the first parse", cfg)
+	}
+}
+
+func TestWatchFirstParseFails(t *testing.T) {
+	source := sources.Static(map[string]string{"PORT": "abc"})
+	var cfg watchConfig
+	err := envstruct.Watch(context.Background(), &cfg, func(old, new watchConfig) error {
+		t.Error("onChange was called")
+		return nil
+	}, envstruct.WithSources(source))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestWatchOnChangeError(t *testing.T) {
+	source := sources.Static(map[string]string{"LOG_LEVEL": "info"})
+	errStop := errors.New("stop")
+	done := make(chan error, 1)
+	go func() {
+		var cfg watchConfig
+		done <- envstruct.Watch(context.Background(), &cfg, func(old, new watchConfig) error {
+			return errStop
+		}, envstruct.WithSources(source))
+	}()
+
+	firstParse(t, source)
+	source.Set("LOG_LEVEL", "debug")
+	select {
+	case err := <-done:
+		if !errors.Is(err, errStop) {
+			t.Fatalf("got error %v, want the error of onChange", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not stop")
+	}
+}
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".env")
+	write := func(name, level string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte("LOG_LEVEL="+level+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(file, "info")
+	var cfg watchConfig
+	changes, _ := watch(t, &cfg, envstruct.WithSources(envstruct.DotEnv(file)))
+
+	// the file may be written before Watch watches it, or even before the first parse, so keep
+	// writing it with alternating levels until a change to debug is seen
+	deadline := time.After(5 * time.Second)
+	levels := []string{"debug", "info"}
+	for i, seen := 0, false; !seen; i++ {
+		write(file, levels[i%2])
+		select {
+		case c := <-changes:
+			seen = c.new.Level == "debug"
+		case <-deadline:
+			t.Fatal("timed out waiting for the written file to be reloaded")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	// editors often write a new file and rename it over the old one
+	tmp := filepath.Join(dir, ".env.tmp")
+	write(tmp, "warn")
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+	for c := nextChange(t, changes); c.new.Level != "warn"; c = nextChange(t, changes) {
+	}
+}
+
+// reloadErrors counts the errors passed to the reload error handler.
+type reloadErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *reloadErrors) handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *reloadErrors) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.errs)
+}
+
+func TestStoreWatchKeepsLastGoodConfig(t *testing.T) {
+	source := sources.Static(map[string]string{"LOG_LEVEL": "info", "PORT": "8080"})
+	var errs reloadErrors
+	store, err := envstruct.NewStore(watchConfig{},
+		envstruct.WithSources(source),
+		envstruct.WithReloadErrorHandler(errs.handle),
+		envstruct.WithReloadRetry(envstruct.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Watch(ctx)
+	}()
+	good := watchConfig{Level: "info", Port: 8080}
+
+	// values that do not parse are not retried
+	source.Set("PORT", "abc")
+	waitFor(t, "the failed reload", func() bool {
+		return errs.count() >= 1
+	})
+	if store.Load() != good {
+		t.Errorf("got %+v after a failed reload, want the last good config %+v", store.Load(), good)
+	}
+
+	// sources that can not be reached are retried
+	source.Fail(unavailableError{})
+	source.Set("PORT", "9090")
+	waitFor(t, "the retried reloads", func() bool {
+		return errs.count() >= 4
+	})
+	if store.Load() != good {
+		t.Errorf("got %+v after a failed reload, want the last good config %+v", store.Load(), good)
+	}
+
+	source.Fail(nil)
+	source.Set("LOG_LEVEL", "debug")
+	waitFor(t, "the successful reload", func() bool {
+		return store.Load() == watchConfig{Level: "debug", Port: 9090}
+	})
+	if got := errs.count(); got != 4 {
+		t.Errorf("got %d reload errors, want 1 for the invalid value and 3 for the attempts to reach the source", got)
+	}
+}
+
+func TestWatchKeepsLastGoodConfig(t *testing.T) {
+	source := sources.Static(map[string]string{"LOG_LEVEL": "info", "PORT": "8080"})
+	var errs reloadErrors
+	var cfg watchConfig
+	changes, _ := watch(t, &cfg, envstruct.WithSources(source), envstruct.WithReloadErrorHandler(errs.handle))
+	firstParse(t, source)
+
+	source.Set("PORT", "abc")
+	waitFor(t, "the failed reload", func() bool {
+		return errs.count() == 1
+	})
+	source.Set("PORT", "9090")
+	c := nextChange(t, changes)
+	if want := (watchConfig{Level: "info", Port: 8080}); c.old != want {
+		t.Errorf("got old config %+v, want the last good config %+v", c.old, want)
+	}
+	if want := (watchConfig{Level: "info", Port: 9090}); c.new != want {
+		t.Errorf("got new config %+v, want %+v", c.new, want)
+	}
+}
@@ -0,0 +1,72 @@
+package envstruct
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithByteSecrets makes Parse reject fields tagged with the `secret` option unless they are of
+// type `[]byte` or `Secret[[]byte]`. Unlike strings, which are immutable and linger in memory
+// until they are garbage collected, byte slices can be wiped with Zero or ZeroSecrets once the
+// credentials have been used. Failing fields are reported with the reason ReasonTag.
+//
+// Copies of the values may still remain in the sources they were read from, such as the process
+// environment, which WithScrubSecrets clears.
+func WithByteSecrets() Option {
+	return func(o *options) {
+		o.byteSecrets = true
+	}
+}
+
+// byteSecretType reports whether fields of type `t` can hold secrets when WithByteSecrets is used.
+func byteSecretType(t reflect.Type) bool {
+	return t == reflect.TypeFor[[]byte]() || t == reflect.TypeFor[Secret[[]byte]]()
+}
+
+// checkByteSecret returns the error for a secret field `f` that WithByteSecrets rejects, or nil.
+func checkByteSecret(f boundField) *FieldError {
+	if !f.tag.has("secret") || byteSecretType(f.field.Type) {
+		return nil
+	}
+	return &FieldError{
+		Field:  f.path,
+		Key:    f.tag.key,
+		Reason: ReasonTag,
+		Hint:   fmt.Sprintf("change the type of field '%s' to []byte or Secret[[]byte]", f.path),
+		Err:    fmt.Errorf("secret field '%s' bound to '%s' must be a byte slice, not %s", f.path, f.tag.key, f.field.Type),
+	}
+}
+
+// Zero overwrites `b` with zeros, such as a `[]byte` secret that is no longer needed.
+func Zero(b []byte) {
+	clear(b)
+}
+
+// Zero overwrites the value of the secret with zeros if it is a `[]byte`, and resets it to the
+// zero value of `T` otherwise.
+func (s *Secret[T]) Zero() {
+	if b, ok := any(s.value).([]byte); ok {
+		clear(b)
+	}
+	var zero T
+	s.value = zero
+}
+
+// ZeroSecrets calls Zero on every field of `cfg` that is tagged with the `secret` option, or is a
+// Secret, and holds a `[]byte`, and resets other secret fields to their zero value. `cfg` must be
+// a pointer to a struct.
+func ZeroSecrets(cfg any) {
+	walkFields(structValue(cfg), "", func(f boundField) {
+		if !f.tag.has("secret") || !f.value.CanSet() {
+			return
+		}
+		if zeroer, ok := f.value.Addr().Interface().(interface{ Zero() }); ok {
+			zeroer.Zero()
+			return
+		}
+		if f.value.Kind() == reflect.Slice && f.value.Type().Elem().Kind() == reflect.Uint8 {
+			clear(f.value.Bytes())
+		}
+		f.value.SetZero()
+	})
+}
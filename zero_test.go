@@ -0,0 +1,97 @@
+package envstruct_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	envstruct "github.com/jha-captech/env-vars-to-struct"
+	"github.com/jha-captech/env-vars-to-struct/sources"
+)
+
+type byteSecretConfig struct {
+	Host     string                   `env:"DB_HOST"`
+	Password []byte                   `env:"DB_PASSWORD,secret"`
+	Key      envstruct.Secret[[]byte] `env:"SIGNING_KEY"`
+}
+
+func TestByteSecrets(t *testing.T) {
+	source := sources.Static(map[string]string{"DB_HOST": "db.internal", "DB_PASSWORD": "hunter2", "SIGNING_KEY": "key"})
+	var cfg byteSecretConfig
+	if _, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithByteSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	if string(cfg.Password) != "hunter2" || string(cfg.Key.Value()) != "key" {
+		t.Fatalf("got %q and %q, want the secrets as bytes", cfg.Password, cfg.Key.Value())
+	}
+
+	password, key := cfg.Password, cfg.Key.Value()
+	envstruct.ZeroSecrets(&cfg)
+	if !bytes.Equal(password, make([]byte, len("hunter2"))) || !bytes.Equal(key, make([]byte, len("key"))) {
+		t.Errorf("got %q and %q, want the memory of the secrets to be zeroed", password, key)
+	}
+	if cfg.Password != nil || cfg.Key.Value() != nil {
+		t.Errorf("got %+v, want the secret fields to be reset", cfg)
+	}
+	if cfg.Host != "db.internal" {
+		t.Error("want fields that are not secret to be kept")
+	}
+}
+
+func TestByteSecretsRejectsStrings(t *testing.T) {
+	source := sources.Static(map[string]string{"DB_PASSWORD": "hunter2", "API_TOKEN": "token"})
+	var cfg struct {
+		Password string                   `env:"DB_PASSWORD,secret"`
+		Token    envstruct.Secret[string] `env:"API_TOKEN"`
+		Key      []byte                   `env:"SIGNING_KEY,secret"`
+	}
+	_, err := envstruct.Parse(&cfg, envstruct.WithSources(source), envstruct.WithByteSecrets())
+	var errs envstruct.ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got error %v, want errors for the 2 string secrets", err)
+	}
+	for i, field := range []string{"Password", "Token"} {
+		if errs[i].Field != field || errs[i].Reason != envstruct.ReasonTag {
+			t.Errorf("got error %v, want a tag error for %s", errs[i], field)
+		}
+	}
+}
+
+func TestZeroSecrets(t *testing.T) {
+	cfg := struct {
+		Password string                   `env:"DB_PASSWORD,secret"`
+		Token    envstruct.Secret[string] `env:"API_TOKEN"`
+		Port     int                      `env:"DB_PORT"`
+		Inner    struct {
+			Key []byte `env:"KEY,secret"`
+		}
+	}{Password: "hunter2", Token: envstruct.NewSecret("token"), Port: 5432}
+	cfg.Inner.Key = []byte("key")
+	key := cfg.Inner.Key
+
+	envstruct.ZeroSecrets(&cfg)
+	if cfg.Password != "" || cfg.Token.Value() != "" || cfg.Inner.Key != nil {
+		t.Errorf("got %+v, want the secrets to be reset", cfg)
+	}
+	if !bytes.Equal(key, []byte{0, 0, 0}) {
+		t.Errorf("got %q, want the nested secret to be zeroed", key)
+	}
+	if cfg.Port != 5432 {
+		t.Error("want fields that are not secret to be kept")
+	}
+}
+
+func TestZero(t *testing.T) {
+	b := []byte("hunter2")
+	envstruct.Zero(b)
+	if !bytes.Equal(b, make([]byte, 7)) {
+		t.Errorf("got %q", b)
+	}
+
+	s := envstruct.NewSecret([]byte("key"))
+	value := s.Value()
+	s.Zero()
+	if !bytes.Equal(value, []byte{0, 0, 0}) || s.Value() != nil {
+		t.Errorf("got %q and %q, want the secret to be zeroed and reset", value, s.Value())
+	}
+}
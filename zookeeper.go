@@ -0,0 +1,178 @@
+package envstruct
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+// ZooKeeperConfig configures the ZooKeeper source.
+type ZooKeeperConfig struct {
+	// Servers are the `host:port` addresses of the ensemble.
+	Servers []string
+	// Root is the path of the znode the tree is read from, e.g. `/config/my-service`.
+	Root string
+	// SessionTimeout is the session timeout negotiated with the ensemble. Defaults to 10 seconds.
+	SessionTimeout time.Duration
+	// Conn is used instead of connecting to Servers if set.
+	Conn *zk.Conn
+}
+
+// ZooKeeper returns a source that reads the znode tree under Root. Every znode with data is a key,
+// named by its path relative to Root and flattened like config file keys, so the data of
+// `/config/my-service/db/host` is resolved as `DB_HOST` with the root `/config/my-service`.
+//
+// The tree is read the first time a key is looked up, setting a watch on every znode. When any
+// watch fires the tree is read again on the next lookup, and the source, which implements
+// Notifier, signals the change. Each znode has at most one data and one child watch at a time:
+// reading the tree again only sets watches on znodes whose watch fired or that are new.
+func ZooKeeper(cfg ZooKeeperConfig) Source {
+	var conn zkConn
+	if cfg.Conn != nil {
+		conn = cfg.Conn
+	}
+	return newZooKeeperSource(cfg, conn)
+}
+
+// zkConn is the part of `*zk.Conn` used by the ZooKeeper source.
+type zkConn interface {
+	Get(path string) ([]byte, *zk.Stat, error)
+	GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	Children(path string) ([]string, *zk.Stat, error)
+	ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error)
+}
+
+// newZooKeeperSource returns the ZooKeeper source reading from `conn`, or from a connection to
+// the servers of `cfg` if `conn` is nil.
+func newZooKeeperSource(cfg ZooKeeperConfig, conn zkConn) Source {
+	var (
+		mu     sync.Mutex
+		values map[string]string
+		// watching holds the znodes with a watch that has not fired yet, by path and kind
+		watching = make(map[zkWatch]bool)
+	)
+	source := newNotifySource(&funcSource{name: "zookeeper:" + cfg.Root})
+
+	watch := func(w zkWatch, events <-chan zk.Event) {
+		watching[w] = true
+		go func() {
+			event := <-events
+			mu.Lock()
+			delete(watching, w)
+			values = nil
+			mu.Unlock()
+			if event.Type != zk.EventNotWatching {
+				source.notify()
+			}
+		}()
+	}
+
+	// read reads the tree under `znode`; mu must be held
+	var read func(ctx context.Context, znode string, readValues map[string]string) error
+	read = func(ctx context.Context, znode string, readValues map[string]string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var (
+			data       []byte
+			dataEvents <-chan zk.Event
+			err        error
+		)
+		dataWatch := zkWatch{path: znode}
+		if watching[dataWatch] {
+			data, _, err = conn.Get(znode)
+		} else {
+			data, _, dataEvents, err = conn.GetW(znode)
+		}
+		if errors.Is(err, zk.ErrNoNode) {
+			// deleted since its parent was read
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if dataEvents != nil {
+			watch(dataWatch, dataEvents)
+		}
+		if len(data) > 0 && znode != cfg.Root {
+			key := flattenPath(strings.TrimPrefix(znode, cfg.Root), "/", defaultKeySeparator)
+			readValues[key] = strings.TrimRight(string(data), "\r\n")
+		}
+
+		var (
+			children    []string
+			childEvents <-chan zk.Event
+		)
+		childWatch := zkWatch{path: znode, children: true}
+		if watching[childWatch] {
+			children, _, err = conn.Children(znode)
+		} else {
+			children, _, childEvents, err = conn.ChildrenW(znode)
+		}
+		if errors.Is(err, zk.ErrNoNode) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if childEvents != nil {
+			watch(childWatch, childEvents)
+		}
+		for _, child := range children {
+			if err := read(ctx, path.Join(znode, child), readValues); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	load := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if values != nil {
+			return nil
+		}
+		if conn == nil {
+			timeout := cfg.SessionTimeout
+			if timeout <= 0 {
+				timeout = remoteTimeout
+			}
+			connected, _, err := zk.Connect(cfg.Servers, timeout, zk.WithLogInfo(false))
+			if err != nil {
+				return err
+			}
+			conn = connected
+		}
+
+		readValues := make(map[string]string)
+		if err := read(ctx, cfg.Root, readValues); err != nil {
+			return err
+		}
+		values = readValues
+		return nil
+	}
+
+	source.prefetch = load
+	source.lookup = func(ctx context.Context, key string) (string, bool, error) {
+		if err := load(ctx); err != nil {
+			return "", false, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value, ok := values[key]
+		return value, ok, nil
+	}
+	return source
+}
+
+// zkWatch identifies the data or child watch of a znode.
+type zkWatch struct {
+	path     string
+	children bool
+}
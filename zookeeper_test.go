@@ -0,0 +1,179 @@
+package envstruct
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+// fakeZK is an in-memory znode tree whose watches fire like those of ZooKeeper.
+type fakeZK struct {
+	mu       sync.Mutex
+	data     map[string]string
+	watchers map[zkWatch][]chan zk.Event
+}
+
+func newFakeZK(data map[string]string) *fakeZK {
+	return &fakeZK{data: data, watchers: make(map[zkWatch][]chan zk.Event)}
+}
+
+func (c *fakeZK) children(znode string) []string {
+	var children []string
+	for p := range c.data {
+		if path.Dir(p) == znode && p != znode {
+			children = append(children, path.Base(p))
+		}
+	}
+	slices.Sort(children)
+	return children
+}
+
+func (c *fakeZK) addWatcher(w zkWatch) <-chan zk.Event {
+	ch := make(chan zk.Event, 1)
+	c.watchers[w] = append(c.watchers[w], ch)
+	return ch
+}
+
+func (c *fakeZK) Get(znode string) ([]byte, *zk.Stat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.data[znode]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return []byte(data), &zk.Stat{}, nil
+}
+
+func (c *fakeZK) GetW(znode string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	data, stat, err := c.Get(znode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return data, stat, c.addWatcher(zkWatch{path: znode}), nil
+}
+
+func (c *fakeZK) Children(znode string) ([]string, *zk.Stat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.data[znode]; !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return c.children(znode), &zk.Stat{}, nil
+}
+
+func (c *fakeZK) ChildrenW(znode string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	children, stat, err := c.Children(znode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return children, stat, c.addWatcher(zkWatch{path: znode, children: true}), nil
+}
+
+// set sets the data of `znode`, creating it if needed, and fires the watches of the change.
+func (c *fakeZK) set(znode, data string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, existed := c.data[znode]
+	c.data[znode] = data
+	c.fire(zkWatch{path: znode}, zk.EventNodeDataChanged)
+	if !existed {
+		c.fire(zkWatch{path: path.Dir(znode), children: true}, zk.EventNodeChildrenChanged)
+	}
+}
+
+// fire sends `eventType` to the watchers of `w`, which only fire once.
+func (c *fakeZK) fire(w zkWatch, eventType zk.EventType) {
+	for _, ch := range c.watchers[w] {
+		ch <- zk.Event{Type: eventType, Path: w.path}
+	}
+	delete(c.watchers, w)
+}
+
+// outstanding returns the number of watches that have not fired.
+func (c *fakeZK) outstanding() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, chs := range c.watchers {
+		n += len(chs)
+	}
+	return n
+}
+
+func TestZooKeeper(t *testing.T) {
+	conn := newFakeZK(map[string]string{
+		"/config/svc":         "",
+		"/config/svc/db":      "",
+		"/config/svc/db/host": "db.internal\n",
+		"/config/svc/db/port": "5432",
+		"/config/svc/level":   "info",
+	})
+	source := newZooKeeperSource(ZooKeeperConfig{Root: "/config/svc"}, conn)
+	ctx := context.Background()
+
+	lookup := func(key string) string {
+		t.Helper()
+		value, _, err := source.Lookup(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	if got := lookup("DB_HOST"); got != "db.internal" {
+		t.Errorf("got DB_HOST %q, want %q", got, "db.internal")
+	}
+	if got := lookup("LEVEL"); got != "info" {
+		t.Errorf("got LEVEL %q, want %q", got, "info")
+	}
+	// a data and a child watch on each of the 5 znodes
+	if got := conn.outstanding(); got != 10 {
+		t.Fatalf("got %d watches after the first read, want 10", got)
+	}
+
+	changed := source.(Notifier).Changed()
+	for i := range 5 {
+		level := strings.Repeat("debug", i+1)
+		conn.set("/config/svc/level", level)
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change was signaled")
+		}
+		if got := lookup("LEVEL"); got != level {
+			t.Errorf("got LEVEL %q, want %q", got, level)
+		}
+		// only the watch that fired is set again
+		if got := conn.outstanding(); got != 10 {
+			t.Fatalf("got %d watches after reload %d, want 10", got, i+1)
+		}
+	}
+
+	conn.set("/config/svc/region", "eu-west-1")
+	<-changed
+	if got := lookup("REGION"); got != "eu-west-1" {
+		t.Errorf("got REGION %q, want %q", got, "eu-west-1")
+	}
+	if got := conn.outstanding(); got != 12 {
+		t.Errorf("got %d watches after a znode was added, want 12", got)
+	}
+}
+
+func TestZooKeeperContext(t *testing.T) {
+	conn := newFakeZK(map[string]string{"/config/svc": "", "/config/svc/level": "info"})
+	source := newZooKeeperSource(ZooKeeperConfig{Root: "/config/svc"}, conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := source.Lookup(ctx, "LEVEL"); err == nil {
+		t.Fatal("expected the canceled context to stop the read")
+	}
+}